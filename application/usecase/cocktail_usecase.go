@@ -2,37 +2,205 @@ package usecase
 
 import (
 	"context"
+	"github.com/shake551/cocktails-api/domain/batch"
 	"github.com/shake551/cocktails-api/domain/model"
 	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/domain/strength"
+	"github.com/shake551/cocktails-api/domain/units"
+	"github.com/shake551/cocktails-api/domain/validate"
+	"sort"
+	"strings"
 )
 
 type CocktailUseCase interface {
-	GetLimit(ctx context.Context, limit int64, offset int64, keyword string) ([]model.Cocktail, error)
-	GetById(ctx context.Context, id int64) (model.CocktailDetail, error)
+	GetLimit(ctx context.Context, limit int64, offset int64, filter model.CocktailFilter) ([]model.Cocktail, error)
+	GetById(ctx context.Context, id int64, system units.System) (model.CocktailDetail, error)
+	Batch(ctx context.Context, id int64, params model.BatchParams, system units.System) (model.CocktailBatch, error)
 	Create(ctx context.Context, params model.CocktailParams) (*model.CocktailDetail, error)
 	GetListByIDs(ctx context.Context, ids []int64) ([]model.Cocktail, error)
+	Update(ctx context.Context, id int64, params model.CocktailParams) (*model.CocktailDetail, error)
+	Patch(ctx context.Context, id int64, params model.CocktailPatchParams) (*model.CocktailDetail, error)
+	Delete(ctx context.Context, id int64) error
+	GetMakeable(ctx context.Context, params model.MakeableParams) ([]model.MakeableCocktail, error)
 }
 
 type cocktailUseCase struct {
 	repository.CocktailRepository
+	uow repository.UnitOfWork
 }
 
-func NewCocktailUseCase(r repository.CocktailRepository) CocktailUseCase {
-	return &cocktailUseCase{r}
+func NewCocktailUseCase(r repository.CocktailRepository, uow repository.UnitOfWork) CocktailUseCase {
+	return &cocktailUseCase{r, uow}
 }
 
-func (u *cocktailUseCase) GetLimit(ctx context.Context, limit int64, offset int64, keyword string) ([]model.Cocktail, error) {
-	return u.CocktailRepository.GetLimit(ctx, limit, offset, keyword)
+func (u *cocktailUseCase) GetLimit(ctx context.Context, limit int64, offset int64, filter model.CocktailFilter) ([]model.Cocktail, error) {
+	filter.Keyword = strings.TrimSpace(filter.Keyword)
+	filter.Materials = uniqueMaterialNames(filter.Materials)
+	filter.ExcludeMaterials = uniqueMaterialNames(filter.ExcludeMaterials)
+
+	cocktails, err := u.CocktailRepository.GetLimit(ctx, limit, offset, filter)
+	return fillImages(cocktails), err
+}
+
+func uniqueMaterialNames(names []string) []string {
+	var unique []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		n := strings.TrimSpace(name)
+		if n == "" || seen[n] {
+			continue
+		}
+		seen[n] = true
+		unique = append(unique, n)
+	}
+	return unique
 }
 
-func (u *cocktailUseCase) GetById(ctx context.Context, id int64) (model.CocktailDetail, error) {
-	return u.CocktailRepository.GetByID(ctx, id)
+// GetById returns the recipe with its quantities in the unit system, or as written when system is empty.
+func (u *cocktailUseCase) GetById(ctx context.Context, id int64, system units.System) (model.CocktailDetail, error) {
+	d, err := u.CocktailRepository.GetByID(ctx, id)
+	if err != nil {
+		return model.CocktailDetail{}, err
+	}
+
+	strength.Fill(&d)
+	fillDetailImages(&d)
+	for i := range d.Materials {
+		d.Materials[i].Quantity = units.In(d.Materials[i].Quantity, system)
+	}
+	return d, nil
+}
+
+// Batch returns the recipe scaled to the servings, in the unit system or as written when system is empty.
+func (u *cocktailUseCase) Batch(ctx context.Context, id int64, params model.BatchParams, system units.System) (model.CocktailBatch, error) {
+	if err := validate.Struct(params); err != nil {
+		return model.CocktailBatch{}, err
+	}
+
+	d, err := u.CocktailRepository.GetByID(ctx, id)
+	if err != nil {
+		return model.CocktailBatch{}, err
+	}
+	return batch.Of(*fillDetailImages(&d), params.Servings, system), nil
 }
 
 func (u *cocktailUseCase) Create(ctx context.Context, params model.CocktailParams) (*model.CocktailDetail, error) {
-	return u.CocktailRepository.Create(ctx, params)
+	if err := validate.Struct(params); err != nil {
+		return nil, err
+	}
+	d, err := u.CocktailRepository.Create(ctx, params)
+	return fillDetailImages(d), err
 }
 
 func (u *cocktailUseCase) GetListByIDs(ctx context.Context, ids []int64) ([]model.Cocktail, error) {
-	return u.CocktailRepository.GetListByIDs(ctx, ids)
+	cocktails, err := u.CocktailRepository.GetListByIDs(ctx, ids)
+	return fillImages(cocktails), err
+}
+
+func (u *cocktailUseCase) Update(ctx context.Context, id int64, params model.CocktailParams) (*model.CocktailDetail, error) {
+	if err := validate.Struct(params); err != nil {
+		return nil, err
+	}
+	d, err := u.CocktailRepository.Update(ctx, id, params)
+	return fillDetailImages(d), err
+}
+
+func (u *cocktailUseCase) Patch(ctx context.Context, id int64, params model.CocktailPatchParams) (*model.CocktailDetail, error) {
+	var patched *model.CocktailDetail
+
+	// the recipe is read and written back in one unit, so a concurrent update is not silently reverted
+	err := u.uow.Do(ctx, func(repos repository.Repositories) error {
+		current, err := repos.Cocktail.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		merged := model.CocktailParams{
+			Name:    current.Name,
+			Reading: current.Reading,
+			Method:  current.Method,
+			Glass:   current.Glass,
+			Garnish: current.Garnish,
+			Notes:   current.Notes,
+			Steps:   current.Steps,
+		}
+		if params.Name != nil {
+			merged.Name = *params.Name
+		}
+		if params.Reading != nil {
+			merged.Reading = *params.Reading
+		}
+		if params.Method != nil {
+			merged.Method = *params.Method
+		}
+		if params.Glass != nil {
+			merged.Glass = *params.Glass
+		}
+		if params.Garnish != nil {
+			merged.Garnish = *params.Garnish
+		}
+		if params.Notes != nil {
+			merged.Notes = *params.Notes
+		}
+		if params.Steps != nil {
+			merged.Steps = params.Steps
+		}
+
+		if params.Materials != nil {
+			merged.Materials = params.Materials
+		} else {
+			for _, m := range current.Materials {
+				merged.Materials = append(merged.Materials, model.MaterialParams{Name: m.Name, Quantity: m.Quantity})
+			}
+		}
+
+		if err := validate.Struct(merged); err != nil {
+			return err
+		}
+
+		patched, err = repos.Cocktail.Update(ctx, id, merged)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fillDetailImages(patched), nil
+}
+
+func (u *cocktailUseCase) Delete(ctx context.Context, id int64) error {
+	return u.CocktailRepository.Delete(ctx, id)
+}
+
+func (u *cocktailUseCase) GetMakeable(ctx context.Context, params model.MakeableParams) ([]model.MakeableCocktail, error) {
+	var names []string
+	for _, name := range params.MaterialNames {
+		if n := strings.TrimSpace(name); n != "" {
+			names = append(names, n)
+		}
+	}
+
+	maxMissing := params.MaxMissing
+	if maxMissing < 0 {
+		maxMissing = 0
+	}
+
+	if len(params.MaterialIDs) == 0 && len(names) == 0 && maxMissing == 0 {
+		return []model.MakeableCocktail{}, nil
+	}
+
+	cocktails, err := u.CocktailRepository.GetMakeable(ctx, params.MaterialIDs, names, maxMissing)
+	if err != nil {
+		return nil, err
+	}
+
+	// fully makeable cocktails first, then the ones missing the fewest materials
+	sort.SliceStable(cocktails, func(i, j int) bool {
+		return len(cocktails[i].MissingMaterials) < len(cocktails[j].MissingMaterials)
+	})
+	for i := range cocktails {
+		fillImage(&cocktails[i].Cocktail)
+	}
+
+	return cocktails, nil
 }
@@ -4,27 +4,39 @@ import (
 	"context"
 	"testing"
 
+	"github.com/shake551/cocktails-api/domain/errs"
 	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/repository"
 	"github.com/shake551/cocktails-api/domain/repository_mock"
+	"github.com/shake551/cocktails-api/domain/units"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+// newUnitOfWork returns a UnitOfWork mock which runs every unit directly on repos.
+func newUnitOfWork(repos repository.Repositories) *repository_mock.UnitOfWork {
+	uow := new(repository_mock.UnitOfWork)
+	uow.On("Do", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(repository.Repositories) error) error {
+		return fn(repos)
+	})
+	return uow
+}
+
 func TestGetLimit(t *testing.T) {
 	type testcase struct {
-		name    string
-		limit   int64
-		offset  int64
-		keyword string
-		want    []model.Cocktail
+		name   string
+		limit  int64
+		offset int64
+		filter model.CocktailFilter
+		want   []model.Cocktail
 	}
 
 	tests := []testcase{
 		{
-			name:    "get two cocktail",
-			limit:   int64(2),
-			offset:  int64(0),
-			keyword: "",
+			name:   "get two cocktail",
+			limit:  int64(2),
+			offset: int64(0),
+			filter: model.CocktailFilter{},
 			want: []model.Cocktail{
 				{
 					ID:        1,
@@ -63,18 +75,40 @@ func TestGetLimit(t *testing.T) {
 		},
 	}
 
-	r.On("GetLimit", mock.Anything, int64(2), int64(0), "").Return(cocktails, nil)
+	r.On("GetLimit", mock.Anything, int64(2), int64(0), model.CocktailFilter{}).Return(cocktails, nil)
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			uc := &cocktailUseCase{r}
-			res, err := uc.GetLimit(context.Background(), tt.limit, tt.offset, tt.keyword)
+			uc := &cocktailUseCase{r, newUnitOfWork(repository.Repositories{Cocktail: r})}
+			res, err := uc.GetLimit(context.Background(), tt.limit, tt.offset, tt.filter)
 			assert.Equal(t, res, tt.want)
 			assert.Nil(t, err)
 		})
 	}
 }
 
+func TestGetLimitWithMaterialFilter(t *testing.T) {
+	r := new(repository_mock.CocktailRepository)
+
+	want := model.CocktailFilter{
+		Keyword:          "ジン",
+		Materials:        []string{"ジン", "トニック"},
+		ExcludeMaterials: []string{"レモン"},
+	}
+	r.On("GetLimit", mock.Anything, int64(30), int64(0), want).Return([]model.Cocktail{}, nil)
+	uc := &cocktailUseCase{r, newUnitOfWork(repository.Repositories{Cocktail: r})}
+
+	input := model.CocktailFilter{
+		Keyword:          " ジン",
+		Materials:        []string{"ジン", " トニック ", "ジン", ""},
+		ExcludeMaterials: []string{"レモン"},
+	}
+	res, err := uc.GetLimit(context.Background(), 30, 0, input)
+
+	assert.Equal(t, []model.Cocktail{}, res)
+	assert.Nil(t, err)
+}
+
 func TestGetById(t *testing.T) {
 	type testcase struct {
 		Name string
@@ -117,8 +151,8 @@ func TestGetById(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.Name, func(t *testing.T) {
 			r.On("GetByID", mock.Anything, int64(1)).Return(tc.Want, nil)
-			uc := &cocktailUseCase{r}
-			res, err := uc.GetById(context.Background(), tc.ID)
+			uc := &cocktailUseCase{r, newUnitOfWork(repository.Repositories{Cocktail: r})}
+			res, err := uc.GetById(context.Background(), tc.ID, "")
 
 			assert.Equal(t, res, tc.Want)
 			assert.Nil(t, err)
@@ -126,6 +160,107 @@ func TestGetById(t *testing.T) {
 	}
 }
 
+func TestGetByIdEstimatesStrength(t *testing.T) {
+	kahlua := 20.0
+	r := new(repository_mock.CocktailRepository)
+	r.On("GetByID", mock.Anything, int64(1)).Return(model.CocktailDetail{
+		ID:     1,
+		Name:   "カルーアミルク",
+		Method: model.MethodBuild,
+		Materials: []model.Material{
+			{ID: 1, Name: "カルーア", ABV: &kahlua, Quantity: model.MaterialQuantity{Quantity: 45, Unit: "ml"}},
+			{ID: 2, Name: "牛乳", Quantity: model.MaterialQuantity{Quantity: 90, Unit: "ml"}},
+		},
+	}, nil)
+	uc := &cocktailUseCase{r, newUnitOfWork(repository.Repositories{Cocktail: r})}
+
+	res, err := uc.GetById(context.Background(), 1, "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 6.1, *res.ABV)
+	assert.Equal(t, 0.71, *res.StandardDrinks)
+}
+
+func TestGetByIdImages(t *testing.T) {
+	r := new(repository_mock.CocktailRepository)
+	r.On("GetByID", mock.Anything, int64(1)).Return(model.CocktailDetail{ID: 1, Name: "モヒート", ImageURL: "/images/4"}, nil)
+	r.On("GetByID", mock.Anything, int64(2)).Return(model.CocktailDetail{ID: 2, Name: "ミモザ", ImageURL: "https://example.com/mimosa.jpg"}, nil)
+	uc := &cocktailUseCase{r, newUnitOfWork(repository.Repositories{Cocktail: r})}
+
+	res, err := uc.GetById(context.Background(), 1, "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, &model.ImageVariants{
+		Thumbnail: model.ImageFormats{JPEG: "/images/4/thumbnail.jpg", WebP: "/images/4/thumbnail.webp"},
+		Medium:    model.ImageFormats{JPEG: "/images/4/medium.jpg", WebP: "/images/4/medium.webp"},
+		Full:      model.ImageFormats{JPEG: "/images/4/full.jpg", WebP: "/images/4/full.webp"},
+	}, res.Images)
+
+	// an image hosted elsewhere has no variants
+	res, err = uc.GetById(context.Background(), 2, "")
+
+	assert.Nil(t, err)
+	assert.Nil(t, res.Images)
+}
+
+func TestGetByIdInUnits(t *testing.T) {
+	r := new(repository_mock.CocktailRepository)
+	r.On("GetByID", mock.Anything, int64(1)).Return(model.CocktailDetail{
+		ID:     1,
+		Name:   "マティーニ",
+		Method: model.MethodStir,
+		Materials: []model.Material{
+			{ID: 1, Name: "ジン", Quantity: model.MaterialQuantity{Quantity: 1.5, Unit: "oz"}},
+			{ID: 2, Name: "ドライベルモット", Quantity: model.MaterialQuantity{Quantity: 1, Unit: "tsp"}},
+			{ID: 3, Name: "オレンジビターズ", Quantity: model.MaterialQuantity{Quantity: 1, Unit: "dash"}},
+		},
+	}, nil)
+	uc := &cocktailUseCase{r, newUnitOfWork(repository.Repositories{Cocktail: r})}
+
+	res, err := uc.GetById(context.Background(), 1, units.Metric)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []model.MaterialQuantity{
+		{Quantity: 45, Unit: "ml"},
+		{Quantity: 5, Unit: "ml"},
+		{Quantity: 1, Unit: "dash"},
+	}, []model.MaterialQuantity{res.Materials[0].Quantity, res.Materials[1].Quantity, res.Materials[2].Quantity})
+}
+
+func TestBatch(t *testing.T) {
+	r := new(repository_mock.CocktailRepository)
+	r.On("GetByID", mock.Anything, int64(1)).Return(model.CocktailDetail{
+		ID:       1,
+		Name:     "カルーアミルク",
+		ImageURL: "/images/4",
+		Method:   model.MethodBuild,
+		Materials: []model.Material{
+			{ID: 1, Name: "カルーア", Quantity: model.MaterialQuantity{Quantity: 45, Unit: "ml"}},
+			{ID: 2, Name: "牛乳", Quantity: model.MaterialQuantity{Quantity: 90, Unit: "ml"}},
+		},
+	}, nil)
+	uc := &cocktailUseCase{r, newUnitOfWork(repository.Repositories{Cocktail: r})}
+
+	res, err := uc.Batch(context.Background(), 1, model.BatchParams{Servings: 20}, "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(20), res.Servings)
+	assert.Equal(t, model.MaterialQuantity{Quantity: 900, Unit: "ml"}, res.Materials[0].Quantity)
+	assert.Equal(t, model.MaterialQuantity{Quantity: 1800, Unit: "ml"}, res.Materials[1].Quantity)
+	assert.Nil(t, res.Water)
+	assert.Equal(t, "/images/4/medium.webp", res.Cocktail.Images.Medium.WebP)
+}
+
+func TestBatchInvalidServings(t *testing.T) {
+	r := new(repository_mock.CocktailRepository)
+	uc := &cocktailUseCase{r, newUnitOfWork(repository.Repositories{Cocktail: r})}
+
+	_, err := uc.Batch(context.Background(), 1, model.BatchParams{Servings: 0}, "")
+
+	assert.Equal(t, errs.KindValidation, errs.KindOf(err))
+	r.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
 func TestCreate(t *testing.T) {
 	type testcase struct {
 		Name  string
@@ -186,7 +321,7 @@ func TestCreate(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.Name, func(t *testing.T) {
 			r.On("Create", mock.Anything, tc.Input).Return(tc.Want, nil)
-			uc := &cocktailUseCase{r}
+			uc := &cocktailUseCase{r, newUnitOfWork(repository.Repositories{Cocktail: r})}
 
 			res, err := uc.Create(context.Background(), tc.Input)
 
@@ -195,3 +330,236 @@ func TestCreate(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateInvalidParams(t *testing.T) {
+	r := new(repository_mock.CocktailRepository)
+	uc := &cocktailUseCase{r, newUnitOfWork(repository.Repositories{Cocktail: r})}
+
+	_, err := uc.Create(context.Background(), model.CocktailParams{Name: ""})
+
+	assert.Equal(t, errs.KindValidation, errs.KindOf(err))
+	r.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPatch(t *testing.T) {
+	newName := "ゴッドマザー"
+	newReading := "ごっどまざー"
+	newMethod := model.MethodStir
+
+	current := model.CocktailDetail{
+		ID:      1,
+		Name:    "ゴットファーザー",
+		Reading: "ごっどふぁーざー",
+		Method:  model.MethodBuild,
+		Glass:   "ロックグラス",
+		Steps:   []string{"氷を入れたグラスに注ぐ", "軽くステアする"},
+		Materials: []model.Material{
+			{
+				ID:   1,
+				Name: "ウイスキー",
+				Quantity: model.MaterialQuantity{
+					Quantity: 30,
+					Unit:     "ml",
+				},
+			},
+		},
+	}
+
+	type testcase struct {
+		Name  string
+		Input model.CocktailPatchParams
+		Want  model.CocktailParams
+	}
+
+	tests := []testcase{
+		{
+			Name:  "rename only",
+			Input: model.CocktailPatchParams{Name: &newName},
+			Want: model.CocktailParams{
+				Name:    "ゴッドマザー",
+				Reading: "ごっどふぁーざー",
+				Method:  model.MethodBuild,
+				Glass:   "ロックグラス",
+				Steps:   []string{"氷を入れたグラスに注ぐ", "軽くステアする"},
+				Materials: []model.MaterialParams{
+					{
+						Name: "ウイスキー",
+						Quantity: model.MaterialQuantity{
+							Quantity: 30,
+							Unit:     "ml",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "replace materials",
+			Input: model.CocktailPatchParams{
+				Materials: []model.MaterialParams{
+					{
+						Name: "ウォッカ",
+						Quantity: model.MaterialQuantity{
+							Quantity: 45,
+							Unit:     "ml",
+						},
+					},
+				},
+			},
+			Want: model.CocktailParams{
+				Name:    "ゴットファーザー",
+				Reading: "ごっどふぁーざー",
+				Method:  model.MethodBuild,
+				Glass:   "ロックグラス",
+				Steps:   []string{"氷を入れたグラスに注ぐ", "軽くステアする"},
+				Materials: []model.MaterialParams{
+					{
+						Name: "ウォッカ",
+						Quantity: model.MaterialQuantity{
+							Quantity: 45,
+							Unit:     "ml",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:  "rename with reading",
+			Input: model.CocktailPatchParams{Name: &newName, Reading: &newReading},
+			Want: model.CocktailParams{
+				Name:    "ゴッドマザー",
+				Reading: "ごっどまざー",
+				Method:  model.MethodBuild,
+				Glass:   "ロックグラス",
+				Steps:   []string{"氷を入れたグラスに注ぐ", "軽くステアする"},
+				Materials: []model.MaterialParams{
+					{
+						Name: "ウイスキー",
+						Quantity: model.MaterialQuantity{
+							Quantity: 30,
+							Unit:     "ml",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:  "replace method and steps",
+			Input: model.CocktailPatchParams{Method: &newMethod, Steps: []string{"ミキシンググラスでステアする"}},
+			Want: model.CocktailParams{
+				Name:    "ゴットファーザー",
+				Reading: "ごっどふぁーざー",
+				Method:  model.MethodStir,
+				Glass:   "ロックグラス",
+				Steps:   []string{"ミキシンググラスでステアする"},
+				Materials: []model.MaterialParams{
+					{
+						Name: "ウイスキー",
+						Quantity: model.MaterialQuantity{
+							Quantity: 30,
+							Unit:     "ml",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			r := new(repository_mock.CocktailRepository)
+			r.On("GetByID", mock.Anything, int64(1)).Return(current, nil)
+			r.On("Update", mock.Anything, int64(1), tc.Want).Return(&model.CocktailDetail{ID: 1}, nil)
+			uc := &cocktailUseCase{r, newUnitOfWork(repository.Repositories{Cocktail: r})}
+
+			_, err := uc.Patch(context.Background(), 1, tc.Input)
+
+			assert.Nil(t, err)
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPatchNotFound(t *testing.T) {
+	r := new(repository_mock.CocktailRepository)
+	r.On("GetByID", mock.Anything, int64(2)).Return(model.CocktailDetail{}, repository.ErrCocktailNotFound)
+	uc := &cocktailUseCase{r, newUnitOfWork(repository.Repositories{Cocktail: r})}
+
+	_, err := uc.Patch(context.Background(), 2, model.CocktailPatchParams{})
+
+	assert.ErrorIs(t, err, repository.ErrCocktailNotFound)
+}
+
+func TestDelete(t *testing.T) {
+	r := new(repository_mock.CocktailRepository)
+	r.On("Delete", mock.Anything, int64(1)).Return(repository.ErrCocktailInUse)
+	uc := &cocktailUseCase{r, newUnitOfWork(repository.Repositories{Cocktail: r})}
+
+	err := uc.Delete(context.Background(), 1)
+
+	assert.ErrorIs(t, err, repository.ErrCocktailInUse)
+}
+
+func TestGetMakeable(t *testing.T) {
+	makeable := model.MakeableCocktail{
+		Cocktail:         model.Cocktail{ID: 1, Name: "スコッチ・オーレ"},
+		MissingMaterials: []model.Material{},
+	}
+	missingOne := model.MakeableCocktail{
+		Cocktail: model.Cocktail{ID: 2, Name: "カルーア・ミルク"},
+		MissingMaterials: []model.Material{
+			{ID: 3, Name: "ミルク", Quantity: model.MaterialQuantity{Quantity: 90, Unit: "ml"}},
+		},
+	}
+
+	type testcase struct {
+		Name  string
+		Input model.MakeableParams
+		Names []string
+		Max   int64
+		Want  []model.MakeableCocktail
+	}
+
+	tests := []testcase{
+		{
+			Name:  "sort by missing count",
+			Input: model.MakeableParams{MaterialIDs: []int64{1, 2}, MaterialNames: []string{" ミルク ", ""}, MaxMissing: 1},
+			Names: []string{"ミルク"},
+			Max:   1,
+			Want:  []model.MakeableCocktail{makeable, missingOne},
+		},
+		{
+			Name:  "negative max missing",
+			Input: model.MakeableParams{MaterialIDs: []int64{1, 2}, MaxMissing: -1},
+			Max:   0,
+			Want:  []model.MakeableCocktail{makeable},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			r := new(repository_mock.CocktailRepository)
+			repoResult := append([]model.MakeableCocktail{}, tc.Want...)
+			if len(repoResult) == 2 {
+				repoResult[0], repoResult[1] = repoResult[1], repoResult[0]
+			}
+			r.On("GetMakeable", mock.Anything, tc.Input.MaterialIDs, tc.Names, tc.Max).Return(repoResult, nil)
+			uc := &cocktailUseCase{r, newUnitOfWork(repository.Repositories{Cocktail: r})}
+
+			res, err := uc.GetMakeable(context.Background(), tc.Input)
+
+			assert.Equal(t, tc.Want, res)
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestGetMakeableWithoutMaterials(t *testing.T) {
+	r := new(repository_mock.CocktailRepository)
+	uc := &cocktailUseCase{r, newUnitOfWork(repository.Repositories{Cocktail: r})}
+
+	res, err := uc.GetMakeable(context.Background(), model.MakeableParams{})
+
+	assert.Equal(t, []model.MakeableCocktail{}, res)
+	assert.Nil(t, err)
+	r.AssertNotCalled(t, "GetMakeable", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
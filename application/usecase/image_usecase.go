@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/photo"
+	"github.com/shake551/cocktails-api/domain/repository"
+)
+
+type ImageUseCase interface {
+	UploadCocktailImage(ctx context.Context, cocktailID int64, data []byte) (model.CocktailImage, error)
+	Get(ctx context.Context, id int64) (model.Image, error)
+	GetVariant(ctx context.Context, id int64, name string) (model.Image, error)
+	MakeMissingVariants(ctx context.Context) (int, error)
+}
+
+type imageUseCase struct {
+	images repository.ImageRepository
+	uow    repository.UnitOfWork
+}
+
+func NewImageUseCase(images repository.ImageRepository, uow repository.UnitOfWork) ImageUseCase {
+	return &imageUseCase{images, uow}
+}
+
+// UploadCocktailImage stores the image with its variants and points the image_url of the cocktail at it.
+// The image itself is stored as the full size JPEG variant, so the metadata of the upload is not kept anywhere.
+// The image it replaces is deleted when it was stored here too.
+func (u *imageUseCase) UploadCocktailImage(ctx context.Context, cocktailID int64, data []byte) (model.CocktailImage, error) {
+	if _, err := photo.Check(data); err != nil {
+		return model.CocktailImage{}, err
+	}
+	variants, err := photo.Variants(data)
+	if err != nil {
+		return model.CocktailImage{}, err
+	}
+	full := variants[photo.VariantName(photo.Full, photo.JPEG)]
+
+	// the image may be kept outside the database, so it is stored before the unit and removed again when the unit fails
+	id, err := u.images.Create(ctx, full)
+	if err != nil {
+		return model.CocktailImage{}, err
+	}
+	if err := u.images.PutVariants(ctx, id, variants); err != nil {
+		u.deleteImage(ctx, id)
+		return model.CocktailImage{}, err
+	}
+
+	var previous string
+	err = u.uow.Do(ctx, func(repos repository.Repositories) error {
+		c, err := repos.Cocktail.GetByID(ctx, cocktailID)
+		if err != nil {
+			return err
+		}
+
+		previous = c.ImageURL
+		return repos.Cocktail.UpdateImageURL(ctx, cocktailID, photo.URL(id))
+	})
+	if err != nil {
+		u.deleteImage(ctx, id)
+		return model.CocktailImage{}, err
+	}
+
+	if previousID, ok := photo.IDOf(previous); ok {
+		u.deleteImage(ctx, previousID)
+	}
+
+	return model.CocktailImage{
+		ID:          id,
+		CocktailID:  cocktailID,
+		URL:         photo.URL(id),
+		ContentType: photo.ContentType(full),
+		Size:        int64(len(full)),
+		Images:      photo.VariantURLs(photo.URL(id)),
+	}, nil
+}
+
+// deleteImage removes an image nothing points at. A failure only leaves the image behind, so it is logged rather than returned.
+func (u *imageUseCase) deleteImage(ctx context.Context, id int64) {
+	if err := u.images.Delete(ctx, id); err != nil && !errors.Is(err, repository.ErrImageNotFound) {
+		log.Printf("failed to delete unused image. id: %d, err: %v", id, err)
+	}
+}
+
+func (u *imageUseCase) Get(ctx context.Context, id int64) (model.Image, error) {
+	data, err := u.images.Get(ctx, id)
+	if err != nil {
+		return model.Image{}, err
+	}
+	return model.Image{ID: id, ContentType: photo.ContentType(data), Data: data}, nil
+}
+
+// GetVariant returns the variant of the image by its name, like "thumbnail.jpg".
+// Variants are only ever made on upload or by MakeMissingVariants, so a request never decodes an image.
+func (u *imageUseCase) GetVariant(ctx context.Context, id int64, name string) (model.Image, error) {
+	if !photo.IsVariant(name) {
+		return model.Image{}, repository.ErrImageNotFound
+	}
+
+	data, err := u.images.GetVariant(ctx, id, name)
+	if err != nil {
+		return model.Image{}, err
+	}
+	return model.Image{ID: id, ContentType: photo.ContentType(data), Data: data}, nil
+}
+
+// MakeMissingVariants makes and stores the variants of the images missing any of them, like the ones uploaded
+// before variants were made, and returns how many images it made them of.
+// An image which cannot be decoded has nothing to be made of it, so it is logged and skipped.
+func (u *imageUseCase) MakeMissingVariants(ctx context.Context) (int, error) {
+	missing := map[int64]bool{}
+	var ids []int64
+	for _, name := range photo.VariantNames() {
+		without, err := u.images.WithoutVariant(ctx, name)
+		if err != nil {
+			return 0, err
+		}
+		for _, id := range without {
+			if !missing[id] {
+				missing[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	made := 0
+	for _, id := range ids {
+		data, err := u.images.Get(ctx, id)
+		if errors.Is(err, repository.ErrImageNotFound) {
+			// deleted since it was listed
+			continue
+		}
+		if err != nil {
+			return made, err
+		}
+
+		variants, err := photo.Variants(data)
+		if err != nil {
+			log.Printf("failed to make image variants. id: %d, err: %v", id, err)
+			continue
+		}
+		if err := u.images.PutVariants(ctx, id, variants); err != nil {
+			return made, err
+		}
+		made++
+	}
+	return made, nil
+}
+
+// fillImage sets the variant URLs of the image of the cocktail.
+func fillImage(c *model.Cocktail) {
+	c.Images = photo.VariantURLs(c.ImageURL)
+}
+
+func fillImages(cocktails []model.Cocktail) []model.Cocktail {
+	for i := range cocktails {
+		fillImage(&cocktails[i])
+	}
+	return cocktails
+}
+
+// fillDetailImages sets the variant URLs of the image of the cocktail, which may be nil after an error.
+func fillDetailImages(d *model.CocktailDetail) *model.CocktailDetail {
+	if d != nil {
+		d.Images = photo.VariantURLs(d.ImageURL)
+	}
+	return d
+}
@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/photo"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/domain/repository_mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// pngImage is a small PNG upload, and variants are the ones made of it.
+var (
+	pngImage = encodePNG(40, 30)
+	variants = mustVariants(pngImage)
+	full     = variants["full.jpg"]
+)
+
+func encodePNG(w, h int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func mustVariants(data []byte) map[string][]byte {
+	v, err := photo.Variants(data)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func TestUploadCocktailImage(t *testing.T) {
+	r := new(repository_mock.CocktailRepository)
+	r.On("GetByID", mock.Anything, int64(1)).Return(model.CocktailDetail{ID: 1, ImageURL: "/images/3"}, nil)
+	r.On("UpdateImageURL", mock.Anything, int64(1), "/images/4").Return(nil)
+	images := new(repository_mock.ImageRepository)
+	images.On("Create", mock.Anything, full).Return(int64(4), nil)
+	images.On("PutVariants", mock.Anything, int64(4), variants).Return(nil)
+	images.On("Delete", mock.Anything, int64(3)).Return(nil)
+	uc := &imageUseCase{images, newUnitOfWork(repository.Repositories{Cocktail: r})}
+
+	res, err := uc.UploadCocktailImage(context.Background(), 1, pngImage)
+
+	// the image is stored as the full size JPEG, whatever it was uploaded as
+	assert.Nil(t, err)
+	assert.Equal(t, model.CocktailImage{
+		ID:          4,
+		CocktailID:  1,
+		URL:         "/images/4",
+		ContentType: "image/jpeg",
+		Size:        int64(len(full)),
+		Images:      photo.VariantURLs("/images/4"),
+	}, res)
+	assert.Equal(t, "/images/4/thumbnail.webp", res.Images.Thumbnail.WebP)
+	r.AssertExpectations(t)
+	images.AssertExpectations(t)
+}
+
+func TestUploadCocktailImageKeepsExternalImages(t *testing.T) {
+	r := new(repository_mock.CocktailRepository)
+	r.On("GetByID", mock.Anything, int64(1)).Return(model.CocktailDetail{ID: 1, ImageURL: "https://example.com/images/3"}, nil)
+	r.On("UpdateImageURL", mock.Anything, int64(1), "/images/4").Return(nil)
+	images := new(repository_mock.ImageRepository)
+	images.On("Create", mock.Anything, full).Return(int64(4), nil)
+	images.On("PutVariants", mock.Anything, int64(4), variants).Return(nil)
+	uc := &imageUseCase{images, newUnitOfWork(repository.Repositories{Cocktail: r})}
+
+	_, err := uc.UploadCocktailImage(context.Background(), 1, pngImage)
+
+	assert.Nil(t, err)
+	images.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestUploadCocktailImageUnsupported(t *testing.T) {
+	images := new(repository_mock.ImageRepository)
+	uc := &imageUseCase{images, newUnitOfWork(repository.Repositories{})}
+
+	_, err := uc.UploadCocktailImage(context.Background(), 1, []byte("<html></html>"))
+
+	assert.ErrorIs(t, err, photo.ErrUnsupported)
+	images.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUploadCocktailImageNotFound(t *testing.T) {
+	r := new(repository_mock.CocktailRepository)
+	r.On("GetByID", mock.Anything, int64(1)).Return(model.CocktailDetail{}, repository.ErrCocktailNotFound)
+	images := new(repository_mock.ImageRepository)
+	images.On("Create", mock.Anything, full).Return(int64(4), nil)
+	images.On("PutVariants", mock.Anything, int64(4), variants).Return(nil)
+	images.On("Delete", mock.Anything, int64(4)).Return(nil)
+	uc := &imageUseCase{images, newUnitOfWork(repository.Repositories{Cocktail: r})}
+
+	_, err := uc.UploadCocktailImage(context.Background(), 1, pngImage)
+
+	// the stored image is removed again, since no cocktail points at it
+	assert.ErrorIs(t, err, repository.ErrCocktailNotFound)
+	images.AssertExpectations(t)
+}
+
+func TestUploadCocktailImageCorrupt(t *testing.T) {
+	images := new(repository_mock.ImageRepository)
+	uc := &imageUseCase{images, newUnitOfWork(repository.Repositories{})}
+
+	// sniffed as a PNG, but cut off before any pixels
+	_, err := uc.UploadCocktailImage(context.Background(), 1, pngImage[:20])
+
+	assert.ErrorIs(t, err, photo.ErrCorrupt)
+	images.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUploadCocktailImageVariantsFail(t *testing.T) {
+	images := new(repository_mock.ImageRepository)
+	images.On("Create", mock.Anything, full).Return(int64(4), nil)
+	images.On("PutVariants", mock.Anything, int64(4), variants).Return(errors.New("disk full"))
+	images.On("Delete", mock.Anything, int64(4)).Return(nil)
+	uc := &imageUseCase{images, newUnitOfWork(repository.Repositories{})}
+
+	_, err := uc.UploadCocktailImage(context.Background(), 1, pngImage)
+
+	assert.EqualError(t, err, "disk full")
+	images.AssertExpectations(t)
+}
+
+func TestGetImageVariant(t *testing.T) {
+	images := new(repository_mock.ImageRepository)
+	images.On("GetVariant", mock.Anything, int64(4), "thumbnail.webp").Return(variants["thumbnail.webp"], nil)
+	uc := &imageUseCase{images, newUnitOfWork(repository.Repositories{})}
+
+	res, err := uc.GetVariant(context.Background(), 4, "thumbnail.webp")
+
+	assert.Nil(t, err)
+	assert.Equal(t, model.Image{ID: 4, ContentType: "image/webp", Data: variants["thumbnail.webp"]}, res)
+	images.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestGetImageVariantNotFound(t *testing.T) {
+	// an image uploaded before variants were made has none until they are made
+	images := new(repository_mock.ImageRepository)
+	images.On("GetVariant", mock.Anything, int64(4), "full.jpg").Return(nil, repository.ErrImageNotFound)
+	uc := &imageUseCase{images, newUnitOfWork(repository.Repositories{})}
+
+	_, err := uc.GetVariant(context.Background(), 4, "full.jpg")
+	assert.ErrorIs(t, err, repository.ErrImageNotFound)
+	images.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+
+	// names other than the variants are never looked up
+	_, err = uc.GetVariant(context.Background(), 4, "original.png")
+	assert.ErrorIs(t, err, repository.ErrImageNotFound)
+	_, err = uc.GetVariant(context.Background(), 4, "thumbnail.png")
+	assert.ErrorIs(t, err, repository.ErrImageNotFound)
+	images.AssertNumberOfCalls(t, "GetVariant", 1)
+}
+
+func TestMakeMissingVariants(t *testing.T) {
+	// 4 has no variants, 5 has no WebP thumbnail, 6 cannot be decoded and 7 was deleted since it was listed
+	images := new(repository_mock.ImageRepository)
+	images.On("WithoutVariant", mock.Anything, "full.jpg").Return([]int64{4, 6, 7}, nil)
+	images.On("WithoutVariant", mock.Anything, "full.webp").Return([]int64{4, 6, 7}, nil)
+	images.On("WithoutVariant", mock.Anything, "medium.jpg").Return([]int64{4, 6, 7}, nil)
+	images.On("WithoutVariant", mock.Anything, "medium.webp").Return([]int64{4, 6, 7}, nil)
+	images.On("WithoutVariant", mock.Anything, "thumbnail.jpg").Return([]int64{4, 6, 7}, nil)
+	images.On("WithoutVariant", mock.Anything, "thumbnail.webp").Return([]int64{4, 5, 6, 7}, nil)
+	images.On("Get", mock.Anything, int64(4)).Return(pngImage, nil)
+	images.On("Get", mock.Anything, int64(5)).Return(pngImage, nil)
+	images.On("Get", mock.Anything, int64(6)).Return([]byte("not an image"), nil)
+	images.On("Get", mock.Anything, int64(7)).Return(nil, repository.ErrImageNotFound)
+	images.On("PutVariants", mock.Anything, int64(4), variants).Return(nil)
+	images.On("PutVariants", mock.Anything, int64(5), variants).Return(nil)
+	uc := &imageUseCase{images, newUnitOfWork(repository.Repositories{})}
+
+	made, err := uc.MakeMissingVariants(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, made)
+	images.AssertExpectations(t)
+	images.AssertNumberOfCalls(t, "Get", 4)
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"github.com/shake551/cocktails-api/domain/errs"
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/domain/validate"
+	"strings"
+)
+
+type MaterialUseCase interface {
+	GetLimit(ctx context.Context, limit int64, offset int64, keyword string) ([]model.MaterialItem, error)
+	GetByID(ctx context.Context, id int64) (model.MaterialDetail, error)
+	Create(ctx context.Context, params model.MaterialNameParams) (*model.MaterialItem, error)
+	Rename(ctx context.Context, id int64, params model.MaterialNameParams) (*model.MaterialItem, error)
+	UpdateABV(ctx context.Context, id int64, params model.MaterialABVParams) (*model.MaterialItem, error)
+	AddAlias(ctx context.Context, materialID int64, params model.MaterialNameParams) (*model.MaterialAlias, error)
+	DeleteAlias(ctx context.Context, materialID int64, aliasID int64) error
+	Merge(ctx context.Context, params model.MaterialMergeParams) (model.MaterialDetail, error)
+}
+
+type materialUseCase struct {
+	repository.MaterialRepository
+}
+
+func NewMaterialUseCase(r repository.MaterialRepository) MaterialUseCase {
+	return &materialUseCase{r}
+}
+
+func (u *materialUseCase) GetLimit(ctx context.Context, limit int64, offset int64, keyword string) ([]model.MaterialItem, error) {
+	return u.MaterialRepository.GetLimit(ctx, limit, offset, strings.TrimSpace(keyword))
+}
+
+func (u *materialUseCase) GetByID(ctx context.Context, id int64) (model.MaterialDetail, error) {
+	m, err := u.MaterialRepository.GetByID(ctx, id)
+	fillImages(m.Cocktails)
+	return m, err
+}
+
+func (u *materialUseCase) Create(ctx context.Context, params model.MaterialNameParams) (*model.MaterialItem, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	if err := validate.Struct(params); err != nil {
+		return nil, err
+	}
+	return u.MaterialRepository.Create(ctx, params)
+}
+
+func (u *materialUseCase) Rename(ctx context.Context, id int64, params model.MaterialNameParams) (*model.MaterialItem, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	if err := validate.Struct(params); err != nil {
+		return nil, err
+	}
+	return u.MaterialRepository.Rename(ctx, id, params)
+}
+
+func (u *materialUseCase) UpdateABV(ctx context.Context, id int64, params model.MaterialABVParams) (*model.MaterialItem, error) {
+	if err := validate.Struct(params); err != nil {
+		return nil, err
+	}
+	return u.MaterialRepository.UpdateABV(ctx, id, params)
+}
+
+func (u *materialUseCase) AddAlias(ctx context.Context, materialID int64, params model.MaterialNameParams) (*model.MaterialAlias, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	if err := validate.Struct(params); err != nil {
+		return nil, err
+	}
+	return u.MaterialRepository.AddAlias(ctx, materialID, params)
+}
+
+func (u *materialUseCase) DeleteAlias(ctx context.Context, materialID int64, aliasID int64) error {
+	return u.MaterialRepository.DeleteAlias(ctx, materialID, aliasID)
+}
+
+// Merge folds the source material into the target one, for duplicates like "ジン" and "ドライジン".
+func (u *materialUseCase) Merge(ctx context.Context, params model.MaterialMergeParams) (model.MaterialDetail, error) {
+	if err := validate.Struct(params); err != nil {
+		return model.MaterialDetail{}, err
+	}
+	if params.SourceID == params.TargetID {
+		return model.MaterialDetail{}, errs.InvalidFields(errs.FieldError{Field: "target_id", Message: "must differ from source_id"})
+	}
+	m, err := u.MaterialRepository.Merge(ctx, params.SourceID, params.TargetID)
+	fillImages(m.Cocktails)
+	return m, err
+}
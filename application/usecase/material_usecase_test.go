@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shake551/cocktails-api/domain/errs"
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/domain/repository_mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMaterialGetLimit(t *testing.T) {
+	materials := []model.MaterialItem{
+		{
+			ID:        1,
+			Name:      "スコッチウイスキー",
+			CreatedAt: 1000000000,
+			UpdatedAt: 1000000000,
+		},
+	}
+
+	r := new(repository_mock.MaterialRepository)
+	r.On("GetLimit", mock.Anything, int64(30), int64(0), "ウイスキー").Return(materials, nil)
+	uc := &materialUseCase{r}
+
+	res, err := uc.GetLimit(context.Background(), 30, 0, " ウイスキー ")
+
+	assert.Equal(t, materials, res)
+	assert.Nil(t, err)
+}
+
+func TestMaterialCreate(t *testing.T) {
+	type testcase struct {
+		Name    string
+		Input   model.MaterialNameParams
+		Want    *model.MaterialItem
+		WantErr error
+	}
+
+	tests := []testcase{
+		{
+			Name:  "success",
+			Input: model.MaterialNameParams{Name: "カルーア"},
+			Want:  &model.MaterialItem{ID: 2, Name: "カルーア"},
+		},
+		{
+			Name:    "duplicate",
+			Input:   model.MaterialNameParams{Name: "ミルク"},
+			WantErr: repository.ErrMaterialDuplicate,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			r := new(repository_mock.MaterialRepository)
+			r.On("Create", mock.Anything, tc.Input).Return(tc.Want, tc.WantErr)
+			uc := &materialUseCase{r}
+
+			res, err := uc.Create(context.Background(), model.MaterialNameParams{Name: "  " + tc.Input.Name})
+
+			assert.Equal(t, tc.Want, res)
+			assert.ErrorIs(t, err, tc.WantErr)
+		})
+	}
+}
+
+func TestMaterialCreateEmptyName(t *testing.T) {
+	r := new(repository_mock.MaterialRepository)
+	uc := &materialUseCase{r}
+
+	_, err := uc.Create(context.Background(), model.MaterialNameParams{Name: "   "})
+
+	assert.Equal(t, errs.KindValidation, errs.KindOf(err))
+	r.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestMaterialGetByID(t *testing.T) {
+	want := model.MaterialDetail{
+		ID:   3,
+		Name: "ミルク",
+		Cocktails: []model.Cocktail{
+			{ID: 1, Name: "スコッチ・オーレ"},
+		},
+	}
+
+	r := new(repository_mock.MaterialRepository)
+	r.On("GetByID", mock.Anything, int64(3)).Return(want, nil)
+	uc := &materialUseCase{r}
+
+	res, err := uc.GetByID(context.Background(), 3)
+
+	assert.Equal(t, want, res)
+	assert.Nil(t, err)
+}
+
+func TestMaterialAddAlias(t *testing.T) {
+	want := &model.MaterialAlias{ID: 1, MaterialID: 3, Name: "Milk"}
+
+	r := new(repository_mock.MaterialRepository)
+	r.On("AddAlias", mock.Anything, int64(3), model.MaterialNameParams{Name: "Milk"}).Return(want, nil)
+	uc := &materialUseCase{r}
+
+	res, err := uc.AddAlias(context.Background(), 3, model.MaterialNameParams{Name: " Milk "})
+
+	assert.Equal(t, want, res)
+	assert.Nil(t, err)
+}
+
+func TestMaterialUpdateABVOutOfRange(t *testing.T) {
+	abv := 101.0
+	r := new(repository_mock.MaterialRepository)
+	uc := &materialUseCase{r}
+
+	_, err := uc.UpdateABV(context.Background(), 1, model.MaterialABVParams{ABV: &abv})
+
+	assert.Equal(t, errs.KindValidation, errs.KindOf(err))
+	assert.Equal(t, []errs.FieldError{{Field: "abv", Message: "must be 100 or less"}}, errs.FieldsOf(err))
+	r.AssertNotCalled(t, "UpdateABV", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMaterialMerge(t *testing.T) {
+	type testcase struct {
+		Name      string
+		Input     model.MaterialMergeParams
+		WantField string
+	}
+
+	tests := []testcase{
+		{Name: "missing source", Input: model.MaterialMergeParams{TargetID: 2}, WantField: "source_id"},
+		{Name: "same material", Input: model.MaterialMergeParams{SourceID: 2, TargetID: 2}, WantField: "target_id"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			r := new(repository_mock.MaterialRepository)
+			uc := &materialUseCase{r}
+
+			_, err := uc.Merge(context.Background(), tc.Input)
+
+			assert.Equal(t, errs.KindValidation, errs.KindOf(err))
+			assert.Equal(t, tc.WantField, errs.FieldsOf(err)[0].Field)
+			r.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+
+	want := model.MaterialDetail{ID: 2, Name: "牛乳", Aliases: []model.MaterialAlias{{ID: 1, MaterialID: 2, Name: "ミルク"}}}
+	r := new(repository_mock.MaterialRepository)
+	r.On("Merge", mock.Anything, int64(3), int64(2)).Return(want, nil)
+	uc := &materialUseCase{r}
+
+	res, err := uc.Merge(context.Background(), model.MaterialMergeParams{SourceID: 3, TargetID: 2})
+
+	assert.Equal(t, want, res)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shake551/cocktails-api/domain/errs"
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/domain/search"
+)
+
+type SearchUseCase interface {
+	SearchCocktails(ctx context.Context, query string, limit int64) ([]model.CocktailSearchResult, error)
+}
+
+// searchUseCase answers from an in-process index rebuilt from the repository once it is older than refresh,
+// so writes made through any instance show up within that interval.
+type searchUseCase struct {
+	repository.CocktailRepository
+	refresh time.Duration
+
+	mu      sync.Mutex
+	index   *search.Index
+	builtAt time.Time
+}
+
+func NewSearchUseCase(r repository.CocktailRepository, refresh time.Duration) SearchUseCase {
+	return &searchUseCase{CocktailRepository: r, refresh: refresh}
+}
+
+func (u *searchUseCase) SearchCocktails(ctx context.Context, query string, limit int64) ([]model.CocktailSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errs.InvalidFields(errs.FieldError{Field: "q", Message: "must not be empty"})
+	}
+
+	index, err := u.currentIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := index.Search(query, int(limit))
+	for i := range results {
+		fillImage(&results[i].Cocktail)
+	}
+	return results, nil
+}
+
+// currentIndex returns the index, rebuilding it first when it is stale. Searches arriving meanwhile wait for the rebuild.
+func (u *searchUseCase) currentIndex(ctx context.Context) (*search.Index, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.index != nil && time.Since(u.builtAt) < u.refresh {
+		return u.index, nil
+	}
+
+	cocktails, err := u.CocktailRepository.GetAllDetails(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u.index = search.NewIndex(cocktails)
+	u.builtAt = time.Now()
+	return u.index, nil
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shake551/cocktails-api/domain/errs"
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/repository_mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchCocktails(t *testing.T) {
+	cocktails := []model.CocktailDetail{
+		{ID: 1, Name: "マルガリータ", Materials: []model.Material{{ID: 1, Name: "テキーラ"}}},
+		{ID: 2, Name: "スコッチ・オーレ", Materials: []model.Material{{ID: 2, Name: "スコッチ"}}},
+	}
+
+	r := new(repository_mock.CocktailRepository)
+	r.On("GetAllDetails", mock.Anything).Return(cocktails, nil)
+	uc := NewSearchUseCase(r, time.Minute)
+
+	res, err := uc.SearchCocktails(context.Background(), " まるがりいた ", 10)
+	assert.Nil(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, int64(1), res[0].Cocktail.ID)
+
+	res, err = uc.SearchCocktails(context.Background(), "すこっち", 10)
+	assert.Nil(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, int64(2), res[0].Cocktail.ID)
+
+	r.AssertNumberOfCalls(t, "GetAllDetails", 1)
+}
+
+func TestSearchCocktailsRebuildsStaleIndex(t *testing.T) {
+	r := new(repository_mock.CocktailRepository)
+	r.On("GetAllDetails", mock.Anything).Return([]model.CocktailDetail{}, nil)
+	uc := NewSearchUseCase(r, 0)
+
+	for i := 0; i < 2; i++ {
+		_, err := uc.SearchCocktails(context.Background(), "ジン", 10)
+		assert.Nil(t, err)
+	}
+
+	r.AssertNumberOfCalls(t, "GetAllDetails", 2)
+}
+
+func TestSearchCocktailsEmptyQuery(t *testing.T) {
+	r := new(repository_mock.CocktailRepository)
+	uc := NewSearchUseCase(r, time.Minute)
+
+	_, err := uc.SearchCocktails(context.Background(), "  ", 10)
+
+	assert.Equal(t, errs.KindValidation, errs.KindOf(err))
+	r.AssertNotCalled(t, "GetAllDetails", mock.Anything)
+}
@@ -2,38 +2,57 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"github.com/shake551/cocktails-api/domain/event"
 	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/photo"
 	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/domain/strength"
+	"github.com/shake551/cocktails-api/domain/validate"
+	"time"
 )
 
 type ShopUseCase interface {
 	GetLimit(ctx context.Context, limit int64, offset int64) ([]model.Shop, error)
 	Create(ctx context.Context, params model.ShopParams) (*model.Shop, error)
 	GetByID(ctx context.Context, id int64) (model.Shop, error)
-	GetShopCocktailList(ctx context.Context, shopID int64, limit int64, offset int64) ([]model.Cocktail, error)
+	Update(ctx context.Context, id int64, params model.ShopParams) (*model.Shop, error)
+	GetShopCocktailList(ctx context.Context, shopID int64, limit int64, offset int64) ([]model.ShopMenuCocktail, error)
 	AddShopCocktail(ctx context.Context, shopID int64, params model.ShopCocktailParams) ([]*model.ShopCocktail, error)
+	UpdateShopCocktailPrice(ctx context.Context, shopID int64, cocktailID int64, params model.ShopCocktailPriceParams) (*model.ShopCocktail, error)
+	UpdateShopCocktailAvailability(ctx context.Context, shopID int64, cocktailID int64, params model.ShopCocktailAvailability) (*model.ShopCocktailAvailability, error)
 	GetShopCocktailDetail(ctx context.Context, shopID int64, cocktailID int64) (model.CocktailDetail, error)
 	GetUnprovidedOrderList(ctx context.Context, shopID int64, limit int64, offset int64) ([]*model.TableOrder, error)
 	AddTable(ctx context.Context, shopID int64) (*model.Table, error)
 	GetTable(ctx context.Context, shopID int64, tableID int64) (*model.Table, error)
 	GetTableOrderList(ctx context.Context, ShopID int64, tableID int64, unprovided bool) ([]*model.TableOrder, error)
+	GetBill(ctx context.Context, shopID int64, tableID int64) (*model.Bill, error)
 	Order(ctx context.Context, shopID int64, tableID int64, params model.OrderParams) ([]*model.Order, error)
 	OrderProvide(ctx context.Context, shopID int64, tableID int64, orderID int64) error
+	TransitionOrder(ctx context.Context, shopID int64, tableID int64, orderID int64, status model.OrderStatus) (*model.Order, error)
+	SubscribeOrders(shopID int64) (<-chan model.OrderEvent, func())
+	GetInventory(ctx context.Context, shopID int64) ([]model.InventoryItem, error)
+	UpdateInventory(ctx context.Context, shopID int64, params model.InventoryParams) ([]model.InventoryItem, error)
 }
 
 type shopUseCase struct {
 	repository.ShopRepository
+	hub *event.OrderHub
+	uow repository.UnitOfWork
 }
 
-func NewShopUseCase(r repository.ShopRepository) ShopUseCase {
-	return &shopUseCase{r}
+func NewShopUseCase(r repository.ShopRepository, hub *event.OrderHub, uow repository.UnitOfWork) ShopUseCase {
+	return &shopUseCase{r, hub, uow}
 }
 
 func (u *shopUseCase) GetLimit(ctx context.Context, limit int64, offset int64) ([]model.Shop, error) {
-	return u.GetLimit(ctx, limit, offset)
+	return u.ShopRepository.GetLimit(ctx, limit, offset)
 }
 
 func (u *shopUseCase) Create(ctx context.Context, params model.ShopParams) (*model.Shop, error) {
+	if err := validate.Struct(params); err != nil {
+		return nil, err
+	}
 	return u.ShopRepository.Create(ctx, params)
 }
 
@@ -41,16 +60,57 @@ func (u *shopUseCase) GetByID(ctx context.Context, id int64) (model.Shop, error)
 	return u.ShopRepository.GetByID(ctx, id)
 }
 
-func (u *shopUseCase) GetShopCocktailList(ctx context.Context, shopID int64, limit int64, offset int64) ([]model.Cocktail, error) {
-	return u.ShopRepository.GetShopCocktailList(ctx, shopID, limit, offset)
+func (u *shopUseCase) Update(ctx context.Context, id int64, params model.ShopParams) (*model.Shop, error) {
+	if err := validate.Struct(params); err != nil {
+		return nil, err
+	}
+	return u.ShopRepository.Update(ctx, id, params)
+}
+
+func (u *shopUseCase) GetShopCocktailList(ctx context.Context, shopID int64, limit int64, offset int64) ([]model.ShopMenuCocktail, error) {
+	cocktails, err := u.ShopRepository.GetShopCocktailList(ctx, shopID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	for i, c := range cocktails {
+		cocktails[i].Available = c.InStock && !c.Availability.IsSoldOut(now)
+		cocktails[i].Images = photo.VariantURLs(c.ImageURL)
+	}
+
+	return cocktails, nil
 }
 
 func (u *shopUseCase) AddShopCocktail(ctx context.Context, shopID int64, params model.ShopCocktailParams) ([]*model.ShopCocktail, error) {
+	if err := validate.Struct(params); err != nil {
+		return nil, err
+	}
 	return u.ShopRepository.AddShopCocktail(ctx, shopID, params)
 }
 
+func (u *shopUseCase) UpdateShopCocktailPrice(ctx context.Context, shopID int64, cocktailID int64, params model.ShopCocktailPriceParams) (*model.ShopCocktail, error) {
+	if err := validate.Struct(params); err != nil {
+		return nil, err
+	}
+	return u.ShopRepository.UpdateShopCocktailPrice(ctx, shopID, cocktailID, params)
+}
+
+func (u *shopUseCase) UpdateShopCocktailAvailability(ctx context.Context, shopID int64, cocktailID int64, params model.ShopCocktailAvailability) (*model.ShopCocktailAvailability, error) {
+	if !params.SoldOut {
+		params.BackAt = 0
+	}
+	return u.ShopRepository.UpdateShopCocktailAvailability(ctx, shopID, cocktailID, params)
+}
+
 func (u *shopUseCase) GetShopCocktailDetail(ctx context.Context, shopID int64, cocktailID int64) (model.CocktailDetail, error) {
-	return u.ShopRepository.GetShopCocktailDetail(ctx, shopID, cocktailID)
+	d, err := u.ShopRepository.GetShopCocktailDetail(ctx, shopID, cocktailID)
+	if err != nil {
+		return model.CocktailDetail{}, err
+	}
+
+	strength.Fill(&d)
+	return *fillDetailImages(&d), nil
 }
 
 func (u *shopUseCase) GetUnprovidedOrderList(ctx context.Context, shopID int64, limit int64, offset int64) ([]*model.TableOrder, error) {
@@ -69,11 +129,109 @@ func (u *shopUseCase) GetTableOrderList(ctx context.Context, shopID int64, table
 	return u.ShopRepository.GetTableOrderList(ctx, shopID, tableID, unprovided)
 }
 
-func (u *shopUseCase) Order(ctx context.Context, shopID int64, tableID int64, params model.OrderParams) ([]*model.Order, error) {
-	return u.ShopRepository.Order(ctx, shopID, tableID, params)
+func (u *shopUseCase) GetBill(ctx context.Context, shopID int64, tableID int64) (*model.Bill, error) {
+	shop, err := u.ShopRepository.GetByID(ctx, shopID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = u.ShopRepository.GetTable(ctx, shopID, tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := u.ShopRepository.GetTableBillOrders(ctx, shopID, tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	var subtotal int64
+	for _, o := range orders {
+		subtotal += o.Price
+	}
+
+	// fractions of tax are rounded down
+	tax := subtotal * shop.TaxRate / 100
+
+	return &model.Bill{
+		ShopID:   shopID,
+		TableID:  tableID,
+		Orders:   orders,
+		Subtotal: subtotal,
+		TaxRate:  shop.TaxRate,
+		Tax:      tax,
+		Total:    subtotal + tax,
+	}, nil
+}
 
+func (u *shopUseCase) Order(ctx context.Context, shopID int64, tableID int64, params model.OrderParams) ([]*model.Order, error) {
+	if err := validate.Struct(params); err != nil {
+		return nil, err
+	}
+	orders, err := u.ShopRepository.Order(ctx, shopID, tableID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range orders {
+		u.hub.Publish(model.OrderEvent{Type: model.OrderEventCreated, ShopID: shopID, Order: *o})
+	}
+
+	return orders, nil
 }
 
 func (u *shopUseCase) OrderProvide(ctx context.Context, shopID int64, tableID int64, orderID int64) error {
-	return u.ShopRepository.OrderProvide(ctx, shopID, tableID, orderID)
+	_, err := u.TransitionOrder(ctx, shopID, tableID, orderID, model.OrderStatusServed)
+	return err
+}
+
+func (u *shopUseCase) TransitionOrder(ctx context.Context, shopID int64, tableID int64, orderID int64, status model.OrderStatus) (*model.Order, error) {
+	var o *model.Order
+	now := time.Now().Unix()
+
+	err := u.uow.Do(ctx, func(repos repository.Repositories) error {
+		var err error
+		o, err = repos.Shop.GetOrder(ctx, shopID, tableID, orderID)
+		if err != nil {
+			return err
+		}
+
+		if !o.Status.CanTransitionTo(status) {
+			return fmt.Errorf("%w. %s -> %s", repository.ErrInvalidOrderTransition, o.Status, status)
+		}
+
+		if err := repos.Shop.UpdateOrderStatus(ctx, orderID, o.Status, status, now); err != nil {
+			return err
+		}
+
+		// a cancelled order gives back the stock it took, within the same unit of work as the status
+		if status == model.OrderStatusCancelled {
+			return repos.Shop.RestoreInventory(ctx, shopID, o.ID, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// published after the commit, since the unit of work may run more than once
+	o.SetStatus(status, now)
+	u.hub.Publish(model.OrderEvent{Type: model.OrderEventStatusChanged, ShopID: shopID, Order: *o})
+
+	return o, nil
+}
+
+func (u *shopUseCase) SubscribeOrders(shopID int64) (<-chan model.OrderEvent, func()) {
+	return u.hub.Subscribe(shopID)
+}
+
+func (u *shopUseCase) GetInventory(ctx context.Context, shopID int64) ([]model.InventoryItem, error) {
+	return u.ShopRepository.GetInventory(ctx, shopID)
+}
+
+func (u *shopUseCase) UpdateInventory(ctx context.Context, shopID int64, params model.InventoryParams) ([]model.InventoryItem, error) {
+	if err := validate.Struct(params); err != nil {
+		return nil, err
+	}
+	return u.ShopRepository.UpdateInventory(ctx, shopID, params)
 }
@@ -0,0 +1,334 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/shake551/cocktails-api/domain/errs"
+	"github.com/shake551/cocktails-api/domain/event"
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/domain/repository_mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestShopGetLimit(t *testing.T) {
+	shops := []model.Shop{{ID: 1, Name: "bar", TaxRate: 10}}
+	r := new(repository_mock.ShopRepository)
+	r.On("GetLimit", mock.Anything, int64(20), int64(0)).Return(shops, nil)
+	uc := &shopUseCase{r, event.NewOrderHub(), newUnitOfWork(repository.Repositories{Shop: r})}
+
+	res, err := uc.GetLimit(context.Background(), 20, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, shops, res)
+}
+
+func TestGetShopCocktailList(t *testing.T) {
+	type testcase struct {
+		Name  string
+		Input model.ShopMenuCocktail
+		Want  bool
+	}
+
+	tests := []testcase{
+		{
+			Name:  "available",
+			Input: model.ShopMenuCocktail{ID: 1, InStock: true},
+			Want:  true,
+		},
+		{
+			Name:  "out of stock",
+			Input: model.ShopMenuCocktail{ID: 1, InStock: false},
+			Want:  false,
+		},
+		{
+			Name:  "sold out",
+			Input: model.ShopMenuCocktail{ID: 1, InStock: true, Availability: model.ShopCocktailAvailability{SoldOut: true}},
+			Want:  false,
+		},
+		{
+			Name:  "sold out until future",
+			Input: model.ShopMenuCocktail{ID: 1, InStock: true, Availability: model.ShopCocktailAvailability{SoldOut: true, BackAt: 9999999999}},
+			Want:  false,
+		},
+		{
+			Name:  "back in past",
+			Input: model.ShopMenuCocktail{ID: 1, InStock: true, Availability: model.ShopCocktailAvailability{SoldOut: true, BackAt: 1000000000}},
+			Want:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			r := new(repository_mock.ShopRepository)
+			r.On("GetShopCocktailList", mock.Anything, int64(1), int64(10), int64(0)).Return([]model.ShopMenuCocktail{tc.Input}, nil)
+			uc := &shopUseCase{r, event.NewOrderHub(), newUnitOfWork(repository.Repositories{Shop: r})}
+
+			res, err := uc.GetShopCocktailList(context.Background(), 1, 10, 0)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, res[0].Available)
+		})
+	}
+}
+
+func TestGetShopCocktailListImages(t *testing.T) {
+	r := new(repository_mock.ShopRepository)
+	r.On("GetShopCocktailList", mock.Anything, int64(1), int64(10), int64(0)).Return([]model.ShopMenuCocktail{
+		{ID: 1, ImageURL: "/images/4"},
+		{ID: 2},
+	}, nil)
+	uc := &shopUseCase{r, event.NewOrderHub(), newUnitOfWork(repository.Repositories{Shop: r})}
+
+	res, err := uc.GetShopCocktailList(context.Background(), 1, 10, 0)
+
+	// the menu list can show the thumbnails rather than the full size photos
+	assert.Nil(t, err)
+	assert.Equal(t, "/images/4/thumbnail.webp", res[0].Images.Thumbnail.WebP)
+	assert.Equal(t, "/images/4/thumbnail.jpg", res[0].Images.Thumbnail.JPEG)
+	assert.Nil(t, res[1].Images)
+}
+
+func TestGetShopCocktailDetailStrength(t *testing.T) {
+	abv := 20.0
+	r := new(repository_mock.ShopRepository)
+	r.On("GetShopCocktailDetail", mock.Anything, int64(1), int64(2)).Return(model.CocktailDetail{
+		ID:     2,
+		Method: model.MethodBuild,
+		Materials: []model.Material{
+			{Name: "カルーア", ABV: &abv, Quantity: model.MaterialQuantity{Quantity: 45, Unit: "ml"}},
+			{Name: "牛乳", Quantity: model.MaterialQuantity{Quantity: 90, Unit: "ml"}},
+		},
+	}, nil)
+	uc := &shopUseCase{r, event.NewOrderHub(), newUnitOfWork(repository.Repositories{Shop: r})}
+
+	res, err := uc.GetShopCocktailDetail(context.Background(), 1, 2)
+
+	// the menu shows how strong the cocktail is, like the cocktail detail does
+	assert.Nil(t, err)
+	assert.Equal(t, 6.1, *res.ABV)
+	assert.Equal(t, 0.71, *res.StandardDrinks)
+}
+
+func TestUpdateShopCocktailAvailability(t *testing.T) {
+	r := new(repository_mock.ShopRepository)
+	want := model.ShopCocktailAvailability{SoldOut: false}
+	r.On("UpdateShopCocktailAvailability", mock.Anything, int64(1), int64(2), want).Return(&want, nil)
+	uc := &shopUseCase{r, event.NewOrderHub(), newUnitOfWork(repository.Repositories{Shop: r})}
+
+	res, err := uc.UpdateShopCocktailAvailability(context.Background(), 1, 2, model.ShopCocktailAvailability{SoldOut: false, BackAt: 1000000000})
+
+	assert.Nil(t, err)
+	assert.Equal(t, &want, res)
+}
+
+func TestGetBill(t *testing.T) {
+	type testcase struct {
+		Name    string
+		TaxRate int64
+		Orders  []model.BillOrder
+		Want    *model.Bill
+	}
+
+	orders := []model.BillOrder{
+		{OrderID: 1, CocktailID: 1, Name: "スコッチ・オーレ", Price: 800},
+		{OrderID: 2, CocktailID: 1, Name: "スコッチ・オーレ", Price: 800},
+		{OrderID: 3, CocktailID: 2, Name: "カルーア・ミルク", Price: 655},
+	}
+
+	tests := []testcase{
+		{
+			Name:    "standard tax",
+			TaxRate: 10,
+			Orders:  orders,
+			Want: &model.Bill{
+				ShopID:   1,
+				TableID:  2,
+				Orders:   orders,
+				Subtotal: 2255,
+				TaxRate:  10,
+				Tax:      225,
+				Total:    2480,
+			},
+		},
+		{
+			Name:    "no orders",
+			TaxRate: 8,
+			Orders:  []model.BillOrder{},
+			Want: &model.Bill{
+				ShopID:  1,
+				TableID: 2,
+				Orders:  []model.BillOrder{},
+				TaxRate: 8,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			r := new(repository_mock.ShopRepository)
+			r.On("GetByID", mock.Anything, int64(1)).Return(model.Shop{ID: 1, Name: "shake", TaxRate: tc.TaxRate}, nil)
+			r.On("GetTable", mock.Anything, int64(1), int64(2)).Return(&model.Table{ID: 2, ShopID: 1}, nil)
+			r.On("GetTableBillOrders", mock.Anything, int64(1), int64(2)).Return(tc.Orders, nil)
+			uc := &shopUseCase{r, event.NewOrderHub(), newUnitOfWork(repository.Repositories{Shop: r})}
+
+			res, err := uc.GetBill(context.Background(), 1, 2)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, res)
+		})
+	}
+}
+
+func TestGetBillTableNotFound(t *testing.T) {
+	r := new(repository_mock.ShopRepository)
+	r.On("GetByID", mock.Anything, int64(1)).Return(model.Shop{ID: 1, TaxRate: 10}, nil)
+	r.On("GetTable", mock.Anything, int64(1), int64(3)).Return(&model.Table{}, repository.ErrTableNotFound)
+	uc := &shopUseCase{r, event.NewOrderHub(), newUnitOfWork(repository.Repositories{Shop: r})}
+
+	_, err := uc.GetBill(context.Background(), 1, 3)
+
+	assert.ErrorIs(t, err, repository.ErrTableNotFound)
+}
+
+func TestTransitionOrder(t *testing.T) {
+	type testcase struct {
+		Name    string
+		From    model.OrderStatus
+		To      model.OrderStatus
+		WantErr error
+	}
+
+	tests := []testcase{
+		{Name: "accepted to preparing", From: model.OrderStatusAccepted, To: model.OrderStatusPreparing},
+		{Name: "preparing to ready", From: model.OrderStatusPreparing, To: model.OrderStatusReady},
+		{Name: "ready to served", From: model.OrderStatusReady, To: model.OrderStatusServed},
+		{Name: "accepted to served", From: model.OrderStatusAccepted, To: model.OrderStatusServed},
+		{Name: "cancel ready order", From: model.OrderStatusReady, To: model.OrderStatusCancelled},
+		{Name: "back to preparing", From: model.OrderStatusReady, To: model.OrderStatusPreparing, WantErr: repository.ErrInvalidOrderTransition},
+		{Name: "cancel served order", From: model.OrderStatusServed, To: model.OrderStatusCancelled, WantErr: repository.ErrInvalidOrderTransition},
+		{Name: "serve cancelled order", From: model.OrderStatusCancelled, To: model.OrderStatusServed, WantErr: repository.ErrInvalidOrderTransition},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			r := new(repository_mock.ShopRepository)
+			r.On("GetOrder", mock.Anything, int64(1), int64(2), int64(3)).Return(&model.Order{ID: 3, TableID: 2, ShopCocktailID: 4, Status: tc.From}, nil)
+			r.On("UpdateOrderStatus", mock.Anything, int64(3), tc.From, tc.To, mock.Anything).Return(nil)
+			r.On("RestoreInventory", mock.Anything, int64(1), int64(3), mock.Anything).Return(nil)
+			uc := &shopUseCase{r, event.NewOrderHub(), newUnitOfWork(repository.Repositories{Shop: r})}
+
+			res, err := uc.TransitionOrder(context.Background(), 1, 2, 3, tc.To)
+
+			assert.ErrorIs(t, err, tc.WantErr)
+			if tc.WantErr != nil {
+				r.AssertNotCalled(t, "UpdateOrderStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				r.AssertNotCalled(t, "RestoreInventory", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			if tc.To == model.OrderStatusCancelled {
+				r.AssertCalled(t, "RestoreInventory", mock.Anything, int64(1), int64(3), res.CancelledAt)
+			} else {
+				r.AssertNotCalled(t, "RestoreInventory", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+			assert.Equal(t, tc.To, res.Status)
+			assert.Equal(t, res.UpdatedAt, map[model.OrderStatus]int64{
+				model.OrderStatusPreparing: res.PreparingAt,
+				model.OrderStatusReady:     res.ReadyAt,
+				model.OrderStatusServed:    res.ServedAt,
+				model.OrderStatusCancelled: res.CancelledAt,
+			}[tc.To])
+		})
+	}
+}
+
+func TestOrderPublishesEvents(t *testing.T) {
+	hub := event.NewOrderHub()
+	events, unsubscribe := hub.Subscribe(1)
+	defer unsubscribe()
+
+	orders := []*model.Order{
+		{ID: 1, TableID: 2, ShopCocktailID: 3, Status: model.OrderStatusAccepted},
+		{ID: 2, TableID: 2, ShopCocktailID: 4, Status: model.OrderStatusAccepted},
+	}
+	params := model.OrderParams{CocktailIDs: []int64{3, 4}}
+
+	r := new(repository_mock.ShopRepository)
+	r.On("Order", mock.Anything, int64(1), int64(2), params).Return(orders, nil)
+	uc := &shopUseCase{r, hub, newUnitOfWork(repository.Repositories{Shop: r})}
+
+	_, err := uc.Order(context.Background(), 1, 2, params)
+
+	assert.Nil(t, err)
+	for _, o := range orders {
+		assert.Equal(t, model.OrderEvent{Type: model.OrderEventCreated, ShopID: 1, Order: *o}, <-events)
+	}
+}
+
+func TestOrderStockErrors(t *testing.T) {
+	type testcase struct {
+		Name string
+		Err  error
+	}
+
+	tests := []testcase{
+		{Name: "out of stock", Err: repository.ErrOutOfStock},
+		{Name: "unit mismatch", Err: fmt.Errorf("%w. material_id: 2, recipe: ml, stock: 本", repository.ErrInventoryUnitMismatch)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			hub := event.NewOrderHub()
+			events, unsubscribe := hub.Subscribe(1)
+			defer unsubscribe()
+
+			params := model.OrderParams{CocktailIDs: []int64{3}}
+			r := new(repository_mock.ShopRepository)
+			r.On("Order", mock.Anything, int64(1), int64(2), params).Return(nil, tc.Err)
+			uc := &shopUseCase{r, hub, newUnitOfWork(repository.Repositories{Shop: r})}
+
+			orders, err := uc.Order(context.Background(), 1, 2, params)
+
+			// the order is refused as a conflict the staff can act on, and nobody is told it was placed
+			assert.Nil(t, orders)
+			assert.ErrorIs(t, err, tc.Err)
+			assert.Equal(t, errs.KindConflict, errs.KindOf(err))
+			select {
+			case e := <-events:
+				t.Errorf("unexpected event %v", e)
+			default:
+			}
+		})
+	}
+}
+
+func TestOrderInvalidParams(t *testing.T) {
+	r := new(repository_mock.ShopRepository)
+	uc := &shopUseCase{r, event.NewOrderHub(), newUnitOfWork(repository.Repositories{Shop: r})}
+
+	_, err := uc.Order(context.Background(), 1, 2, model.OrderParams{})
+
+	assert.Equal(t, []errs.FieldError{{Field: "cocktail_ids", Message: "must not be empty"}}, errs.FieldsOf(err))
+	r.AssertNotCalled(t, "Order", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransitionOrderPublishesEvent(t *testing.T) {
+	hub := event.NewOrderHub()
+	events, unsubscribe := hub.Subscribe(1)
+	defer unsubscribe()
+
+	r := new(repository_mock.ShopRepository)
+	r.On("GetOrder", mock.Anything, int64(1), int64(2), int64(3)).Return(&model.Order{ID: 3, TableID: 2, Status: model.OrderStatusReady}, nil)
+	r.On("UpdateOrderStatus", mock.Anything, int64(3), model.OrderStatusReady, model.OrderStatusServed, mock.Anything).Return(nil)
+	uc := &shopUseCase{r, hub, newUnitOfWork(repository.Repositories{Shop: r})}
+
+	err := uc.OrderProvide(context.Background(), 1, 2, 3)
+
+	assert.Nil(t, err)
+	e := <-events
+	assert.Equal(t, model.OrderEventStatusChanged, e.Type)
+	assert.Equal(t, model.OrderStatusServed, e.Order.Status)
+}
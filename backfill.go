@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/shake551/cocktails-api/application/usecase"
+)
+
+// runBackfillVariants makes the variants of the stored images which are missing any, like the ones uploaded
+// before variants were made. It is run once after upgrading, as serving an image never makes them.
+func runBackfillVariants(ctx context.Context, storage string) error {
+	repos, done, err := newRepositories(ctx, storage)
+	defer done()
+	if err != nil {
+		return err
+	}
+
+	repos.image, err = newImageRepository(os.Getenv("IMAGE_STORAGE"), repos.image)
+	if err != nil {
+		return err
+	}
+
+	made, err := usecase.NewImageUseCase(repos.image, repos.unitOfWork).MakeMissingVariants(ctx)
+	fmt.Printf("made the variants of %d images\n", made)
+	return err
+}
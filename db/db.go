@@ -1,23 +1,120 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"log"
+	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 )
 
-var DB *sqlx.DB
+// Executor runs statements on a database handle or within a transaction.
+// *sql.DB, *sqlx.DB and *sql.Tx all satisfy it.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type beginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Config is the MySQL connection and its pool settings. Zero values keep the defaults of database/sql.
+type Config struct {
+	DSN             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// Initialize connects to MySQL and returns the handle. The caller closes it.
+func Initialize(cfg Config) (*sqlx.DB, error) {
+	d, err := sqlx.Open("mysql", cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxOpenConns != 0 {
+		d.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns != 0 {
+		d.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime != 0 {
+		d.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+	if cfg.ConnMaxIdleTime != 0 {
+		d.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+
+	if err := d.Ping(); err != nil {
+		d.Close()
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// maxTxAttempts bounds how often InTx runs a transaction which keeps deadlocking.
+const maxTxAttempts = 3
+
+// InTx runs fn in a transaction begun on e, committing when fn succeeds.
+// A transaction failing on a deadlock or a lock wait timeout is rolled back and run again, so fn may run more than once.
+// When e already is a transaction, fn joins it and its owner decides on the commit and the retry.
+func InTx(ctx context.Context, e Executor, fn func(tx Executor) error) error {
+	b, ok := e.(beginner)
+	if !ok {
+		return fn(e)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := runTx(ctx, b, fn)
+		if err == nil || attempt == maxTxAttempts || !IsRetryable(err) {
+			return err
+		}
+
+		log.Printf("retry transaction. attempt: %d, err: %v", attempt, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * 20 * time.Millisecond):
+		}
+	}
+}
 
-func Initialize(dsn string) (func() error, error) {
-	var err error
-	DB, err = sqlx.Open("mysql", dsn)
+func runTx(ctx context.Context, b beginner, fn func(tx Executor) error) error {
+	tx, err := b.BeginTx(ctx, nil)
 	if err != nil {
-		return func() error { return nil }, err
+		return err
 	}
-	return DB.Close, DB.Ping()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// IsRetryable reports whether err is a MySQL deadlock or lock wait timeout, after which the whole transaction can run again.
+func IsRetryable(err error) bool {
+	var me *mysql.MySQLError
+	if !errors.As(err, &me) {
+		return false
+	}
+	return me.Number == mysqlErrDeadlock || me.Number == mysqlErrLockWaitTimeout
 }
 
+const (
+	mysqlErrLockWaitTimeout = 1205
+	mysqlErrDeadlock        = 1213
+)
+
 func IsNoRows(err error) bool {
 	return err == sql.ErrNoRows
 }
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	d, err := sql.Open("sqlite", ":memory:")
+	assert.Nil(t, err)
+	d.SetMaxOpenConns(1)
+	t.Cleanup(func() { d.Close() })
+
+	_, err = d.Exec(`CREATE TABLE counts (n INTEGER NOT NULL)`)
+	assert.Nil(t, err)
+	return d
+}
+
+func count(t *testing.T, d *sql.DB) int {
+	var n int
+	assert.Nil(t, d.QueryRow(`SELECT COUNT(*) FROM counts`).Scan(&n))
+	return n
+}
+
+func TestInTxRetriesDeadlock(t *testing.T) {
+	d := openDB(t)
+	attempts := 0
+
+	err := InTx(context.Background(), d, func(tx Executor) error {
+		attempts++
+		if _, err := tx.ExecContext(context.Background(), `INSERT INTO counts (n) VALUES (?)`, attempts); err != nil {
+			return err
+		}
+		if attempts == 1 {
+			return &mysql.MySQLError{Number: mysqlErrDeadlock, Message: "Deadlock found when trying to get lock"}
+		}
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, 1, count(t, d))
+}
+
+func TestInTxGivesUp(t *testing.T) {
+	type testcase struct {
+		Name     string
+		Err      error
+		Attempts int
+	}
+
+	tests := []testcase{
+		{Name: "lock wait timeout", Err: &mysql.MySQLError{Number: mysqlErrLockWaitTimeout}, Attempts: maxTxAttempts},
+		{Name: "not retryable", Err: errors.New("boom"), Attempts: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			d := openDB(t)
+			attempts := 0
+
+			err := InTx(context.Background(), d, func(tx Executor) error {
+				attempts++
+				if _, err := tx.ExecContext(context.Background(), `INSERT INTO counts (n) VALUES (?)`, attempts); err != nil {
+					return err
+				}
+				return tc.Err
+			})
+
+			assert.ErrorIs(t, err, tc.Err)
+			assert.Equal(t, tc.Attempts, attempts)
+			assert.Equal(t, 0, count(t, d))
+		})
+	}
+}
+
+func TestInTxJoinsTransaction(t *testing.T) {
+	d := openDB(t)
+	tx, err := d.Begin()
+	assert.Nil(t, err)
+
+	attempts := 0
+	err = InTx(context.Background(), tx, func(joined Executor) error {
+		attempts++
+		assert.Equal(t, tx, joined)
+		return &mysql.MySQLError{Number: mysqlErrDeadlock}
+	})
+
+	assert.True(t, IsRetryable(err))
+	assert.Equal(t, 1, attempts)
+	assert.Nil(t, tx.Rollback())
+}
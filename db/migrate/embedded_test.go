@@ -0,0 +1,20 @@
+package migrate_test
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/shake551/cocktails-api/db/migrate"
+	"github.com/shake551/cocktails-api/db/migrations"
+	sqlitemigrations "github.com/shake551/cocktails-api/infrastructure/parsistence/sqlite/migrations"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	for _, fsys := range []fs.FS{migrations.FS, sqlitemigrations.FS} {
+		loaded, err := migrate.Load(fsys)
+
+		assert.Nil(t, err)
+		assert.NotEmpty(t, loaded)
+	}
+}
@@ -0,0 +1,313 @@
+// Package migrate applies the numbered schema migrations of a storage backend and records them in schema_migrations.
+//
+// A migration is a pair of files named like 0001_create_tables.up.sql and 0001_create_tables.down.sql.
+// Statements in a file are separated by a semicolon at the end of a line, and lines starting with -- are comments.
+// Data SQL cannot compute, like normalized names, is filled in by Go steps. MySQL commits every DDL statement at once,
+// so a migration with steps has no SQL of its own: the columns they fill in are added by the migration before, and the
+// steps run in one transaction with the record of their migration, so a failing step can simply run again.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shake551/cocktails-api/db"
+)
+
+var (
+	// ErrUnknownVersion means the database has a migration applied which this binary does not know,
+	// typically because a newer release migrated it.
+	ErrUnknownVersion = errors.New("database schema has an unknown version")
+	// ErrPendingMigrations means the database is behind the migrations of this binary.
+	ErrPendingMigrations = errors.New("database schema has pending migrations")
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration and when it was applied. AppliedAt is 0 for a pending migration.
+type Status struct {
+	Migration
+	AppliedAt int64
+}
+
+// Step is Go code run as the migration with its version, which must have no up SQL.
+// The steps of a migration commit along with its record in schema_migrations, or not at all.
+type Step struct {
+	Version int64
+	Up      func(ctx context.Context, tx db.Executor) error
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	steps      map[int64][]Step
+}
+
+var (
+	fileName     = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	statementEnd = regexp.MustCompile(`;[ \t\r]*(\n|$)`)
+	commentLine  = regexp.MustCompile(`(?m)^[ \t]*--.*$`)
+)
+
+// Load reads the migrations in the root of fsys, sorted by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, f := range files {
+		match := fileName.FindStringSubmatch(path.Base(f))
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", f)
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version: %s", f)
+		}
+
+		body, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	var migrations []Migration
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// New returns a Migrator applying the migrations in fsys to db, along with their steps.
+func New(db *sql.DB, fsys fs.FS, steps ...Step) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[int64]Migration{}
+	for _, mig := range migrations {
+		known[mig.Version] = mig
+	}
+
+	byVersion := map[int64][]Step{}
+	for _, s := range steps {
+		mig, ok := known[s.Version]
+		if !ok {
+			return nil, fmt.Errorf("step of an unknown migration: %d", s.Version)
+		}
+		if len(statements(mig.Up)) > 0 {
+			return nil, fmt.Errorf("migration %d_%s has both SQL and steps, which cannot commit together", mig.Version, mig.Name)
+		}
+		byVersion[s.Version] = append(byVersion[s.Version], s)
+	}
+
+	return &Migrator{db: db, migrations: migrations, steps: byVersion}, nil
+}
+
+// Up applies every pending migration in order and returns the applied ones.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkKnown(applied); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+
+		if err := m.exec(ctx, mig.Up); err != nil {
+			return done, fmt.Errorf("migration %d_%s up: %w", mig.Version, mig.Name, err)
+		}
+		err := db.InTx(ctx, m.db, func(tx db.Executor) error {
+			for _, s := range m.steps[mig.Version] {
+				if err := s.Up(ctx, tx); err != nil {
+					return fmt.Errorf("migration %d_%s step: %w", mig.Version, mig.Name, err)
+				}
+			}
+			_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`, mig.Version, mig.Name, time.Now().Unix())
+			return err
+		})
+		if err != nil {
+			return done, err
+		}
+
+		done = append(done, mig)
+	}
+
+	return done, nil
+}
+
+// Down reverts the latest applied migration. It returns nil when nothing is applied.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkKnown(applied); err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+
+		if err := m.exec(ctx, mig.Down); err != nil {
+			return nil, fmt.Errorf("migration %d_%s down: %w", mig.Version, mig.Name, err)
+		}
+		if _, err := m.db.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, mig.Version); err != nil {
+			return nil, err
+		}
+
+		return &mig, nil
+	}
+
+	return nil, nil
+}
+
+// Status lists every known migration with when it was applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkKnown(applied); err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	for _, mig := range m.migrations {
+		statuses = append(statuses, Status{Migration: mig, AppliedAt: applied[mig.Version]})
+	}
+
+	return statuses, nil
+}
+
+// Check reports whether the database is exactly at the schema of this binary.
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range statuses {
+		if s.AppliedAt == 0 {
+			return fmt.Errorf("%w. first pending: %d_%s", ErrPendingMigrations, s.Version, s.Name)
+		}
+	}
+
+	return nil
+}
+
+// applied returns when each applied version was applied.
+func (m *Migrator) applied(ctx context.Context) (map[int64]int64, error) {
+	q := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT NOT NULL PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at BIGINT NOT NULL
+	)`
+	if _, err := m.db.ExecContext(ctx, q); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]int64{}
+	for rows.Next() {
+		var version, appliedAt int64
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+func (m *Migrator) checkKnown(applied map[int64]int64) error {
+	known := map[int64]bool{}
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+	}
+
+	var unknown []string
+	for version := range applied {
+		if !known[version] {
+			unknown = append(unknown, strconv.FormatInt(version, 10))
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("%w. versions: %s", ErrUnknownVersion, strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+// exec runs the statements of a migration one by one, since drivers differ in running several at once.
+func (m *Migrator) exec(ctx context.Context, script string) error {
+	for _, stmt := range statements(script) {
+		if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func statements(script string) []string {
+	var stmts []string
+	for _, s := range statementEnd.Split(commentLine.ReplaceAllString(script, ""), -1) {
+		if s = strings.TrimSpace(s); s != "" {
+			stmts = append(stmts, s)
+		}
+	}
+	return stmts
+}
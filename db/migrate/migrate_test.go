@@ -0,0 +1,157 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/shake551/cocktails-api/db"
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_create_cocktails.up.sql":   {Data: []byte("-- cocktails\nCREATE TABLE cocktails (id INTEGER PRIMARY KEY);\n")},
+		"0001_create_cocktails.down.sql": {Data: []byte("DROP TABLE cocktails;\n")},
+		"0002_add_name.up.sql":           {Data: []byte("ALTER TABLE cocktails ADD COLUMN name TEXT;\nCREATE INDEX cocktails_name ON cocktails (name);\n")},
+		"0002_add_name.down.sql":         {Data: []byte("DROP INDEX cocktails_name;\nALTER TABLE cocktails DROP COLUMN name;\n")},
+	}
+}
+
+func openDB(t *testing.T) *sql.DB {
+	d, err := sql.Open("sqlite", ":memory:")
+	assert.Nil(t, err)
+	d.SetMaxOpenConns(1)
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.Background()
+	m, err := New(openDB(t), testFS())
+	assert.Nil(t, err)
+
+	assert.ErrorIs(t, m.Check(ctx), ErrPendingMigrations)
+
+	applied, err := m.Up(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, applied, 2)
+	assert.Nil(t, m.Check(ctx))
+
+	applied, err = m.Up(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, applied)
+
+	reverted, err := m.Down(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), reverted.Version)
+
+	statuses, err := m.Status(ctx)
+	assert.Nil(t, err)
+	assert.NotZero(t, statuses[0].AppliedAt)
+	assert.Zero(t, statuses[1].AppliedAt)
+
+	_, err = m.Down(ctx)
+	assert.Nil(t, err)
+	reverted, err = m.Down(ctx)
+	assert.Nil(t, err)
+	assert.Nil(t, reverted)
+}
+
+func TestUnknownVersion(t *testing.T) {
+	ctx := context.Background()
+	d := openDB(t)
+	newer, err := New(d, testFS())
+	assert.Nil(t, err)
+	_, err = newer.Up(ctx)
+	assert.Nil(t, err)
+
+	fsys := testFS()
+	delete(fsys, "0002_add_name.up.sql")
+	delete(fsys, "0002_add_name.down.sql")
+	older, err := New(d, fsys)
+	assert.Nil(t, err)
+
+	assert.ErrorIs(t, older.Check(ctx), ErrUnknownVersion)
+	_, err = older.Up(ctx)
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	type testcase struct {
+		Name string
+		FS   fstest.MapFS
+	}
+
+	tests := []testcase{
+		{Name: "bad name", FS: fstest.MapFS{"create.sql": {Data: []byte("SELECT 1;")}}},
+		{Name: "missing down", FS: fstest.MapFS{"0001_create.up.sql": {Data: []byte("SELECT 1;")}}},
+		{Name: "two names", FS: fstest.MapFS{
+			"0001_create.up.sql": {Data: []byte("SELECT 1;")},
+			"0001_drop.down.sql": {Data: []byte("SELECT 1;")},
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := Load(tc.FS)
+
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestStatements(t *testing.T) {
+	script := "-- comment\nCREATE TABLE a (x TEXT DEFAULT ';');\n\nDROP TABLE b;"
+
+	assert.Equal(t, []string{"CREATE TABLE a (x TEXT DEFAULT ';')", "DROP TABLE b"}, statements(script))
+}
+
+func TestSteps(t *testing.T) {
+	ctx := context.Background()
+	d := openDB(t)
+	fsys := testFS()
+	fsys["0003_fill_names.up.sql"] = &fstest.MapFile{Data: []byte("-- filled in by a step\n")}
+	fsys["0003_fill_names.down.sql"] = &fstest.MapFile{Data: []byte("UPDATE cocktails SET name = NULL;\n")}
+
+	// a failing step leaves its migration pending, with none of what it wrote
+	fail := errors.New("step failed")
+	failing := func(ctx context.Context, tx db.Executor) error {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO cocktails (id, name) VALUES (1, 'ギムレット')`); err != nil {
+			return err
+		}
+		return fail
+	}
+	m, err := New(d, fsys, Step{Version: 3, Up: failing})
+	assert.Nil(t, err)
+	applied, err := m.Up(ctx)
+	assert.ErrorIs(t, err, fail)
+	assert.Len(t, applied, 2)
+	assert.ErrorIs(t, m.Check(ctx), ErrPendingMigrations)
+	var n int
+	assert.Nil(t, d.QueryRowContext(ctx, `SELECT COUNT(*) FROM cocktails`).Scan(&n))
+	assert.Zero(t, n)
+
+	// so running up again only runs the step, after the migration which added the column it fills in
+	fill := func(ctx context.Context, tx db.Executor) error {
+		_, err := tx.ExecContext(ctx, `INSERT INTO cocktails (id, name) VALUES (1, 'ギムレット')`)
+		return err
+	}
+	m, err = New(d, fsys, Step{Version: 3, Up: fill})
+	assert.Nil(t, err)
+	applied, err = m.Up(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, applied, 1)
+	assert.Nil(t, m.Check(ctx))
+	assert.Nil(t, d.QueryRowContext(ctx, `SELECT COUNT(*) FROM cocktails`).Scan(&n))
+	assert.Equal(t, 1, n)
+
+	_, err = New(d, fsys, Step{Version: 4, Up: fill})
+	assert.NotNil(t, err)
+	// a migration with SQL could not commit its steps along with it
+	_, err = New(d, fsys, Step{Version: 2, Up: fill})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS shop_orders;
+DROP TABLE IF EXISTS shop_tables;
+DROP TABLE IF EXISTS shop_cocktails;
+DROP TABLE IF EXISTS shops;
+DROP TABLE IF EXISTS cocktail_material_images;
+DROP TABLE IF EXISTS cocktail_materials;
+DROP TABLE IF EXISTS materials;
+DROP TABLE IF EXISTS cocktails;
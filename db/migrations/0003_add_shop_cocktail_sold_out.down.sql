@@ -0,0 +1 @@
+ALTER TABLE shop_cocktails DROP COLUMN back_at, DROP COLUMN sold_out;
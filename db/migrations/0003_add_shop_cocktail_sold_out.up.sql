@@ -0,0 +1 @@
+ALTER TABLE shop_cocktails ADD COLUMN sold_out bool NOT NULL DEFAULT false, ADD COLUMN back_at INTEGER;
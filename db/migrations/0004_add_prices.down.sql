@@ -0,0 +1,3 @@
+ALTER TABLE shop_orders DROP COLUMN price;
+ALTER TABLE shop_cocktails DROP COLUMN price;
+ALTER TABLE shops DROP COLUMN tax_rate;
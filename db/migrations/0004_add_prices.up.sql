@@ -0,0 +1,3 @@
+ALTER TABLE shops ADD COLUMN tax_rate INTEGER NOT NULL DEFAULT 10;
+ALTER TABLE shop_cocktails ADD COLUMN price INTEGER NOT NULL DEFAULT 0 AFTER cocktail_id;
+ALTER TABLE shop_orders ADD COLUMN price INTEGER NOT NULL DEFAULT 0 AFTER shop_cocktail_id;
@@ -0,0 +1,10 @@
+ALTER TABLE shop_orders ADD COLUMN is_provided bool DEFAULT false AFTER price;
+-- only served orders count as provided; the other states have no equivalent
+UPDATE shop_orders SET is_provided = true WHERE status = 'served';
+ALTER TABLE shop_orders
+    DROP COLUMN cancelled_at,
+    DROP COLUMN served_at,
+    DROP COLUMN ready_at,
+    DROP COLUMN preparing_at,
+    DROP COLUMN accepted_at,
+    DROP COLUMN status;
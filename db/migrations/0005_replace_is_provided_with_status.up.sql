@@ -0,0 +1,11 @@
+ALTER TABLE shop_orders
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'accepted' AFTER price,
+    ADD COLUMN accepted_at INTEGER AFTER status,
+    ADD COLUMN preparing_at INTEGER AFTER accepted_at,
+    ADD COLUMN ready_at INTEGER AFTER preparing_at,
+    ADD COLUMN served_at INTEGER AFTER ready_at,
+    ADD COLUMN cancelled_at INTEGER AFTER served_at;
+-- every existing order was accepted when placed, and a provided one was served when it was last updated
+UPDATE shop_orders SET accepted_at = created_at;
+UPDATE shop_orders SET status = 'served', served_at = updated_at WHERE is_provided;
+ALTER TABLE shop_orders DROP COLUMN is_provided;
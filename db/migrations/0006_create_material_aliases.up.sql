@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS material_aliases (
+    id INTEGER UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+    material_id INTEGER NOT NULL,
+    name VARCHAR(128) NOT NULL,
+    created_at INTEGER NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1 @@
+ALTER TABLE materials ADD COLUMN abv DOUBLE AFTER name;
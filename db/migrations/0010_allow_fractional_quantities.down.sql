@@ -0,0 +1,2 @@
+ALTER TABLE shop_inventories MODIFY quantity INTEGER NOT NULL;
+ALTER TABLE cocktail_materials MODIFY quantity INTEGER;
@@ -0,0 +1,2 @@
+ALTER TABLE cocktail_materials MODIFY quantity DOUBLE;
+ALTER TABLE shop_inventories MODIFY quantity DOUBLE NOT NULL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS cocktail_material_image_variants;
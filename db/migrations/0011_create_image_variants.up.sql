@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS cocktail_material_image_variants (
+    image_id INTEGER UNSIGNED NOT NULL,
+    name VARCHAR(32) NOT NULL,
+    data LONGTEXT NOT NULL,
+    PRIMARY KEY (image_id, name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,2 @@
+ALTER TABLE material_aliases DROP COLUMN name_key;
+ALTER TABLE materials DROP COLUMN name_key;
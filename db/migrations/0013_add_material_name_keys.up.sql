@@ -0,0 +1,5 @@
+-- the keys are normalized names, which MySQL cannot compute, so 0014_fill_material_name_keys fills them in
+ALTER TABLE materials ADD COLUMN name_key VARCHAR(255) COLLATE utf8mb4_bin AFTER name;
+ALTER TABLE materials ADD UNIQUE INDEX materials_name_key (name_key);
+ALTER TABLE material_aliases ADD COLUMN name_key VARCHAR(255) COLLATE utf8mb4_bin AFTER name;
+ALTER TABLE material_aliases ADD UNIQUE INDEX material_aliases_name_key (name_key);
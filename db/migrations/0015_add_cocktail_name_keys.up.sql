@@ -0,0 +1,3 @@
+-- the keys are normalized names, which MySQL cannot compute, so 0016_fill_cocktail_name_keys fills them in
+ALTER TABLE cocktails ADD COLUMN name_key VARCHAR(255) COLLATE utf8mb4_bin NOT NULL DEFAULT '' AFTER reading;
+ALTER TABLE cocktails ADD COLUMN reading_key VARCHAR(255) COLLATE utf8mb4_bin NOT NULL DEFAULT '' AFTER name_key;
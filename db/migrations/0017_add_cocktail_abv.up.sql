@@ -0,0 +1,2 @@
+-- the estimated strength needs the recipe weighed in Go, so 0018_fill_cocktail_abv fills it in
+ALTER TABLE cocktails ADD COLUMN abv DOUBLE AFTER reading_key;
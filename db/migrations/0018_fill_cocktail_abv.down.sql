@@ -0,0 +1 @@
+UPDATE cocktails SET abv = NULL;
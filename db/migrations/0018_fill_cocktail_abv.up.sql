@@ -0,0 +1 @@
+-- the estimated strength needs the recipe weighed in Go, so the step of this migration fills it in
@@ -0,0 +1,9 @@
+-- what each order took from the shop stock, in the unit the stock was kept in, so cancelling it gives back exactly that.
+-- orders placed before this have no rows and give nothing back.
+CREATE TABLE IF NOT EXISTS shop_order_deductions (
+    order_id INTEGER UNSIGNED NOT NULL,
+    material_id INTEGER NOT NULL,
+    quantity DOUBLE NOT NULL,
+    unit VARCHAR(128) NOT NULL,
+    INDEX shop_order_deductions_order_id (order_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
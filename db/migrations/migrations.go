@@ -0,0 +1,7 @@
+// Package migrations holds the MySQL schema migrations applied by db/migrate.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,206 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/shake551/cocktails-api/db"
+	"github.com/shake551/cocktails-api/db/migrate"
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/normalize"
+	"github.com/shake551/cocktails-api/domain/strength"
+)
+
+// Steps fill in what the migrations cannot compute in SQL.
+// Their statements are plain enough to run on SQLite as well.
+var Steps = []migrate.Step{
+	{Version: 14, Up: fillMaterialNameKeys},
+	{Version: 16, Up: fillCocktailNameKeys},
+	{Version: 18, Up: fillCocktailABV},
+}
+
+type namedRow struct {
+	id         int64
+	materialID int64
+	name       string
+	key        sql.NullString
+}
+
+// fillMaterialNameKeys stores the normalized names materials and their aliases are matched by.
+// Names normalizing like the one of another material keep no key, since the keys are unique,
+// and are logged to be merged into that material.
+func fillMaterialNameKeys(ctx context.Context, d db.Executor) error {
+	materials, err := namedRows(ctx, d, `SELECT id, id, name, name_key FROM materials ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	aliases, err := namedRows(ctx, d, `SELECT id, material_id, name, name_key FROM material_aliases ORDER BY id`)
+	if err != nil {
+		return err
+	}
+
+	// owners maps the keys of either table to their material, while a material may have an alias
+	// normalizing like its own name
+	owners := map[string]int64{}
+	aliasKeys := map[string]bool{}
+	for _, m := range materials {
+		if m.key.Valid {
+			owners[m.key.String] = m.materialID
+		}
+	}
+	for _, a := range aliases {
+		if a.key.Valid {
+			owners[a.key.String] = a.materialID
+			aliasKeys[a.key.String] = true
+		}
+	}
+
+	for _, m := range materials {
+		if m.key.Valid {
+			continue
+		}
+		key := normalize.Name(m.name)
+		if owner, ok := owners[key]; ok && owner != m.materialID {
+			log.Printf("material %d %q has the name of material %d. merge it to match it by name", m.id, m.name, owner)
+			continue
+		}
+		if _, err := d.ExecContext(ctx, `UPDATE materials SET name_key = ? WHERE id = ?`, key, m.id); err != nil {
+			return err
+		}
+		owners[key] = m.materialID
+	}
+
+	for _, a := range aliases {
+		if a.key.Valid {
+			continue
+		}
+		key := normalize.Name(a.name)
+		if owner, ok := owners[key]; (ok && owner != a.materialID) || aliasKeys[key] {
+			log.Printf("alias %d %q of material %d is already a name of material %d", a.id, a.name, a.materialID, owner)
+			continue
+		}
+		if _, err := d.ExecContext(ctx, `UPDATE material_aliases SET name_key = ? WHERE id = ?`, key, a.id); err != nil {
+			return err
+		}
+		owners[key] = a.materialID
+		aliasKeys[key] = true
+	}
+
+	return nil
+}
+
+// fillCocktailNameKeys stores the normalized names and readings keywords are matched against.
+func fillCocktailNameKeys(ctx context.Context, d db.Executor) error {
+	rows, err := d.QueryContext(ctx, `SELECT id, name, reading FROM cocktails ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type cocktailRow struct {
+		id      int64
+		name    string
+		reading string
+	}
+	var cocktails []cocktailRow
+	for rows.Next() {
+		var c cocktailRow
+		if err := rows.Scan(&c.id, &c.name, &c.reading); err != nil {
+			return err
+		}
+		cocktails = append(cocktails, c)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, c := range cocktails {
+		_, err := d.ExecContext(ctx, `UPDATE cocktails SET name_key = ?, reading_key = ? WHERE id = ?`, normalize.Name(c.name), normalize.Name(c.reading), c.id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fillCocktailABV stores the estimated strength of the cocktails, which max_abv filters on.
+func fillCocktailABV(ctx context.Context, d db.Executor) error {
+	q := `
+		SELECT
+			cocktail_materials.cocktail_id,
+			cocktail_recipes.method,
+			materials.abv,
+			cocktail_materials.quantity,
+			cocktail_materials.unit
+		FROM cocktail_materials
+		INNER JOIN materials
+			ON cocktail_materials.material_id = materials.id
+		LEFT JOIN cocktail_recipes
+			ON cocktail_materials.cocktail_id = cocktail_recipes.cocktail_id
+		ORDER BY cocktail_materials.cocktail_id
+	`
+
+	rows, err := d.QueryContext(ctx, q)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	methods := map[int64]model.Method{}
+	recipes := map[int64][]model.Material{}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var method sql.NullString
+		var quantity sql.NullFloat64
+		var unit sql.NullString
+		m := model.Material{}
+		if err := rows.Scan(&id, &method, &m.ABV, &quantity, &unit); err != nil {
+			return err
+		}
+		if _, ok := recipes[id]; !ok {
+			ids = append(ids, id)
+		}
+		m.Quantity = model.MaterialQuantity{Quantity: quantity.Float64, Unit: unit.String}
+		methods[id] = model.Method(method.String)
+		recipes[id] = append(recipes[id], m)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		s, ok := strength.Of(methods[id], recipes[id])
+		if !ok {
+			continue
+		}
+		if _, err := d.ExecContext(ctx, `UPDATE cocktails SET abv = ? WHERE id = ?`, s.ABV, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func namedRows(ctx context.Context, d db.Executor, query string) ([]namedRow, error) {
+	rows, err := d.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var named []namedRow
+	for rows.Next() {
+		var r namedRow
+		if err := rows.Scan(&r.id, &r.materialID, &r.name, &r.key); err != nil {
+			return nil, err
+		}
+		named = append(named, r)
+	}
+
+	return named, rows.Err()
+}
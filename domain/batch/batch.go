@@ -0,0 +1,69 @@
+// Package batch scales recipes to be made for many servings at once, for punches and pre-batched
+// cocktails served at events.
+package batch
+
+import (
+	"math"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/strength"
+	"github.com/shake551/cocktails-api/domain/units"
+)
+
+// Of scales the recipe to the servings and renders it in the system, or in the units it was written in
+// when system is empty, rounded to amounts that can be measured out behind a bar.
+// A stirred or shaken batch is kept in the fridge and poured without ice, so the water mixing would have melted
+// is added to it up front. It is estimated from the strength of the materials, or taken as a typical share of
+// their volume when none of them has an ABV.
+func Of(d model.CocktailDetail, servings int64, system units.System) model.CocktailBatch {
+	b := model.CocktailBatch{
+		Cocktail: model.Cocktail{
+			ID:        d.ID,
+			Name:      d.Name,
+			Reading:   d.Reading,
+			ImageURL:  d.ImageURL,
+			Images:    d.Images,
+			CreatedAt: d.CreatedAt,
+			UpdatedAt: d.UpdatedAt,
+		},
+		Servings:  servings,
+		Method:    d.Method,
+		Materials: make([]model.Material, 0, len(d.Materials)),
+	}
+
+	var volume float64
+	for _, m := range d.Materials {
+		q := m.Quantity
+		q.Quantity = math.Round(q.Quantity*float64(servings)*100) / 100
+		if ml, dim, err := units.Canonical(q); err == nil && dim == units.Volume {
+			volume += ml
+		}
+
+		m.Quantity = units.Practical(units.In(q, system), system)
+		b.Materials = append(b.Materials, m)
+	}
+
+	if d.Method == model.MethodStir || d.Method == model.MethodShake {
+		ml, ok := strength.Water(d.Method, d.Materials)
+		if !ok {
+			ml = volume / float64(servings) * strength.DefaultDilution(d.Method)
+		}
+		if ml > 0 {
+			water := measure(ml*float64(servings), system)
+			b.Water = &water
+			volume += ml * float64(servings)
+		}
+	}
+
+	if volume > 0 {
+		v := measure(volume, system)
+		b.Volume = &v
+	}
+	return b
+}
+
+// measure renders ml millilitres in the system, keeping millilitres when it is empty.
+func measure(ml float64, system units.System) model.MaterialQuantity {
+	q := model.MaterialQuantity{Quantity: ml, Unit: units.Millilitre.Symbol}
+	return units.Practical(units.In(q, system), system)
+}
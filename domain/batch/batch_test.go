@@ -0,0 +1,108 @@
+package batch
+
+import (
+	"testing"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/units"
+	"github.com/stretchr/testify/assert"
+)
+
+func abv(v float64) *float64 {
+	return &v
+}
+
+func material(name string, a *float64, quantity float64, unit string) model.Material {
+	return model.Material{Name: name, ABV: a, Quantity: model.MaterialQuantity{Quantity: quantity, Unit: unit}}
+}
+
+func TestOfStirred(t *testing.T) {
+	martini := model.CocktailDetail{
+		ID:     1,
+		Name:   "マティーニ",
+		Method: model.MethodStir,
+		Materials: []model.Material{
+			material("ジン", abv(40), 5, "cl"),
+			material("ドライ・ベルモット", abv(18), 10, "ml"),
+			material("オレンジビターズ", nil, 1, "dash"),
+			material("オリーブ", nil, 1, "個"),
+		},
+	}
+
+	b := Of(martini, 10, "")
+
+	assert.Equal(t, model.Cocktail{ID: 1, Name: "マティーニ"}, b.Cocktail)
+	assert.Equal(t, int64(10), b.Servings)
+	assert.Equal(t, []model.Material{
+		material("ジン", abv(40), 50, "cl"),
+		material("ドライ・ベルモット", abv(18), 100, "ml"),
+		material("オレンジビターズ", nil, 10, "ml"),
+		material("オリーブ", nil, 10, "個"),
+	}, b.Materials)
+	assert.Equal(t, &model.MaterialQuantity{Quantity: 265, Unit: "ml"}, b.Water)
+	assert.Equal(t, &model.MaterialQuantity{Quantity: 875, Unit: "ml"}, b.Volume)
+}
+
+func TestOfStirredWithoutABV(t *testing.T) {
+	// none of the materials has an ABV, so a fifth of the volume is added as water
+	negroni := model.CocktailDetail{
+		Method: model.MethodStir,
+		Materials: []model.Material{
+			material("ジン", nil, 30, "ml"),
+			material("カンパリ", nil, 30, "ml"),
+			material("スイート・ベルモット", nil, 30, "ml"),
+		},
+	}
+
+	b := Of(negroni, 10, "")
+
+	assert.Equal(t, &model.MaterialQuantity{Quantity: 180, Unit: "ml"}, b.Water)
+	assert.Equal(t, &model.MaterialQuantity{Quantity: 1080, Unit: "ml"}, b.Volume)
+}
+
+func TestOfShaken(t *testing.T) {
+	daiquiri := model.CocktailDetail{
+		Method: model.MethodShake,
+		Materials: []model.Material{
+			material("ラム", nil, 45, "ml"),
+			material("ライムジュース", nil, 15, "ml"),
+			material("シュガーシロップ", nil, 1, "tsp"),
+		},
+	}
+
+	b := Of(daiquiri, 4, "")
+	assert.Equal(t, &model.MaterialQuantity{Quantity: 65, Unit: "ml"}, b.Water)
+	assert.Equal(t, &model.MaterialQuantity{Quantity: 325, Unit: "ml"}, b.Volume)
+
+	// with the ABV of the rum the water follows the strength of the mix instead
+	daiquiri.Materials[0].ABV = abv(40)
+	b = Of(daiquiri, 4, "")
+	assert.Equal(t, &model.MaterialQuantity{Quantity: 210, Unit: "ml"}, b.Water)
+	assert.Equal(t, &model.MaterialQuantity{Quantity: 470, Unit: "ml"}, b.Volume)
+}
+
+func TestOfBuiltInImperial(t *testing.T) {
+	kahluaMilk := model.CocktailDetail{
+		Method: model.MethodBuild,
+		Materials: []model.Material{
+			material("カルーア", abv(20), 45, "ml"),
+			material("牛乳", nil, 90, "ml"),
+		},
+	}
+
+	b := Of(kahluaMilk, 4, units.Imperial)
+
+	assert.Equal(t, []model.Material{
+		material("カルーア", abv(20), 6, "oz"),
+		material("牛乳", nil, 12, "oz"),
+	}, b.Materials)
+	assert.Nil(t, b.Water)
+	assert.Equal(t, &model.MaterialQuantity{Quantity: 18, Unit: "oz"}, b.Volume)
+}
+
+func TestOfUnmeasured(t *testing.T) {
+	b := Of(model.CocktailDetail{Materials: []model.Material{material("ミント", nil, 6, "枚")}}, 3, units.Metric)
+
+	assert.Equal(t, []model.Material{material("ミント", nil, 18, "枚")}, b.Materials)
+	assert.Nil(t, b.Volume)
+}
@@ -0,0 +1,82 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Kind string
+
+const (
+	KindInternal    Kind = "internal"
+	KindBadRequest  Kind = "bad_request"
+	KindNotFound    Kind = "not_found"
+	KindConflict    Kind = "conflict"
+	KindValidation  Kind = "validation"
+	KindTooLarge    Kind = "too_large"
+	KindUnsupported Kind = "unsupported"
+)
+
+// Error is an error the client can act on. Any other error is treated as an internal one.
+type Error struct {
+	Kind    Kind
+	Message string
+	Fields  []FieldError
+}
+
+// FieldError points at the request field which failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func BadRequest(format string, args ...interface{}) *Error {
+	return &Error{Kind: KindBadRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+func NotFound(format string, args ...interface{}) *Error {
+	return &Error{Kind: KindNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func Conflict(format string, args ...interface{}) *Error {
+	return &Error{Kind: KindConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func Validation(format string, args ...interface{}) *Error {
+	return &Error{Kind: KindValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+func TooLarge(format string, args ...interface{}) *Error {
+	return &Error{Kind: KindTooLarge, Message: fmt.Sprintf(format, args...)}
+}
+
+func Unsupported(format string, args ...interface{}) *Error {
+	return &Error{Kind: KindUnsupported, Message: fmt.Sprintf(format, args...)}
+}
+
+// InvalidFields is a validation error carrying the fields which failed.
+func InvalidFields(fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Message: "validation failed", Fields: fields}
+}
+
+// KindOf returns the kind of the first *Error in the chain of err.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindInternal
+}
+
+// FieldsOf returns the field errors of the first *Error in the chain of err.
+func FieldsOf(err error) []FieldError {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Fields
+	}
+	return nil
+}
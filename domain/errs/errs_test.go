@@ -0,0 +1,35 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindOf(t *testing.T) {
+	notFound := NotFound("cocktail not found")
+
+	type testcase struct {
+		Name string
+		Err  error
+		Want Kind
+	}
+
+	tests := []testcase{
+		{Name: "typed", Err: notFound, Want: KindNotFound},
+		{Name: "wrapped", Err: fmt.Errorf("%w. cocktail_id: %d", Conflict("cocktail is sold out"), 1), Want: KindConflict},
+		{Name: "validation", Err: Validation("name must not be empty"), Want: KindValidation},
+		{Name: "too large", Err: TooLarge("image must be at most 5MB"), Want: KindTooLarge},
+		{Name: "untyped", Err: errors.New("connection refused"), Want: KindInternal},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Want, KindOf(tc.Err))
+		})
+	}
+
+	assert.ErrorIs(t, fmt.Errorf("%w. id: 1", notFound), notFound)
+}
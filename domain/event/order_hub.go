@@ -0,0 +1,70 @@
+package event
+
+import (
+	"log"
+	"sync"
+
+	"github.com/shake551/cocktails-api/domain/model"
+)
+
+// subscriberBuffer is the number of events a slow subscriber may lag behind before events are dropped for it.
+const subscriberBuffer = 32
+
+// OrderHub is an in-process pub/sub hub delivering order events to the subscribers of each shop.
+type OrderHub struct {
+	mu          sync.RWMutex
+	subscribers map[int64]map[chan model.OrderEvent]struct{}
+}
+
+func NewOrderHub() *OrderHub {
+	return &OrderHub{subscribers: map[int64]map[chan model.OrderEvent]struct{}{}}
+}
+
+// Subscribe registers a subscriber for the orders of the shop.
+// The returned function unsubscribes and closes the channel, and must be called once the subscriber is gone.
+func (h *OrderHub) Subscribe(shopID int64) (<-chan model.OrderEvent, func()) {
+	ch := make(chan model.OrderEvent, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[shopID] == nil {
+		h.subscribers[shopID] = map[chan model.OrderEvent]struct{}{}
+	}
+	h.subscribers[shopID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+
+			delete(h.subscribers[shopID], ch)
+			if len(h.subscribers[shopID]) == 0 {
+				delete(h.subscribers, shopID)
+			}
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers the event to the subscribers of its shop without blocking.
+func (h *OrderHub) Publish(e model.OrderEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch := range h.subscribers[e.ShopID] {
+		select {
+		case ch <- e:
+		default:
+			log.Printf("drop order event for slow subscriber. shop_id: %d, order_id: %d", e.ShopID, e.Order.ID)
+		}
+	}
+}
+
+// SubscriberCount returns the number of subscribers of the shop.
+func (h *OrderHub) SubscriberCount(shopID int64) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.subscribers[shopID])
+}
@@ -0,0 +1,56 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderHubPublish(t *testing.T) {
+	h := NewOrderHub()
+
+	ch1, unsubscribe1 := h.Subscribe(1)
+	defer unsubscribe1()
+	ch2, unsubscribe2 := h.Subscribe(2)
+	defer unsubscribe2()
+
+	e := model.OrderEvent{Type: model.OrderEventCreated, ShopID: 1, Order: model.Order{ID: 10}}
+	h.Publish(e)
+
+	assert.Equal(t, e, <-ch1)
+	select {
+	case got := <-ch2:
+		t.Fatalf("unexpected event for other shop: %v", got)
+	default:
+	}
+}
+
+func TestOrderHubUnsubscribe(t *testing.T) {
+	h := NewOrderHub()
+
+	ch, unsubscribe := h.Subscribe(1)
+	assert.Equal(t, 1, h.SubscriberCount(1))
+
+	unsubscribe()
+	unsubscribe()
+
+	_, ok := <-ch
+	assert.False(t, ok)
+	assert.Equal(t, 0, h.SubscriberCount(1))
+
+	h.Publish(model.OrderEvent{ShopID: 1})
+}
+
+func TestOrderHubDropsForSlowSubscriber(t *testing.T) {
+	h := NewOrderHub()
+
+	ch, unsubscribe := h.Subscribe(1)
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBuffer+5; i++ {
+		h.Publish(model.OrderEvent{ShopID: 1, Order: model.Order{ID: int64(i)}})
+	}
+
+	assert.Equal(t, subscriberBuffer, len(ch))
+}
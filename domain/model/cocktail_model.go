@@ -3,57 +3,148 @@ package model
 import "database/sql"
 
 type Cocktail struct {
-	ID        int64  `json:"id"`
-	Name      string `json:"name"`
-	ImageURL  string `json:"image_url"`
-	CreatedAt int64  `json:"created_at"`
-	UpdatedAt int64  `json:"updated_at"`
+	ID        int64          `json:"id"`
+	Name      string         `json:"name"`
+	Reading   string         `json:"reading"`
+	ImageURL  string         `json:"image_url"`
+	Images    *ImageVariants `json:"images"`
+	CreatedAt int64          `json:"created_at"`
+	UpdatedAt int64          `json:"updated_at"`
 }
 
 type NullableCocktail struct {
 	ID        int64
 	Name      string
+	Reading   string
 	ImageURL  sql.NullString
 	CreatedAt int64
 	UpdatedAt int64
 }
 
 type CocktailDetail struct {
-	ID        int64      `json:"id"`
-	Name      string     `json:"name"`
-	ImageURL  string     `json:"image_url"`
+	ID        int64          `json:"id"`
+	Name      string         `json:"name"`
+	Reading   string         `json:"reading"`
+	ImageURL  string         `json:"image_url"`
+	Images    *ImageVariants `json:"images"`
+	Materials []Material     `json:"materials"`
+	Method    Method         `json:"method"`
+	Glass     string         `json:"glass"`
+	Garnish   string         `json:"garnish"`
+	Notes     string         `json:"notes"`
+	Steps     []string       `json:"steps"`
+	// ABV and StandardDrinks are estimated for one serving once mixed, and are nil when they cannot be.
+	ABV            *float64 `json:"abv"`
+	StandardDrinks *float64 `json:"standard_drinks"`
+	CreatedAt      int64    `json:"created_at"`
+	UpdatedAt      int64    `json:"updated_at"`
+}
+
+// Method is how the materials are mixed. It is empty when the recipe does not say.
+type Method string
+
+const (
+	MethodShake Method = "shake"
+	MethodStir  Method = "stir"
+	MethodBuild Method = "build"
+	MethodBlend Method = "blend"
+)
+
+// CocktailBatch is a recipe scaled to be made for many servings at once.
+type CocktailBatch struct {
+	Cocktail  Cocktail   `json:"cocktail"`
+	Servings  int64      `json:"servings"`
+	Method    Method     `json:"method"`
 	Materials []Material `json:"materials"`
-	CreatedAt int64      `json:"created_at"`
-	UpdatedAt int64      `json:"updated_at"`
+	// Water is added to a stirred or shaken batch in place of the ice melted while mixing, and is nil otherwise.
+	Water *MaterialQuantity `json:"water"`
+	// Volume is the whole batch including the water, and is nil when no material is measured by volume.
+	Volume *MaterialQuantity `json:"volume"`
+}
+
+type BatchParams struct {
+	Servings int64 `json:"servings" validate:"gte=1,lte=1000"`
 }
 
 type NullableCocktailDetailRow struct {
 	ID           int64
 	Name         string
+	Reading      string
 	ImageURL     sql.NullString
 	MaterialID   int64
 	MaterialName string
-	Quantity     int64
+	MaterialABV  *float64
+	Quantity     float64
 	Unit         string
 }
 
 type Material struct {
 	ID       int64            `json:"id"`
 	Name     string           `json:"name"`
+	ABV      *float64         `json:"abv"`
 	Quantity MaterialQuantity `json:"quantity"`
 }
 
 type MaterialQuantity struct {
-	Quantity int64  `json:"quantity"`
-	Unit     string `json:"unit"`
+	Quantity float64 `json:"quantity" validate:"gte=0"`
+	Unit     string  `json:"unit" validate:"max=128"`
 }
 
 type CocktailParams struct {
-	Name      string
+	Name      string           `json:"name" validate:"required,max=128"`
+	Reading   string           `json:"reading" validate:"max=128,kana"`
+	Materials []MaterialParams `json:"materials" validate:"required,min=1,dive"`
+	Method    Method           `json:"method" validate:"omitempty,oneof=shake stir build blend"`
+	Glass     string           `json:"glass" validate:"max=64"`
+	Garnish   string           `json:"garnish" validate:"max=128"`
+	Notes     string           `json:"notes" validate:"max=2000"`
+	Steps     []string         `json:"steps" validate:"max=30,dive,required,max=512"`
+}
+
+type CocktailFilter struct {
+	Keyword          string
+	Materials        []string
+	ExcludeMaterials []string
+	MaxABV           *float64
+}
+
+type CocktailPatchParams struct {
+	Name      *string
+	Reading   *string
 	Materials []MaterialParams
+	Method    *Method
+	Glass     *string
+	Garnish   *string
+	Notes     *string
+	Steps     []string
 }
 
 type MaterialParams struct {
-	Name     string           `json:"name"`
+	Name     string           `json:"name" validate:"required,max=128"`
 	Quantity MaterialQuantity `json:"quantity"`
 }
+
+type MakeableParams struct {
+	MaterialIDs   []int64  `json:"material_ids"`
+	MaterialNames []string `json:"material_names"`
+	MaxMissing    int64    `json:"max_missing"`
+}
+
+type MakeableCocktail struct {
+	Cocktail         Cocktail   `json:"cocktail"`
+	MissingMaterials []Material `json:"missing_materials"`
+}
+
+type CocktailSearchResult struct {
+	Cocktail   Cocktail          `json:"cocktail"`
+	Score      float64           `json:"score"`
+	Highlights []SearchHighlight `json:"highlights"`
+}
+
+// SearchHighlight points at the characters [Start, End) of Text which matched the query.
+type SearchHighlight struct {
+	Field string `json:"field"`
+	Text  string `json:"text"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
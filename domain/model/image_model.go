@@ -0,0 +1,32 @@
+package model
+
+// Image is a stored image with its type sniffed from the data.
+type Image struct {
+	ID          int64
+	ContentType string
+	Data        []byte
+}
+
+// CocktailImage is the image an upload set on a cocktail.
+type CocktailImage struct {
+	ID          int64          `json:"id"`
+	CocktailID  int64          `json:"cocktail_id"`
+	URL         string         `json:"url"`
+	ContentType string         `json:"content_type"`
+	Size        int64          `json:"size"`
+	Images      *ImageVariants `json:"images"`
+}
+
+// ImageVariants are the URLs of an image resized for where it is shown, like a thumbnail for the menu list.
+// Only images stored by the API have them, so they are nil for a cocktail whose image is hosted elsewhere.
+type ImageVariants struct {
+	Thumbnail ImageFormats `json:"thumbnail"`
+	Medium    ImageFormats `json:"medium"`
+	Full      ImageFormats `json:"full"`
+}
+
+// ImageFormats are the URLs of an image encoded as JPEG and as WebP, which is smaller but not read by every browser.
+type ImageFormats struct {
+	JPEG string `json:"jpeg"`
+	WebP string `json:"webp"`
+}
@@ -0,0 +1,41 @@
+package model
+
+type MaterialItem struct {
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	ABV       *float64 `json:"abv"`
+	CreatedAt int64    `json:"created_at"`
+	UpdatedAt int64    `json:"updated_at"`
+}
+
+type MaterialDetail struct {
+	ID        int64           `json:"id"`
+	Name      string          `json:"name"`
+	ABV       *float64        `json:"abv"`
+	Aliases   []MaterialAlias `json:"aliases"`
+	Cocktails []Cocktail      `json:"cocktails"`
+	CreatedAt int64           `json:"created_at"`
+	UpdatedAt int64           `json:"updated_at"`
+}
+
+// MaterialAlias is another name the material is matched by, like "Gin" for "ジン".
+type MaterialAlias struct {
+	ID         int64  `json:"id"`
+	MaterialID int64  `json:"material_id"`
+	Name       string `json:"name"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+type MaterialNameParams struct {
+	Name string `json:"name" validate:"required,max=128"`
+}
+
+// MaterialABVParams sets the alcohol by volume of a material in percent. A nil ABV means it is unknown.
+type MaterialABVParams struct {
+	ABV *float64 `json:"abv" validate:"omitempty,gte=0,lte=100"`
+}
+
+type MaterialMergeParams struct {
+	SourceID int64 `json:"source_id" validate:"gt=0"`
+	TargetID int64 `json:"target_id" validate:"gt=0"`
+}
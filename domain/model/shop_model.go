@@ -2,9 +2,12 @@ package model
 
 import "database/sql"
 
+const DefaultTaxRate = int64(10)
+
 type Shop struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	TaxRate int64  `json:"tax_rate"`
 }
 
 type Table struct {
@@ -15,34 +18,174 @@ type Table struct {
 type ShopCocktail struct {
 	ShopID     int64 `json:"shop_id"`
 	CocktailID int64 `json:"cocktail_id"`
+	Price      int64 `json:"price"`
+}
+
+type ShopCocktailAvailability struct {
+	SoldOut bool  `json:"sold_out"`
+	BackAt  int64 `json:"back_at,omitempty"`
+}
+
+// IsSoldOut reports whether the cocktail is still marked as sold out at now.
+// A sold out cocktail becomes available again once its BackAt time has passed.
+func (a ShopCocktailAvailability) IsSoldOut(now int64) bool {
+	return a.SoldOut && (a.BackAt == 0 || now < a.BackAt)
+}
+
+type ShopMenuCocktail struct {
+	ID           int64                    `json:"id"`
+	Name         string                   `json:"name"`
+	Reading      string                   `json:"reading"`
+	ImageURL     string                   `json:"image_url"`
+	Images       *ImageVariants           `json:"images"`
+	Price        int64                    `json:"price"`
+	Availability ShopCocktailAvailability `json:"availability"`
+	InStock      bool                     `json:"in_stock"`
+	Available    bool                     `json:"available"`
+	CreatedAt    int64                    `json:"created_at"`
+	UpdatedAt    int64                    `json:"updated_at"`
+}
+
+type InventoryItem struct {
+	MaterialID   int64   `json:"material_id"`
+	MaterialName string  `json:"material_name"`
+	Quantity     float64 `json:"quantity"`
+	Unit         string  `json:"unit"`
+	UpdatedAt    int64   `json:"updated_at"`
+}
+
+type OrderStatus string
+
+const (
+	OrderStatusAccepted  OrderStatus = "accepted"
+	OrderStatusPreparing OrderStatus = "preparing"
+	OrderStatusReady     OrderStatus = "ready"
+	OrderStatusServed    OrderStatus = "served"
+	OrderStatusCancelled OrderStatus = "cancelled"
+)
+
+// UnprovidedOrderStatuses are the statuses of orders which still have to be served.
+var UnprovidedOrderStatuses = []OrderStatus{OrderStatusAccepted, OrderStatusPreparing, OrderStatusReady}
+
+// orderTransitions lists the statuses each status can move to.
+// Orders only move forward, so a step may be skipped, and can be cancelled until they are served.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusAccepted:  {OrderStatusPreparing, OrderStatusReady, OrderStatusServed, OrderStatusCancelled},
+	OrderStatusPreparing: {OrderStatusReady, OrderStatusServed, OrderStatusCancelled},
+	OrderStatusReady:     {OrderStatusServed, OrderStatusCancelled},
+}
+
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, t := range orderTransitions[s] {
+		if t == next {
+			return true
+		}
+	}
+	return false
 }
 
 type Order struct {
-	ID             int64 `json:"id"`
-	TableID        int64 `json:"table_id"`
-	ShopCocktailID int64 `json:"shop_cocktail_id"`
-	CreatedAt      int64 `json:"created_at"`
-	UpdatedAt      int64 `json:"updated_at"`
+	ID             int64       `json:"id"`
+	TableID        int64       `json:"table_id"`
+	ShopCocktailID int64       `json:"shop_cocktail_id"`
+	Price          int64       `json:"price"`
+	Status         OrderStatus `json:"status"`
+	AcceptedAt     int64       `json:"accepted_at,omitempty"`
+	PreparingAt    int64       `json:"preparing_at,omitempty"`
+	ReadyAt        int64       `json:"ready_at,omitempty"`
+	ServedAt       int64       `json:"served_at,omitempty"`
+	CancelledAt    int64       `json:"cancelled_at,omitempty"`
+	CreatedAt      int64       `json:"created_at"`
+	UpdatedAt      int64       `json:"updated_at"`
+}
+
+// SetStatus moves the order to status and records when it happened.
+func (o *Order) SetStatus(status OrderStatus, at int64) {
+	o.Status = status
+	o.UpdatedAt = at
+
+	switch status {
+	case OrderStatusAccepted:
+		o.AcceptedAt = at
+	case OrderStatusPreparing:
+		o.PreparingAt = at
+	case OrderStatusReady:
+		o.ReadyAt = at
+	case OrderStatusServed:
+		o.ServedAt = at
+	case OrderStatusCancelled:
+		o.CancelledAt = at
+	}
+}
+
+type OrderEventType string
+
+const (
+	OrderEventCreated       OrderEventType = "created"
+	OrderEventStatusChanged OrderEventType = "status_changed"
+)
+
+type OrderEvent struct {
+	Type   OrderEventType `json:"type"`
+	ShopID int64          `json:"shop_id"`
+	Order  Order          `json:"order"`
+}
+
+type Bill struct {
+	ShopID   int64       `json:"shop_id"`
+	TableID  int64       `json:"table_id"`
+	Orders   []BillOrder `json:"orders"`
+	Subtotal int64       `json:"subtotal"`
+	TaxRate  int64       `json:"tax_rate"`
+	Tax      int64       `json:"tax"`
+	Total    int64       `json:"total"`
+}
+
+type BillOrder struct {
+	OrderID    int64  `json:"order_id"`
+	CocktailID int64  `json:"cocktail_id"`
+	Name       string `json:"name"`
+	Price      int64  `json:"price"`
+	CreatedAt  int64  `json:"created_at"`
 }
 
 type TableOrder struct {
-	Name     string `json:"name"`
-	ImageURL string `json:"image_url"`
+	ID       int64       `json:"id"`
+	Name     string      `json:"name"`
+	ImageURL string      `json:"image_url"`
+	Status   OrderStatus `json:"status"`
 }
 
 type NullableTableOrder struct {
+	ID       int64
 	Name     string
 	ImageURL sql.NullString
+	Status   OrderStatus
 }
 
 type ShopParams struct {
-	Name string `json:"name"`
+	Name    string `json:"name" validate:"required,max=128"`
+	TaxRate *int64 `json:"tax_rate" validate:"omitempty,gte=0,lte=100"`
 }
 
 type ShopCocktailParams struct {
-	CocktailIDs []int64 `json:"cocktail_ids"`
+	CocktailIDs []int64 `json:"cocktail_ids" validate:"required,min=1,dive,gt=0"`
+}
+
+type ShopCocktailPriceParams struct {
+	Price int64 `json:"price" validate:"gte=0"`
 }
 
 type OrderParams struct {
-	CocktailIDs []int64 `json:"cocktail_ids"`
+	CocktailIDs []int64 `json:"cocktail_ids" validate:"required,min=1,dive,gt=0"`
+}
+
+type InventoryParams struct {
+	Materials []InventoryMaterialParams `json:"materials" validate:"required,min=1,dive"`
+}
+
+type InventoryMaterialParams struct {
+	MaterialID int64   `json:"material_id" validate:"gt=0"`
+	Quantity   float64 `json:"quantity" validate:"gte=0"`
+	Unit       string  `json:"unit" validate:"required,max=128"`
 }
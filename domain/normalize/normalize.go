@@ -0,0 +1,79 @@
+// Package normalize folds the spellings of a name which people read as the same into one key.
+package normalize
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Name returns the key names are matched with.
+// NFKC turns full-width alphanumerics into half-width ones and half-width katakana into full-width ones,
+// then the result is lowercased, hiragana is turned into katakana, and spaces and middle dots are dropped,
+// so "ｼﾞﾝ", "じん" and "ジン" share a key, and so do "Ｇｉｎ" and "gin".
+func Name(s string) string {
+	s = strings.ToLower(norm.NFKC.String(s))
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r), r == '・':
+			continue
+		case r >= 'ぁ' && r <= 'ゖ', r == 'ゝ', r == 'ゞ':
+			r += 'ァ' - 'ぁ'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Rank tells how the keyword matches the texts once all of them are normalized with Name:
+// 0 when one of the texts starts with the keyword, 1 when one contains it, and -1 when none does.
+func Rank(keyword string, texts ...string) int {
+	key := Name(keyword)
+	rank := -1
+	for _, t := range texts {
+		n := Name(t)
+		switch {
+		case strings.HasPrefix(n, key):
+			return 0
+		case strings.Contains(n, key):
+			rank = 1
+		}
+	}
+	return rank
+}
+
+// Span is the range [Start, End) of runes of the original text a rune of the key comes from.
+type Span struct {
+	Start int
+	End   int
+}
+
+// NameSpans returns the key of s like Name does, along with the span of s each rune of the key comes from,
+// so that a match found in the key can be pointed at in s.
+func NameSpans(s string) (string, []Span) {
+	runes := []rune(s)
+
+	var b strings.Builder
+	var spans []Span
+	for i := 0; i < len(runes); {
+		// a voiced sound mark joins the kana before it, as "ｼﾞ" becomes "ジ"
+		j := i + 1
+		for j < len(runes) && isCombining(runes[j]) {
+			j++
+		}
+
+		for _, r := range Name(string(runes[i:j])) {
+			b.WriteRune(r)
+			spans = append(spans, Span{Start: i, End: j})
+		}
+		i = j
+	}
+	return b.String(), spans
+}
+
+func isCombining(r rune) bool {
+	return r == 'ﾞ' || r == 'ﾟ' || unicode.Is(unicode.Mn, r)
+}
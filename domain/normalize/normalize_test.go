@@ -0,0 +1,77 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestName(t *testing.T) {
+	type testcase struct {
+		Name  string
+		Input string
+		Want  string
+	}
+
+	tests := []testcase{
+		{Name: "katakana", Input: "ジン", Want: "ジン"},
+		{Name: "hiragana", Input: "じん", Want: "ジン"},
+		{Name: "half-width katakana", Input: "ｼﾞﾝ", Want: "ジン"},
+		{Name: "full-width alphabet", Input: "Ｇｉｎ", Want: "gin"},
+		{Name: "upper case", Input: "GIN", Want: "gin"},
+		{Name: "spaces and middle dots", Input: "ドライ・ジン　", Want: "ドライジン"},
+		{Name: "iteration mark", Input: "いすゞ", Want: "イスヾ"},
+		{Name: "kanji", Input: "牛乳", Want: "牛乳"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Want, Name(tc.Input))
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	type testcase struct {
+		Name    string
+		Keyword string
+		Texts   []string
+		Want    int
+	}
+
+	tests := []testcase{
+		{Name: "prefix", Keyword: "すこっち", Texts: []string{"スコッチ・オーレ", ""}, Want: 0},
+		{Name: "prefix of reading", Keyword: "ゆき", Texts: []string{"雪国", "ゆきぐに"}, Want: 0},
+		{Name: "substring", Keyword: "ｵｰﾚ", Texts: []string{"スコッチ・オーレ", ""}, Want: 1},
+		{Name: "no match", Keyword: "じん", Texts: []string{"スコッチ・オーレ", ""}, Want: -1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Want, Rank(tc.Keyword, tc.Texts...))
+		})
+	}
+}
+
+func TestNameSpans(t *testing.T) {
+	type testcase struct {
+		Name      string
+		Input     string
+		WantSpans []Span
+	}
+
+	tests := []testcase{
+		{Name: "one to one", Input: "ジン", WantSpans: []Span{{0, 1}, {1, 2}}},
+		{Name: "voiced sound mark", Input: "ｼﾞﾝ", WantSpans: []Span{{0, 2}, {2, 3}}},
+		{Name: "dropped middle dot", Input: "ピンク・ジン", WantSpans: []Span{{0, 1}, {1, 2}, {2, 3}, {4, 5}, {5, 6}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			key, spans := NameSpans(tc.Input)
+
+			assert.Equal(t, Name(tc.Input), key)
+			assert.Equal(t, tc.WantSpans, spans)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// withOrientation returns the JPEG data with an EXIF segment recording the orientation, in the byte order.
+func withOrientation(data []byte, order binary.ByteOrder, o uint16) []byte {
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], orientationTag)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], o)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(2+len(segment)))
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, app1...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func encodeJPEG(t *testing.T, m image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, m, nil); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestOrientation(t *testing.T) {
+	data := encodeJPEG(t, image.NewGray(image.Rect(0, 0, 8, 8)))
+
+	assert.Equal(t, 1, orientation(data))
+	assert.Equal(t, 6, orientation(withOrientation(data, binary.LittleEndian, 6)))
+	assert.Equal(t, 8, orientation(withOrientation(data, binary.BigEndian, 8)))
+
+	// out of range values and data which is not a JPEG are left as they are
+	assert.Equal(t, 1, orientation(withOrientation(data, binary.BigEndian, 9)))
+	assert.Equal(t, 1, orientation(pngHeader))
+	assert.Equal(t, 1, orientation(withOrientation(data, binary.LittleEndian, 6)[:20]))
+}
+
+func TestOrient(t *testing.T) {
+	// a b c
+	// d e f
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i, c := range "abcdef" {
+		src.Pix[4*i] = uint8(c)
+	}
+	rows := func(m *image.RGBA) []string {
+		var rows []string
+		for y := 0; y < m.Bounds().Dy(); y++ {
+			var row []byte
+			for x := 0; x < m.Bounds().Dx(); x++ {
+				row = append(row, m.Pix[m.PixOffset(x, y)])
+			}
+			rows = append(rows, string(row))
+		}
+		return rows
+	}
+
+	tests := []struct {
+		o    int
+		want []string
+	}{
+		{o: 1, want: []string{"abc", "def"}},
+		{o: 2, want: []string{"cba", "fed"}},
+		{o: 3, want: []string{"fed", "cba"}},
+		{o: 4, want: []string{"def", "abc"}},
+		{o: 5, want: []string{"ad", "be", "cf"}},
+		{o: 6, want: []string{"da", "eb", "fc"}},
+		{o: 7, want: []string{"fc", "eb", "da"}},
+		{o: 8, want: []string{"cf", "be", "ad"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, rows(orient(src, tt.o)), "orientation %d", tt.o)
+	}
+}
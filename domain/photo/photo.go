@@ -0,0 +1,68 @@
+// Package photo checks the images uploaded for cocktails, resizes them and names the URLs they are served from.
+package photo
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/shake551/cocktails-api/domain/errs"
+)
+
+// MaxSize is the largest image accepted, in bytes.
+const MaxSize = 5 << 20
+
+// urlPrefix is where the API serves the stored images.
+const urlPrefix = "/images/"
+
+var (
+	ErrTooLarge    = errs.TooLarge("image must be at most 5MB")
+	ErrUnsupported = errs.Unsupported("image must be a JPEG, PNG, GIF or WebP")
+)
+
+// contentTypes are the types of image accepted, as sniffed from the data.
+var contentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Check returns the content type of an uploaded image, sniffed from the data rather than trusting
+// the name or the type the client sent.
+func Check(data []byte) (contentType string, err error) {
+	if len(data) > MaxSize {
+		return "", ErrTooLarge
+	}
+
+	contentType = ContentType(data)
+	if !contentTypes[contentType] {
+		return "", ErrUnsupported
+	}
+	return contentType, nil
+}
+
+// ContentType sniffs the type of the image data.
+func ContentType(data []byte) string {
+	return http.DetectContentType(data)
+}
+
+// URL returns the URL the stored image is served from.
+func URL(id int64) string {
+	return urlPrefix + strconv.FormatInt(id, 10)
+}
+
+// IDOf returns the id of the stored image the URL points at.
+// ok is false for a URL elsewhere, like an image hosted on another site.
+func IDOf(url string) (id int64, ok bool) {
+	s := strings.TrimPrefix(url, urlPrefix)
+	if s == url {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,48 @@
+package photo
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pngHeader is enough of a PNG file for its type to be sniffed.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestCheck(t *testing.T) {
+	type testcase struct {
+		Name    string
+		Data    []byte
+		Want    string
+		WantErr error
+	}
+
+	tests := []testcase{
+		{Name: "png", Data: pngHeader, Want: "image/png"},
+		{Name: "webp", Data: []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), Want: "image/webp"},
+		{Name: "text", Data: []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"), WantErr: ErrUnsupported},
+		{Name: "too large", Data: append(pngHeader, bytes.Repeat([]byte{0}, MaxSize)...), WantErr: ErrTooLarge},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			contentType, err := Check(tc.Data)
+
+			assert.ErrorIs(t, err, tc.WantErr)
+			assert.Equal(t, tc.Want, contentType)
+		})
+	}
+}
+
+func TestIDOf(t *testing.T) {
+	id, ok := IDOf(URL(12))
+	assert.True(t, ok)
+	assert.Equal(t, int64(12), id)
+
+	_, ok = IDOf("https://example.com/images/12")
+	assert.False(t, ok)
+
+	_, ok = IDOf("/images/12/thumbnail")
+	assert.False(t, ok)
+}
@@ -0,0 +1,181 @@
+package photo
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+
+	"github.com/chai2010/webp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"github.com/shake551/cocktails-api/domain/errs"
+	"github.com/shake551/cocktails-api/domain/model"
+)
+
+// MaxPixels is the largest image accepted, in pixels, so that a small but highly compressed file cannot
+// take up gigabytes once decoded.
+const MaxPixels = 40_000_000
+
+var (
+	ErrTooManyPixels = errs.TooLarge("image must be at most 40 megapixels")
+	ErrCorrupt       = errs.BadRequest("image could not be decoded")
+)
+
+// The sizes every image is resized to.
+const (
+	Thumbnail = "thumbnail"
+	Medium    = "medium"
+	Full      = "full"
+)
+
+// sizes are the longest sides of the sizes, largest first as each is resized from the one before.
+var sizes = []struct {
+	name string
+	side int
+}{
+	{Full, 1600},
+	{Medium, 800},
+	{Thumbnail, 320},
+}
+
+// The formats every size is encoded in, named by their file extensions.
+const (
+	JPEG = "jpg"
+	WebP = "webp"
+)
+
+// formats are the formats every size is encoded in, JPEG first as every browser reads it.
+var formats = []string{JPEG, WebP}
+
+// quality is used for both formats. At the same quality the WebP variants come out about a third smaller.
+const quality = 82
+
+// VariantName names the size encoded in the format, like "thumbnail.jpg".
+func VariantName(size, format string) string {
+	return size + "." + format
+}
+
+// VariantNames returns the names of the variants made of every image, largest first.
+func VariantNames() []string {
+	names := make([]string, 0, len(sizes)*len(formats))
+	for _, s := range sizes {
+		for _, f := range formats {
+			names = append(names, VariantName(s.name, f))
+		}
+	}
+	return names
+}
+
+// IsVariant reports whether name is one of the variants made of every image.
+func IsVariant(name string) bool {
+	for _, n := range VariantNames() {
+		if name == n {
+			return true
+		}
+	}
+	return false
+}
+
+// Variants decodes an image which passed Check and returns it resized to every size in every format, by VariantName.
+// The image is turned upright by its EXIF orientation and encoded afresh, so none of its metadata, like where the photo
+// was taken, is carried over. Transparent areas are drawn over white, and an image smaller than a size is not enlarged.
+func Variants(data []byte) (map[string][]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	o := 1
+	if format == "jpeg" {
+		o = orientation(data)
+	}
+
+	variants := make(map[string][]byte, len(sizes)*len(formats))
+	var m *image.RGBA
+	for _, s := range sizes {
+		if m == nil {
+			// a square fits the same either way up, so the image is turned after the first resize, which is cheaper
+			m = orient(resize(src, s.side), o)
+		} else {
+			m = resize(m, s.side)
+		}
+
+		var j bytes.Buffer
+		if err := jpeg.Encode(&j, m, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, err
+		}
+		// the image is opaque once drawn over white, so it is encoded without an alpha channel
+		w, err := webp.EncodeRGB(m, quality)
+		if err != nil {
+			return nil, err
+		}
+		variants[VariantName(s.name, JPEG)] = j.Bytes()
+		variants[VariantName(s.name, WebP)] = w
+	}
+	return variants, nil
+}
+
+// resize returns m drawn over white, scaled down to fit in a square of the side.
+func resize(m image.Image, side int) *image.RGBA {
+	b := m.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > side || h > side {
+		if w >= h {
+			w, h = side, max1(h*side/w)
+		} else {
+			w, h = max1(w*side/h), side
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	if w == b.Dx() && h == b.Dy() {
+		draw.Draw(dst, dst.Bounds(), m, b.Min, draw.Over)
+	} else {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), m, b, draw.Over, nil)
+	}
+	return dst
+}
+
+func max1(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// VariantURL returns the URL the variant of the stored image is served from.
+func VariantURL(id int64, name string) string {
+	return URL(id) + "/" + name
+}
+
+// VariantURLs returns the URLs of the variants of the stored image the URL points at.
+// It is nil for a URL elsewhere, which has no variants.
+func VariantURLs(url string) *model.ImageVariants {
+	id, ok := IDOf(url)
+	if !ok {
+		return nil
+	}
+
+	formats := func(size string) model.ImageFormats {
+		return model.ImageFormats{
+			JPEG: VariantURL(id, VariantName(size, JPEG)),
+			WebP: VariantURL(id, VariantName(size, WebP)),
+		}
+	}
+	return &model.ImageVariants{
+		Thumbnail: formats(Thumbnail),
+		Medium:    formats(Medium),
+		Full:      formats(Full),
+	}
+}
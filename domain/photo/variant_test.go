@@ -0,0 +1,144 @@
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/webp"
+)
+
+func encodePNG(t *testing.T, m image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, m); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// sizeOf decodes the variant in the format its name ends with and returns its size.
+func sizeOf(t *testing.T, name string, data []byte) image.Point {
+	t.Helper()
+
+	var m image.Image
+	var err error
+	if strings.HasSuffix(name, WebP) {
+		m, err = webp.Decode(bytes.NewReader(data))
+		assert.Equal(t, "image/webp", ContentType(data), name)
+	} else {
+		m, _, err = image.Decode(bytes.NewReader(data))
+		assert.Equal(t, "image/jpeg", ContentType(data), name)
+	}
+	if err != nil {
+		t.Fatalf("%s: decode error = %v", name, err)
+	}
+	return m.Bounds().Size()
+}
+
+func TestVariants(t *testing.T) {
+	variants, err := Variants(encodePNG(t, image.NewGray(image.Rect(0, 0, 2000, 1000))))
+	assert.Nil(t, err)
+
+	want := map[string]image.Point{
+		"full.jpg":       {1600, 800},
+		"full.webp":      {1600, 800},
+		"medium.jpg":     {800, 400},
+		"medium.webp":    {800, 400},
+		"thumbnail.jpg":  {320, 160},
+		"thumbnail.webp": {320, 160},
+	}
+	assert.Len(t, variants, len(want))
+	for name, size := range want {
+		assert.True(t, IsVariant(name), name)
+		assert.Equal(t, size, sizeOf(t, name, variants[name]), name)
+	}
+}
+
+func TestVariantsSmallImage(t *testing.T) {
+	// a small image is not enlarged, and its transparent areas turn white
+	m := image.NewNRGBA(image.Rect(0, 0, 500, 100))
+	variants, err := Variants(encodePNG(t, m))
+	assert.Nil(t, err)
+
+	assert.Equal(t, image.Pt(500, 100), sizeOf(t, "full.jpg", variants["full.jpg"]))
+	assert.Equal(t, image.Pt(320, 64), sizeOf(t, "thumbnail.jpg", variants["thumbnail.jpg"]))
+
+	full, _, err := image.Decode(bytes.NewReader(variants["full.jpg"]))
+	assert.Nil(t, err)
+	r, g, b, _ := full.At(250, 50).RGBA()
+	assert.Greater(t, r>>8+g>>8+b>>8, uint32(3*250))
+}
+
+func TestVariantsOrientation(t *testing.T) {
+	// taken with the camera held upright, so stored on its side with EXIF to turn it
+	m := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			m.Set(x, y, color.RGBA{0xff, 0, 0, 0xff})
+		}
+	}
+	data := withOrientation(encodeJPEG(t, m), binary.LittleEndian, 6)
+
+	variants, err := Variants(data)
+	assert.Nil(t, err)
+
+	// turned upright, with none of the metadata left
+	assert.Equal(t, image.Pt(20, 40), sizeOf(t, "full.jpg", variants["full.jpg"]))
+	assert.Equal(t, image.Pt(20, 40), sizeOf(t, "thumbnail.webp", variants["thumbnail.webp"]))
+	assert.False(t, bytes.Contains(variants["full.jpg"], []byte("Exif")))
+	assert.Equal(t, 1, orientation(variants["full.jpg"]))
+}
+
+func TestVariantsErrors(t *testing.T) {
+	// a PNG claiming to be 10000x5000, which is small enough to upload but not to decode
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], 10000)
+	binary.BigEndian.PutUint32(ihdr[8:], 5000)
+	ihdr[12], ihdr[13] = 8, 0
+	huge := append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0d"), ihdr...)
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.ChecksumIEEE(ihdr))
+	huge = append(huge, crc...)
+
+	_, err := Variants(huge)
+	assert.ErrorIs(t, err, ErrTooManyPixels)
+
+	_, err = Variants(pngHeader)
+	assert.ErrorIs(t, err, ErrCorrupt)
+
+	// the header is readable but the pixels are cut off
+	data := encodePNG(t, image.NewGray(image.Rect(0, 0, 64, 64)))
+	_, err = Variants(data[:len(data)-20])
+	assert.ErrorIs(t, err, ErrCorrupt)
+}
+
+func TestIsVariant(t *testing.T) {
+	assert.True(t, IsVariant("thumbnail.webp"))
+	assert.True(t, IsVariant("medium.jpg"))
+	assert.False(t, IsVariant("thumbnail.png"))
+	assert.False(t, IsVariant("../1"))
+	assert.False(t, IsVariant(""))
+}
+
+func TestVariantNames(t *testing.T) {
+	assert.Equal(t, []string{"full.jpg", "full.webp", "medium.jpg", "medium.webp", "thumbnail.jpg", "thumbnail.webp"}, VariantNames())
+}
+
+func TestVariantURLs(t *testing.T) {
+	v := VariantURLs(URL(12))
+	assert.Equal(t, "/images/12/thumbnail.webp", v.Thumbnail.WebP)
+	assert.Equal(t, "/images/12/medium.jpg", v.Medium.JPEG)
+	assert.Equal(t, "/images/12/full.webp", v.Full.WebP)
+
+	assert.Nil(t, VariantURLs("https://example.com/images/12"))
+	assert.Nil(t, VariantURLs(""))
+}
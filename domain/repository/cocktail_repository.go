@@ -7,9 +7,15 @@ import (
 
 //go:generate mockery --dir . --name CocktailRepository --outpkg repository_mock --output ../repository_mock --case underscore
 type CocktailRepository interface {
-	GetLimit(ctx context.Context, limit int64, offset int64, keyword string) ([]model.Cocktail, error)
+	GetLimit(ctx context.Context, limit int64, offset int64, filter model.CocktailFilter) ([]model.Cocktail, error)
 	GetByID(ctx context.Context, id int64) (model.CocktailDetail, error)
 	Create(ctx context.Context, params model.CocktailParams) (*model.CocktailDetail, error)
 	GetListByIDs(ctx context.Context, ids []int64) ([]model.Cocktail, error)
+	Update(ctx context.Context, id int64, params model.CocktailParams) (*model.CocktailDetail, error)
+	// UpdateImageURL sets the image of the cocktail, leaving the rest of the recipe as it is.
+	UpdateImageURL(ctx context.Context, id int64, url string) error
+	Delete(ctx context.Context, id int64) error
+	GetMakeable(ctx context.Context, materialIDs []int64, materialNames []string, maxMissing int64) ([]model.MakeableCocktail, error)
+	GetAllDetails(ctx context.Context) ([]model.CocktailDetail, error)
 }
 
@@ -0,0 +1,26 @@
+package repository
+
+import "github.com/shake551/cocktails-api/domain/errs"
+
+var (
+	ErrCocktailNotFound = errs.NotFound("cocktail not found")
+	ErrCocktailInUse    = errs.Conflict("cocktail has unprovided orders")
+
+	ErrImageNotFound = errs.NotFound("image not found")
+
+	ErrMaterialNotFound    = errs.NotFound("material not found")
+	ErrMaterialDuplicate   = errs.Conflict("material name already exists")
+	ErrMaterialUnitsDiffer = errs.Conflict("the materials are measured in units which cannot be added up")
+
+	ErrMaterialAliasNotFound = errs.NotFound("material alias not found")
+
+	ErrShopNotFound          = errs.NotFound("shop not found")
+	ErrTableNotFound         = errs.NotFound("table not found")
+	ErrShopCocktailNotFound  = errs.NotFound("cocktail is not on the shop menu")
+	ErrOutOfStock            = errs.Conflict("not enough stock for the order")
+	ErrSoldOut               = errs.Conflict("cocktail is sold out")
+	ErrInventoryUnitMismatch = errs.Conflict("the stock of a material is kept in another unit than the recipe")
+
+	ErrOrderNotFound          = errs.NotFound("order not found")
+	ErrInvalidOrderTransition = errs.Conflict("invalid order status transition")
+)
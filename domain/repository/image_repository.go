@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+)
+
+// ImageRepository keeps the data of uploaded images. It may live outside the database, so it does not take part in a UnitOfWork.
+//
+//go:generate mockery --dir . --name ImageRepository --outpkg repository_mock --output ../repository_mock --case underscore
+type ImageRepository interface {
+	Create(ctx context.Context, data []byte) (int64, error)
+	Get(ctx context.Context, id int64) ([]byte, error)
+	// PutVariants stores resized copies of the image by name, replacing the ones stored under the same names.
+	PutVariants(ctx context.Context, id int64, variants map[string][]byte) error
+	// GetVariant returns ErrImageNotFound when the variant was not stored, even if the image was.
+	GetVariant(ctx context.Context, id int64, name string) ([]byte, error)
+	// WithoutVariant returns the ids of the images which have no variant stored by the name, in ascending order.
+	WithoutVariant(ctx context.Context, name string) ([]int64, error)
+	// Delete removes the image along with its variants.
+	Delete(ctx context.Context, id int64) error
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"github.com/shake551/cocktails-api/domain/model"
+)
+
+//go:generate mockery --dir . --name MaterialRepository --outpkg repository_mock --output ../repository_mock --case underscore
+type MaterialRepository interface {
+	GetLimit(ctx context.Context, limit int64, offset int64, keyword string) ([]model.MaterialItem, error)
+	GetByID(ctx context.Context, id int64) (model.MaterialDetail, error)
+	Create(ctx context.Context, params model.MaterialNameParams) (*model.MaterialItem, error)
+	Rename(ctx context.Context, id int64, params model.MaterialNameParams) (*model.MaterialItem, error)
+	UpdateABV(ctx context.Context, id int64, params model.MaterialABVParams) (*model.MaterialItem, error)
+	AddAlias(ctx context.Context, materialID int64, params model.MaterialNameParams) (*model.MaterialAlias, error)
+	DeleteAlias(ctx context.Context, materialID int64, aliasID int64) error
+	Merge(ctx context.Context, sourceID int64, targetID int64) (model.MaterialDetail, error)
+}
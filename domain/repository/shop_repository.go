@@ -5,17 +5,26 @@ import (
 	"github.com/shake551/cocktails-api/domain/model"
 )
 
+//go:generate mockery --dir . --name ShopRepository --outpkg repository_mock --output ../repository_mock --case underscore
 type ShopRepository interface {
 	GetLimit(ctx context.Context, limit int64, offset int64) ([]model.Shop, error)
 	Create(ctx context.Context, params model.ShopParams) (*model.Shop, error)
 	GetByID(ctx context.Context, id int64) (model.Shop, error)
-	GetShopCocktailList(ctx context.Context, shopID int64, limit int64, offset int64) ([]model.Cocktail, error)
+	Update(ctx context.Context, id int64, params model.ShopParams) (*model.Shop, error)
+	GetShopCocktailList(ctx context.Context, shopID int64, limit int64, offset int64) ([]model.ShopMenuCocktail, error)
 	AddShopCocktail(ctx context.Context, shopID int64, params model.ShopCocktailParams) ([]*model.ShopCocktail, error)
+	UpdateShopCocktailPrice(ctx context.Context, shopID int64, cocktailID int64, params model.ShopCocktailPriceParams) (*model.ShopCocktail, error)
+	UpdateShopCocktailAvailability(ctx context.Context, shopID int64, cocktailID int64, params model.ShopCocktailAvailability) (*model.ShopCocktailAvailability, error)
 	GetShopCocktailDetail(ctx context.Context, shopID int64, cocktailID int64) (model.CocktailDetail, error)
 	GetUnprovidedOrderList(ctx context.Context, shopID int64, limit int64, offset int64) ([]*model.TableOrder, error)
 	AddTable(ctx context.Context, shopID int64) (*model.Table, error)
 	GetTable(ctx context.Context, shopID int64, tableID int64) (*model.Table, error)
 	GetTableOrderList(ctx context.Context, shopID int64, tableID int64, unprovided bool) ([]*model.TableOrder, error)
+	GetTableBillOrders(ctx context.Context, shopID int64, tableID int64) ([]model.BillOrder, error)
 	Order(ctx context.Context, shopID int64, tableID int64, params model.OrderParams) ([]*model.Order, error)
-	OrderProvide(ctx context.Context, shopID int64, tableID int64, orderID int64) error
+	GetOrder(ctx context.Context, shopID int64, tableID int64, orderID int64) (*model.Order, error)
+	UpdateOrderStatus(ctx context.Context, orderID int64, from model.OrderStatus, to model.OrderStatus, at int64) error
+	GetInventory(ctx context.Context, shopID int64) ([]model.InventoryItem, error)
+	UpdateInventory(ctx context.Context, shopID int64, params model.InventoryParams) ([]model.InventoryItem, error)
+	RestoreInventory(ctx context.Context, shopID int64, orderID int64, at int64) error
 }
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+)
+
+// Repositories are the repositories taking part in one unit of work.
+type Repositories struct {
+	Cocktail CocktailRepository
+	Material MaterialRepository
+	Shop     ShopRepository
+}
+
+//go:generate mockery --dir . --name UnitOfWork --outpkg repository_mock --output ../repository_mock --case underscore
+type UnitOfWork interface {
+	// Do runs fn with repositories sharing one transaction, committing when fn returns nil and rolling back otherwise.
+	// fn runs again when the transaction hits a deadlock or a lock wait timeout, so it must not have effects outside the repositories.
+	Do(ctx context.Context, fn func(repos Repositories) error) error
+}
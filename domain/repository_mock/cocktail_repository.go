@@ -37,6 +37,43 @@ func (_m *CocktailRepository) Create(ctx context.Context, params model.CocktailP
 	return r0, r1
 }
 
+// Delete provides a mock function with given fields: ctx, id
+func (_m *CocktailRepository) Delete(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAllDetails provides a mock function with given fields: ctx
+func (_m *CocktailRepository) GetAllDetails(ctx context.Context) ([]model.CocktailDetail, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []model.CocktailDetail
+	if rf, ok := ret.Get(0).(func(context.Context) []model.CocktailDetail); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.CocktailDetail)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: ctx, id
 func (_m *CocktailRepository) GetByID(ctx context.Context, id int64) (model.CocktailDetail, error) {
 	ret := _m.Called(ctx, id)
@@ -58,13 +95,13 @@ func (_m *CocktailRepository) GetByID(ctx context.Context, id int64) (model.Cock
 	return r0, r1
 }
 
-// GetLimit provides a mock function with given fields: ctx, limit, offset, keyword
-func (_m *CocktailRepository) GetLimit(ctx context.Context, limit int64, offset int64, keyword string) ([]model.Cocktail, error) {
-	ret := _m.Called(ctx, limit, offset, keyword)
+// GetLimit provides a mock function with given fields: ctx, limit, offset, filter
+func (_m *CocktailRepository) GetLimit(ctx context.Context, limit int64, offset int64, filter model.CocktailFilter) ([]model.Cocktail, error) {
+	ret := _m.Called(ctx, limit, offset, filter)
 
 	var r0 []model.Cocktail
-	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string) []model.Cocktail); ok {
-		r0 = rf(ctx, limit, offset, keyword)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, model.CocktailFilter) []model.Cocktail); ok {
+		r0 = rf(ctx, limit, offset, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]model.Cocktail)
@@ -72,8 +109,8 @@ func (_m *CocktailRepository) GetLimit(ctx context.Context, limit int64, offset
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, string) error); ok {
-		r1 = rf(ctx, limit, offset, keyword)
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, model.CocktailFilter) error); ok {
+		r1 = rf(ctx, limit, offset, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -104,6 +141,66 @@ func (_m *CocktailRepository) GetListByIDs(ctx context.Context, ids []int64) ([]
 	return r0, r1
 }
 
+// GetMakeable provides a mock function with given fields: ctx, materialIDs, materialNames, maxMissing
+func (_m *CocktailRepository) GetMakeable(ctx context.Context, materialIDs []int64, materialNames []string, maxMissing int64) ([]model.MakeableCocktail, error) {
+	ret := _m.Called(ctx, materialIDs, materialNames, maxMissing)
+
+	var r0 []model.MakeableCocktail
+	if rf, ok := ret.Get(0).(func(context.Context, []int64, []string, int64) []model.MakeableCocktail); ok {
+		r0 = rf(ctx, materialIDs, materialNames, maxMissing)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.MakeableCocktail)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []int64, []string, int64) error); ok {
+		r1 = rf(ctx, materialIDs, materialNames, maxMissing)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, id, params
+func (_m *CocktailRepository) Update(ctx context.Context, id int64, params model.CocktailParams) (*model.CocktailDetail, error) {
+	ret := _m.Called(ctx, id, params)
+
+	var r0 *model.CocktailDetail
+	if rf, ok := ret.Get(0).(func(context.Context, int64, model.CocktailParams) *model.CocktailDetail); ok {
+		r0 = rf(ctx, id, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CocktailDetail)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, model.CocktailParams) error); ok {
+		r1 = rf(ctx, id, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateImageURL provides a mock function with given fields: ctx, id, url
+func (_m *CocktailRepository) UpdateImageURL(ctx context.Context, id int64, url string) error {
+	ret := _m.Called(ctx, id, url)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, id, url)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewCocktailRepository interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,234 @@
+// Package search finds cocktails by their name, reading or materials, tolerating typos.
+package search
+
+import (
+	"math"
+	"sort"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/normalize"
+)
+
+const (
+	FieldName     = "name"
+	FieldReading  = "reading"
+	FieldMaterial = "material"
+)
+
+// weights rank a match on the name above the same match on a material.
+var weights = map[string]float64{
+	FieldName:     1,
+	FieldReading:  0.95,
+	FieldMaterial: 0.6,
+}
+
+// Index is an in-memory trigram index over cocktails. It is immutable once built, so it is safe for concurrent use.
+type Index struct {
+	entries []entry
+	grams   map[string][]int
+}
+
+type entry struct {
+	cocktail model.Cocktail
+	fields   []field
+}
+
+type field struct {
+	name  string
+	text  string
+	key   []rune
+	spans []normalize.Span
+}
+
+// NewIndex indexes the cocktails along with the names of their materials.
+func NewIndex(cocktails []model.CocktailDetail) *Index {
+	x := &Index{grams: map[string][]int{}}
+	for _, c := range cocktails {
+		e := entry{cocktail: model.Cocktail{
+			ID:        c.ID,
+			Name:      c.Name,
+			Reading:   c.Reading,
+			ImageURL:  c.ImageURL,
+			CreatedAt: c.CreatedAt,
+			UpdatedAt: c.UpdatedAt,
+		}}
+		e.addField(FieldName, c.Name)
+		e.addField(FieldReading, c.Reading)
+		for _, m := range c.Materials {
+			e.addField(FieldMaterial, m.Name)
+		}
+
+		pos := len(x.entries)
+		x.entries = append(x.entries, e)
+
+		seen := map[string]bool{}
+		for _, f := range e.fields {
+			for _, g := range trigrams(f.key) {
+				if !seen[g] {
+					seen[g] = true
+					x.grams[g] = append(x.grams[g], pos)
+				}
+			}
+		}
+	}
+	return x
+}
+
+func (e *entry) addField(name string, text string) {
+	key, spans := normalize.NameSpans(text)
+	if key == "" {
+		return
+	}
+	e.fields = append(e.fields, field{name: name, text: text, key: []rune(key), spans: spans})
+}
+
+// Search returns up to limit cocktails matching the query, best first.
+// A field matches when some part of it is within a few edits of the query, one edit per four characters.
+func (x *Index) Search(query string, limit int) []model.CocktailSearchResult {
+	q := []rune(normalize.Name(query))
+	results := []model.CocktailSearchResult{}
+	if len(q) == 0 {
+		return results
+	}
+
+	for _, pos := range x.candidates(q) {
+		e := x.entries[pos]
+
+		r := model.CocktailSearchResult{Cocktail: e.cocktail, Highlights: []model.SearchHighlight{}}
+		for _, f := range e.fields {
+			score, start, end, ok := matchField(q, f)
+			if !ok {
+				continue
+			}
+
+			r.Score = math.Max(r.Score, score)
+			r.Highlights = append(r.Highlights, model.SearchHighlight{
+				Field: f.name,
+				Text:  f.text,
+				Start: f.spans[start].Start,
+				End:   f.spans[end-1].End,
+			})
+		}
+		if len(r.Highlights) == 0 {
+			continue
+		}
+
+		r.Score = math.Round(r.Score*1000) / 1000
+		results = append(results, r)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Cocktail.ID < results[j].Cocktail.ID
+	})
+
+	if limit >= 0 && limit < len(results) {
+		results = results[:limit]
+	}
+	return results
+}
+
+// candidates returns the entries sharing a trigram with the query in index order.
+// Short queries, and queries whose every trigram was broken by typos, fall back to every entry.
+func (x *Index) candidates(q []rune) []int {
+	all := func() []int {
+		positions := make([]int, len(x.entries))
+		for i := range positions {
+			positions[i] = i
+		}
+		return positions
+	}
+
+	if len(q) < 5 {
+		return all()
+	}
+
+	seen := map[int]bool{}
+	var positions []int
+	for _, g := range trigrams(q) {
+		for _, pos := range x.grams[g] {
+			if !seen[pos] {
+				seen[pos] = true
+				positions = append(positions, pos)
+			}
+		}
+	}
+	if len(positions) == 0 {
+		return all()
+	}
+
+	sort.Ints(positions)
+	return positions
+}
+
+// matchField scores the best match of the query within the field and returns the runes [start, end) of its key which matched.
+func matchField(q []rune, f field) (float64, int, int, bool) {
+	distance, start, end := substringDistance(q, f.key)
+	if distance > len(q)/4 || end == start {
+		return 0, 0, 0, false
+	}
+
+	var score float64
+	switch {
+	case distance == 0 && start == 0 && end == len(f.key):
+		score = 1
+	case distance == 0 && start == 0:
+		score = 0.95
+	case distance == 0:
+		score = 0.9
+	default:
+		score = 0.8 * (1 - float64(distance)/float64(len(q)))
+	}
+
+	return score * weights[f.name], start, end, true
+}
+
+// substringDistance finds the part [start, end) of text with the fewest edits from q and returns that number of edits.
+func substringDistance(q []rune, text []rune) (int, int, int) {
+	// prev[j] and cur[j] hold the edits of the best match of q[:i] ending at text[j], starting at from[j]
+	prev := make([]int, len(text)+1)
+	cur := make([]int, len(text)+1)
+	prevFrom := make([]int, len(text)+1)
+	curFrom := make([]int, len(text)+1)
+	for j := range prev {
+		prevFrom[j] = j
+	}
+
+	for i := 1; i <= len(q); i++ {
+		cur[0], curFrom[0] = i, 0
+		for j := 1; j <= len(text); j++ {
+			cost := 1
+			if q[i-1] == text[j-1] {
+				cost = 0
+			}
+
+			cur[j], curFrom[j] = prev[j-1]+cost, prevFrom[j-1]
+			if prev[j]+1 < cur[j] {
+				cur[j], curFrom[j] = prev[j]+1, prevFrom[j]
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j], curFrom[j] = cur[j-1]+1, curFrom[j-1]
+			}
+		}
+		prev, cur = cur, prev
+		prevFrom, curFrom = curFrom, prevFrom
+	}
+
+	best := 0
+	for j := 1; j <= len(text); j++ {
+		if prev[j] < prev[best] {
+			best = j
+		}
+	}
+	return prev[best], prevFrom[best], best
+}
+
+func trigrams(key []rune) []string {
+	var grams []string
+	for i := 0; i+3 <= len(key); i++ {
+		grams = append(grams, string(key[i:i+3]))
+	}
+	return grams
+}
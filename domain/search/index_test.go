@@ -0,0 +1,71 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newIndex() *Index {
+	return NewIndex([]model.CocktailDetail{
+		{ID: 1, Name: "Margarita", Materials: []model.Material{{Name: "テキーラ"}, {Name: "ホワイトキュラソー"}, {Name: "ライムジュース"}}},
+		{ID: 2, Name: "Frozen Margarita", Materials: []model.Material{{Name: "テキーラ"}, {Name: "ライムジュース"}}},
+		{ID: 3, Name: "スコッチ・オーレ", Materials: []model.Material{{Name: "スコッチ"}, {Name: "牛乳"}}},
+		{ID: 4, Name: "雪国", Reading: "ゆきぐに", Materials: []model.Material{{Name: "ウォッカ"}, {Name: "ホワイトキュラソー"}}},
+	})
+}
+
+func TestSearch(t *testing.T) {
+	x := newIndex()
+
+	type testcase struct {
+		Name  string
+		Query string
+		Want  []int64
+	}
+
+	tests := []testcase{
+		{Name: "exact name first", Query: "margarita", Want: []int64{1, 2}},
+		{Name: "typo", Query: "margerita", Want: []int64{1, 2}},
+		{Name: "kana", Query: "すこっち", Want: []int64{3}},
+		{Name: "reading", Query: "ゆきくに", Want: []int64{4}},
+		{Name: "material", Query: "ﾃｷｰﾗ", Want: []int64{1, 2}},
+		{Name: "too many typos", Query: "mojito", Want: []int64{}},
+		{Name: "empty", Query: " ", Want: []int64{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			ids := []int64{}
+			for _, r := range x.Search(tc.Query, 10) {
+				ids = append(ids, r.Cocktail.ID)
+			}
+
+			assert.Equal(t, tc.Want, ids)
+		})
+	}
+}
+
+func TestSearchHighlights(t *testing.T) {
+	x := newIndex()
+
+	results := x.Search("ﾎﾜｲﾄ ｷｭﾗｿ", 1)
+
+	assert.Len(t, results, 1)
+	assert.Equal(t, int64(1), results[0].Cocktail.ID)
+	assert.Equal(t, []model.SearchHighlight{{Field: FieldMaterial, Text: "ホワイトキュラソー", Start: 0, End: 8}}, results[0].Highlights)
+
+	results = x.Search("margerita", 1)
+
+	assert.Equal(t, 0.711, results[0].Score)
+	assert.Equal(t, []model.SearchHighlight{{Field: FieldName, Text: "Margarita", Start: 0, End: 9}}, results[0].Highlights)
+}
+
+func TestSubstringDistance(t *testing.T) {
+	distance, start, end := substringDistance([]rune("マルガリ"), []rune("フローズンマルガリータ"))
+
+	assert.Equal(t, 0, distance)
+	assert.Equal(t, 5, start)
+	assert.Equal(t, 9, end)
+}
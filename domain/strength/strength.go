@@ -0,0 +1,112 @@
+// Package strength estimates how strong a cocktail is once it has been mixed.
+package strength
+
+import (
+	"math"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/units"
+)
+
+// gramsPerDrink is the pure alcohol in one standard drink, following the 10g used in Japan.
+const gramsPerDrink = 10
+
+// ethanolDensity is the weight of a millilitre of ethanol in grams.
+const ethanolDensity = 0.789
+
+// Strength is how strong one serving of a cocktail is.
+type Strength struct {
+	// ABV is the alcohol by volume of the mixed drink in percent.
+	ABV float64
+	// StandardDrinks is the pure alcohol of the serving counted in standard drinks.
+	StandardDrinks float64
+}
+
+// Dilution returns the water melted from the ice while mixing, as a ratio to the volume of the materials.
+// Shaken and stirred drinks follow the curves measured in Dave Arnold's "Liquid Intelligence",
+// where a stronger mix melts more ice; abv is the strength of the materials before mixing, in percent.
+func Dilution(method model.Method, abv float64) float64 {
+	a := abv / 100
+	switch method {
+	case model.MethodShake:
+		return 1.567*a*a + 1.742*a + 0.203
+	case model.MethodStir:
+		return -1.21*a*a + 1.246*a + 0.145
+	case model.MethodBuild:
+		return 0.1
+	case model.MethodBlend:
+		return 1
+	}
+	return 0
+}
+
+// DefaultDilution returns the dilution Dilution would for a typical drink made by the method, for a mix whose
+// strength is unknown. Shaken and stirred drinks take on about a quarter and a fifth of their volume in water.
+func DefaultDilution(method model.Method) float64 {
+	switch method {
+	case model.MethodShake:
+		return 0.25
+	case model.MethodStir:
+		return 0.2
+	}
+	return Dilution(method, 0)
+}
+
+// Of estimates the strength of a cocktail made by the method from the materials.
+// Materials without an ABV are counted as non-alcoholic, and the ones not measured by volume,
+// like a slice of lemon or "適量", do not count towards the volume. ok is false when none of the measurable
+// materials has an ABV, since the strength would then be a guess.
+func Of(method model.Method, materials []model.Material) (s Strength, ok bool) {
+	volume, alcohol, ok := mix(materials)
+	if !ok {
+		return Strength{}, false
+	}
+
+	diluted := volume * (1 + Dilution(method, alcohol/volume*100))
+	return Strength{
+		ABV:            round(alcohol/diluted*100, 1),
+		StandardDrinks: round(alcohol*ethanolDensity/gramsPerDrink, 2),
+	}, true
+}
+
+// Water returns the millilitres of water the ice melts into one serving made by the method.
+// ok is false when it cannot be estimated, for the same reasons as Of.
+func Water(method model.Method, materials []model.Material) (ml float64, ok bool) {
+	volume, alcohol, ok := mix(materials)
+	if !ok {
+		return 0, false
+	}
+	return volume * Dilution(method, alcohol/volume*100), true
+}
+
+// mix adds up the millilitres of the materials measured by volume and of the alcohol in them.
+func mix(materials []model.Material) (volume, alcohol float64, ok bool) {
+	for _, m := range materials {
+		ml, d, err := units.Canonical(m.Quantity)
+		if err != nil || d != units.Volume {
+			continue
+		}
+
+		volume += ml
+		if m.ABV != nil {
+			alcohol += ml * *m.ABV / 100
+			ok = true
+		}
+	}
+	return volume, alcohol, ok && volume > 0
+}
+
+// Fill sets the estimated strength of the cocktail, leaving it nil when it cannot be estimated.
+func Fill(d *model.CocktailDetail) {
+	s, ok := Of(d.Method, d.Materials)
+	if !ok {
+		d.ABV, d.StandardDrinks = nil, nil
+		return
+	}
+	d.ABV, d.StandardDrinks = &s.ABV, &s.StandardDrinks
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,89 @@
+package strength
+
+import (
+	"testing"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func abv(v float64) *float64 {
+	return &v
+}
+
+func material(name string, a *float64, quantity float64, unit string) model.Material {
+	return model.Material{Name: name, ABV: a, Quantity: model.MaterialQuantity{Quantity: quantity, Unit: unit}}
+}
+
+func TestOf(t *testing.T) {
+	type testcase struct {
+		Name      string
+		Method    model.Method
+		Materials []model.Material
+		Want      Strength
+		WantOK    bool
+	}
+
+	tests := []testcase{
+		{
+			Name:   "built",
+			Method: model.MethodBuild,
+			Materials: []model.Material{
+				material("カルーア", abv(20), 45, "ml"),
+				material("牛乳", nil, 90, "ml"),
+			},
+			Want:   Strength{ABV: 6.1, StandardDrinks: 0.71},
+			WantOK: true,
+		},
+		{
+			Name:   "stirred",
+			Method: model.MethodStir,
+			Materials: []model.Material{
+				material("ジン", abv(40), 5, "cl"),
+				material("ドライ・ベルモット", abv(18), 10, "ml"),
+				material("オリーブ", nil, 1, "個"),
+			},
+			Want:   Strength{ABV: 25.3, StandardDrinks: 1.72},
+			WantOK: true,
+		},
+		{
+			Name:      "no abv known",
+			Method:    model.MethodShake,
+			Materials: []model.Material{material("ジン", nil, 45, "ml")},
+		},
+		{
+			Name:      "nothing measurable",
+			Materials: []model.Material{material("ジン", abv(40), 1, "適量")},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			s, ok := Of(tc.Method, tc.Materials)
+
+			assert.Equal(t, tc.WantOK, ok)
+			assert.Equal(t, tc.Want, s)
+		})
+	}
+}
+
+func TestDilution(t *testing.T) {
+	// a strong mix melts more ice when shaken, and shaking always melts more than stirring
+	assert.Greater(t, Dilution(model.MethodShake, 30), Dilution(model.MethodShake, 10))
+	assert.Greater(t, Dilution(model.MethodShake, 30), Dilution(model.MethodStir, 30))
+	assert.Equal(t, 0.0, Dilution("", 30))
+}
+
+func TestWater(t *testing.T) {
+	martini := []model.Material{
+		material("ジン", abv(40), 5, "cl"),
+		material("ドライ・ベルモット", abv(18), 10, "ml"),
+	}
+
+	ml, ok := Water(model.MethodStir, martini)
+	assert.True(t, ok)
+	assert.InDelta(t, 26.3, ml, 0.05)
+
+	_, ok = Water(model.MethodStir, []model.Material{material("ジン", nil, 45, "ml")})
+	assert.False(t, ok)
+}
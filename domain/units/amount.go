@@ -0,0 +1,91 @@
+package units
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var vulgarFractions = map[rune]float64{
+	'½': 1.0 / 2,
+	'⅓': 1.0 / 3,
+	'⅔': 2.0 / 3,
+	'¼': 1.0 / 4,
+	'¾': 3.0 / 4,
+	'⅛': 1.0 / 8,
+	'⅜': 3.0 / 8,
+	'⅝': 5.0 / 8,
+	'⅞': 7.0 / 8,
+}
+
+// ParseAmount reads an amount the way recipes write it: "1.5", "1/2", "1 1/2", "½" or "1½".
+func ParseAmount(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+
+	var amount float64
+	var err error
+	if r, size := utf8.DecodeLastRuneInString(s); vulgarFractions[r] != 0 {
+		amount, err = parseWhole(strings.TrimSpace(s[:len(s)-size]))
+		amount += vulgarFractions[r]
+	} else {
+		switch fields := strings.Fields(s); len(fields) {
+		case 1:
+			amount, err = parseFraction(fields[0])
+		case 2:
+			if !strings.Contains(fields[1], "/") {
+				return 0, fmt.Errorf("invalid amount %q", s)
+			}
+			var whole, fraction float64
+			whole, err = parseWhole(fields[0])
+			if err == nil {
+				fraction, err = parseFraction(fields[1])
+			}
+			amount = whole + fraction
+		default:
+			err = fmt.Errorf("invalid amount %q", s)
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(amount) || math.IsInf(amount, 0) || amount < 0 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return amount, nil
+}
+
+// parseWhole reads the whole part in front of a fraction, which may be left out.
+func parseWhole(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return float64(n), nil
+}
+
+// parseFraction reads "3/4" or a plain number like "0.75".
+func parseFraction(s string) (float64, error) {
+	numerator, denominator, ok := strings.Cut(s, "/")
+	if !ok {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+		return f, nil
+	}
+
+	n, err := strconv.ParseUint(numerator, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	d, err := strconv.ParseUint(denominator, 10, 32)
+	if err != nil || d == 0 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return float64(n) / float64(d), nil
+}
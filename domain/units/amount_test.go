@@ -0,0 +1,38 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAmount(t *testing.T) {
+	type testcase struct {
+		Input   string
+		Want    float64
+		WantErr bool
+	}
+
+	tests := []testcase{
+		{Input: "30", Want: 30},
+		{Input: "1.5", Want: 1.5},
+		{Input: "1/2", Want: 0.5},
+		{Input: " 1 1/2 ", Want: 1.5},
+		{Input: "¾", Want: 0.75},
+		{Input: "1½", Want: 1.5},
+		{Input: "1/0", WantErr: true},
+		{Input: "1 1.5", WantErr: true},
+		{Input: "-1", WantErr: true},
+		{Input: "NaN", WantErr: true},
+		{Input: "", WantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Input, func(t *testing.T) {
+			amount, err := ParseAmount(tc.Input)
+
+			assert.Equal(t, tc.WantErr, err != nil)
+			assert.Equal(t, tc.Want, amount)
+		})
+	}
+}
@@ -0,0 +1,246 @@
+// Package units converts the quantities materials are measured in, so "30 ml", "1 oz" and "1 tsp"
+// can be compared and added up.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/normalize"
+)
+
+var (
+	ErrUnknownUnit  = errors.New("unknown unit")
+	ErrIncompatible = errors.New("units measure different things")
+)
+
+// Dimension is what a unit measures.
+type Dimension string
+
+const (
+	Volume Dimension = "volume"
+	Mass   Dimension = "mass"
+)
+
+// System is a set of units a recipe is written in.
+type System string
+
+const (
+	Metric   System = "metric"
+	Imperial System = "imperial"
+)
+
+// ParseSystem reads the name of a unit system. An empty name means the units the recipe was written in.
+func ParseSystem(name string) (System, error) {
+	switch s := System(name); s {
+	case "", Metric, Imperial:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown unit system %q", name)
+}
+
+// Unit is a unit a quantity can be measured in.
+type Unit struct {
+	Symbol    string
+	Dimension Dimension
+	// Size is the unit in millilitres for volumes and in grams for masses.
+	Size float64
+}
+
+// An ounce follows the 30 ml bars pour rather than the 29.57 ml US fluid ounce.
+var (
+	Millilitre = Unit{Symbol: "ml", Dimension: Volume, Size: 1}
+	Centilitre = Unit{Symbol: "cl", Dimension: Volume, Size: 10}
+	Litre      = Unit{Symbol: "l", Dimension: Volume, Size: 1000}
+	Ounce      = Unit{Symbol: "oz", Dimension: Volume, Size: 30}
+	Teaspoon   = Unit{Symbol: "tsp", Dimension: Volume, Size: 5}
+	BarSpoon   = Unit{Symbol: "bsp", Dimension: Volume, Size: 5}
+	Tablespoon = Unit{Symbol: "tbsp", Dimension: Volume, Size: 15}
+	Cup        = Unit{Symbol: "cup", Dimension: Volume, Size: 240}
+	Dash       = Unit{Symbol: "dash", Dimension: Volume, Size: 1}
+	Drop       = Unit{Symbol: "drop", Dimension: Volume, Size: 0.2}
+	Gram       = Unit{Symbol: "g", Dimension: Mass, Size: 1}
+	Kilogram   = Unit{Symbol: "kg", Dimension: Mass, Size: 1000}
+)
+
+var spellings = []struct {
+	unit  Unit
+	names []string
+}{
+	{Millilitre, []string{"ml", "milliliter", "millilitre", "cc", "ミリリットル"}},
+	{Centilitre, []string{"cl", "centiliter", "centilitre"}},
+	{Litre, []string{"l", "liter", "litre", "リットル"}},
+	{Ounce, []string{"oz", "fl oz", "fl.oz", "ounce", "ounces", "オンス"}},
+	{Teaspoon, []string{"tsp", "teaspoon", "teaspoons", "ティースプーン", "小さじ"}},
+	{BarSpoon, []string{"bsp", "bar spoon", "bar spoons", "バースプーン"}},
+	{Tablespoon, []string{"tbsp", "tablespoon", "tablespoons", "大さじ"}},
+	{Cup, []string{"cup", "cups", "カップ"}},
+	{Dash, []string{"dash", "dashes", "ダッシュ"}},
+	{Drop, []string{"drop", "drops", "ドロップ", "滴"}},
+	{Gram, []string{"g", "gram", "grams", "グラム"}},
+	{Kilogram, []string{"kg", "kilogram", "kilograms", "キログラム"}},
+}
+
+// byName looks the units up by their spellings once normalized.
+var byName = map[string]Unit{}
+
+func init() {
+	for _, s := range spellings {
+		for _, name := range s.names {
+			byName[normalize.Name(name)] = s.unit
+		}
+	}
+}
+
+// Lookup returns the unit written as name, ignoring case and width.
+// ok is false for counts and vague amounts like "個", "slice" or "適量".
+func Lookup(name string) (u Unit, ok bool) {
+	u, ok = byName[normalize.Name(name)]
+	return u, ok
+}
+
+// Canonical returns the quantity in millilitres for volumes or in grams for masses.
+func Canonical(q model.MaterialQuantity) (amount float64, d Dimension, err error) {
+	u, ok := Lookup(q.Unit)
+	if !ok {
+		return 0, "", fmt.Errorf("%w: %q", ErrUnknownUnit, q.Unit)
+	}
+	return q.Quantity * u.Size, u.Dimension, nil
+}
+
+// Convert expresses the quantity in the unit.
+func Convert(q model.MaterialQuantity, to Unit) (model.MaterialQuantity, error) {
+	amount, d, err := Canonical(q)
+	if err != nil {
+		return model.MaterialQuantity{}, err
+	}
+	if d != to.Dimension {
+		return model.MaterialQuantity{}, fmt.Errorf("%w: %s and %s", ErrIncompatible, q.Unit, to.Symbol)
+	}
+	return model.MaterialQuantity{Quantity: amount / to.Size, Unit: to.Symbol}, nil
+}
+
+// Add adds b to a in the unit of a. A quantity without an amount, like "適量", adds nothing,
+// and the same unit adds up even when it is unknown.
+func Add(a, b model.MaterialQuantity) (model.MaterialQuantity, error) {
+	switch {
+	case b.Quantity == 0:
+		return a, nil
+	case a.Quantity == 0:
+		return b, nil
+	case normalize.Name(a.Unit) == normalize.Name(b.Unit):
+		return model.MaterialQuantity{Quantity: a.Quantity + b.Quantity, Unit: a.Unit}, nil
+	}
+
+	u, ok := Lookup(a.Unit)
+	if !ok {
+		return model.MaterialQuantity{}, fmt.Errorf("%w: %q", ErrUnknownUnit, a.Unit)
+	}
+	converted, err := Convert(b, u)
+	if err != nil {
+		return model.MaterialQuantity{}, err
+	}
+	return model.MaterialQuantity{Quantity: a.Quantity + converted.Quantity, Unit: a.Unit}, nil
+}
+
+// Sub takes b out of a in the unit of a, so the result is negative when b is more than a.
+// Unlike Add, quantities are compared even when one of them is zero, and only the same unit
+// is taken out when it is unknown.
+func Sub(a, b model.MaterialQuantity) (model.MaterialQuantity, error) {
+	if normalize.Name(a.Unit) == normalize.Name(b.Unit) {
+		return model.MaterialQuantity{Quantity: a.Quantity - b.Quantity, Unit: a.Unit}, nil
+	}
+
+	amountA, da, err := Canonical(a)
+	if err != nil {
+		return model.MaterialQuantity{}, err
+	}
+	amountB, db, err := Canonical(b)
+	if err != nil {
+		return model.MaterialQuantity{}, err
+	}
+	if da != db {
+		return model.MaterialQuantity{}, fmt.Errorf("%w: %s and %s", ErrIncompatible, a.Unit, b.Unit)
+	}
+	u, _ := Lookup(a.Unit)
+	return model.MaterialQuantity{Quantity: (amountA - amountB) / u.Size, Unit: a.Unit}, nil
+}
+
+// In renders the quantity in the system, rounded to two decimal places.
+// Quantities in unknown units are left as they are, and so are dashes and drops, which bartenders
+// use whatever the system. Imperial recipes measure less than a quarter ounce in teaspoons,
+// and keep masses in grams since ounces would read as fluid ounces.
+func In(q model.MaterialQuantity, s System) model.MaterialQuantity {
+	u, ok := Lookup(q.Unit)
+	if !ok || s == "" || u == Dash || u == Drop {
+		return q
+	}
+
+	converted, err := Convert(q, target(u, q.Quantity*u.Size, s))
+	if err != nil {
+		return q
+	}
+	converted.Quantity = math.Round(converted.Quantity*100) / 100
+	return converted
+}
+
+// target returns the unit of the system to render amount millilitres or grams of u in.
+func target(u Unit, amount float64, s System) Unit {
+	switch {
+	case u.Dimension == Mass:
+		return Gram
+	case s == Metric:
+		return Millilitre
+	case amount < Ounce.Size/4:
+		return Teaspoon
+	}
+	return Ounce
+}
+
+// Practical rounds the quantity to what can be measured out behind a bar: whole millilitres, or five
+// for a hundred and more, quarter ounces, half spoons and whole dashes. Dashes and drops adding up
+// to a teaspoon or more are measured by volume in the system instead, in millilitres when it is empty.
+// An amount is never rounded down to nothing, and quantities in unknown units are left as they are.
+func Practical(q model.MaterialQuantity, s System) model.MaterialQuantity {
+	u, ok := Lookup(q.Unit)
+	if !ok {
+		return q
+	}
+
+	if (u == Dash || u == Drop) && q.Quantity*u.Size >= Teaspoon.Size {
+		if s == "" {
+			s = Metric
+		}
+		to := target(u, q.Quantity*u.Size, s)
+		q, u = model.MaterialQuantity{Quantity: q.Quantity * u.Size / to.Size, Unit: to.Symbol}, to
+	}
+
+	step := stepOf(u, q.Quantity*u.Size)
+	rounded := math.Round(q.Quantity/step) * step
+	if rounded == 0 && q.Quantity > 0 {
+		rounded = step
+	}
+	q.Quantity = math.Round(rounded*100) / 100
+	return q
+}
+
+// stepOf returns the smallest amount of the unit measured out for a quantity of amount
+// millilitres or grams.
+func stepOf(u Unit, amount float64) float64 {
+	switch u {
+	case Millilitre, Gram:
+		if amount >= 100 {
+			return 5
+		}
+		return 1
+	case Centilitre, Teaspoon, BarSpoon, Tablespoon:
+		return 0.5
+	case Ounce, Cup:
+		return 0.25
+	case Litre, Kilogram:
+		return 0.05
+	}
+	return 1
+}
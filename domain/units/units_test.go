@@ -0,0 +1,161 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonical(t *testing.T) {
+	type testcase struct {
+		Name          string
+		Input         model.MaterialQuantity
+		WantAmount    float64
+		WantDimension Dimension
+		WantErr       error
+	}
+
+	tests := []testcase{
+		{Name: "millilitres", Input: model.MaterialQuantity{Quantity: 45, Unit: "ml"}, WantAmount: 45, WantDimension: Volume},
+		{Name: "ounces", Input: model.MaterialQuantity{Quantity: 1.5, Unit: "oz"}, WantAmount: 45, WantDimension: Volume},
+		{Name: "full-width spelling", Input: model.MaterialQuantity{Quantity: 2, Unit: "ｔｓｐ"}, WantAmount: 10, WantDimension: Volume},
+		{Name: "japanese spelling", Input: model.MaterialQuantity{Quantity: 2, Unit: "ダッシュ"}, WantAmount: 2, WantDimension: Volume},
+		{Name: "mass", Input: model.MaterialQuantity{Quantity: 0.5, Unit: "kg"}, WantAmount: 500, WantDimension: Mass},
+		{Name: "count", Input: model.MaterialQuantity{Quantity: 1, Unit: "個"}, WantErr: ErrUnknownUnit},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			amount, d, err := Canonical(tc.Input)
+
+			assert.ErrorIs(t, err, tc.WantErr)
+			assert.Equal(t, tc.WantAmount, amount)
+			assert.Equal(t, tc.WantDimension, d)
+		})
+	}
+}
+
+func TestConvertIncompatible(t *testing.T) {
+	_, err := Convert(model.MaterialQuantity{Quantity: 10, Unit: "g"}, Millilitre)
+
+	assert.ErrorIs(t, err, ErrIncompatible)
+}
+
+func TestAdd(t *testing.T) {
+	type testcase struct {
+		Name    string
+		A       model.MaterialQuantity
+		B       model.MaterialQuantity
+		Want    model.MaterialQuantity
+		WantErr error
+	}
+
+	tests := []testcase{
+		{Name: "same unit", A: model.MaterialQuantity{Quantity: 30, Unit: "ml"}, B: model.MaterialQuantity{Quantity: 15, Unit: "ml"}, Want: model.MaterialQuantity{Quantity: 45, Unit: "ml"}},
+		{Name: "converted", A: model.MaterialQuantity{Quantity: 30, Unit: "ml"}, B: model.MaterialQuantity{Quantity: 1, Unit: "oz"}, Want: model.MaterialQuantity{Quantity: 60, Unit: "ml"}},
+		{Name: "same unknown unit", A: model.MaterialQuantity{Quantity: 1, Unit: "個"}, B: model.MaterialQuantity{Quantity: 2, Unit: "個"}, Want: model.MaterialQuantity{Quantity: 3, Unit: "個"}},
+		{Name: "no amount", A: model.MaterialQuantity{Unit: "適量"}, B: model.MaterialQuantity{Quantity: 2, Unit: "dash"}, Want: model.MaterialQuantity{Quantity: 2, Unit: "dash"}},
+		{Name: "unknown unit", A: model.MaterialQuantity{Quantity: 1, Unit: "個"}, B: model.MaterialQuantity{Quantity: 15, Unit: "ml"}, WantErr: ErrUnknownUnit},
+		{Name: "incompatible", A: model.MaterialQuantity{Quantity: 10, Unit: "g"}, B: model.MaterialQuantity{Quantity: 15, Unit: "ml"}, WantErr: ErrIncompatible},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			got, err := Add(tc.A, tc.B)
+
+			assert.ErrorIs(t, err, tc.WantErr)
+			assert.Equal(t, tc.Want, got)
+		})
+	}
+}
+
+func TestSub(t *testing.T) {
+	type testcase struct {
+		Name    string
+		A       model.MaterialQuantity
+		B       model.MaterialQuantity
+		Want    model.MaterialQuantity
+		WantErr error
+	}
+
+	tests := []testcase{
+		{Name: "same unit", A: model.MaterialQuantity{Quantity: 30, Unit: "ml"}, B: model.MaterialQuantity{Quantity: 45, Unit: "ML"}, Want: model.MaterialQuantity{Quantity: -15, Unit: "ml"}},
+		{Name: "converted", A: model.MaterialQuantity{Quantity: 2, Unit: "oz"}, B: model.MaterialQuantity{Quantity: 45, Unit: "ml"}, Want: model.MaterialQuantity{Quantity: 0.5, Unit: "oz"}},
+		{Name: "same unknown unit", A: model.MaterialQuantity{Quantity: 3, Unit: "個"}, B: model.MaterialQuantity{Quantity: 1, Unit: "個"}, Want: model.MaterialQuantity{Quantity: 2, Unit: "個"}},
+		{Name: "nothing left", A: model.MaterialQuantity{Unit: "g"}, B: model.MaterialQuantity{Quantity: 15, Unit: "ml"}, WantErr: ErrIncompatible},
+		{Name: "unknown unit", A: model.MaterialQuantity{Quantity: 1, Unit: "個"}, B: model.MaterialQuantity{Quantity: 15, Unit: "ml"}, WantErr: ErrUnknownUnit},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			got, err := Sub(tc.A, tc.B)
+
+			assert.ErrorIs(t, err, tc.WantErr)
+			assert.Equal(t, tc.Want, got)
+		})
+	}
+}
+
+func TestIn(t *testing.T) {
+	type testcase struct {
+		Name   string
+		Input  model.MaterialQuantity
+		System System
+		Want   model.MaterialQuantity
+	}
+
+	tests := []testcase{
+		{Name: "as written", Input: model.MaterialQuantity{Quantity: 1, Unit: "oz"}, Want: model.MaterialQuantity{Quantity: 1, Unit: "oz"}},
+		{Name: "ounces to metric", Input: model.MaterialQuantity{Quantity: 1.5, Unit: "oz"}, System: Metric, Want: model.MaterialQuantity{Quantity: 45, Unit: "ml"}},
+		{Name: "centilitres to metric", Input: model.MaterialQuantity{Quantity: 2, Unit: "cl"}, System: Metric, Want: model.MaterialQuantity{Quantity: 20, Unit: "ml"}},
+		{Name: "millilitres to imperial", Input: model.MaterialQuantity{Quantity: 20, Unit: "ml"}, System: Imperial, Want: model.MaterialQuantity{Quantity: 0.67, Unit: "oz"}},
+		{Name: "small amount to imperial", Input: model.MaterialQuantity{Quantity: 5, Unit: "ml"}, System: Imperial, Want: model.MaterialQuantity{Quantity: 1, Unit: "tsp"}},
+		{Name: "dashes are kept", Input: model.MaterialQuantity{Quantity: 2, Unit: "dash"}, System: Metric, Want: model.MaterialQuantity{Quantity: 2, Unit: "dash"}},
+		{Name: "tablespoons to imperial", Input: model.MaterialQuantity{Quantity: 1, Unit: "tbsp"}, System: Imperial, Want: model.MaterialQuantity{Quantity: 0.5, Unit: "oz"}},
+		{Name: "grams stay in imperial", Input: model.MaterialQuantity{Quantity: 0.2, Unit: "kg"}, System: Imperial, Want: model.MaterialQuantity{Quantity: 200, Unit: "g"}},
+		{Name: "unknown units are kept", Input: model.MaterialQuantity{Quantity: 1, Unit: "適量"}, System: Imperial, Want: model.MaterialQuantity{Quantity: 1, Unit: "適量"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Want, In(tc.Input, tc.System))
+		})
+	}
+}
+
+func TestParseSystem(t *testing.T) {
+	s, err := ParseSystem("imperial")
+	assert.Nil(t, err)
+	assert.Equal(t, Imperial, s)
+
+	_, err = ParseSystem("us")
+	assert.NotNil(t, err)
+}
+
+func TestPractical(t *testing.T) {
+	type testcase struct {
+		Name   string
+		Input  model.MaterialQuantity
+		System System
+		Want   model.MaterialQuantity
+	}
+
+	tests := []testcase{
+		{Name: "small millilitres", Input: model.MaterialQuantity{Quantity: 22.4, Unit: "ml"}, Want: model.MaterialQuantity{Quantity: 22, Unit: "ml"}},
+		{Name: "large millilitres", Input: model.MaterialQuantity{Quantity: 263.2, Unit: "ml"}, Want: model.MaterialQuantity{Quantity: 265, Unit: "ml"}},
+		{Name: "quarter ounces", Input: model.MaterialQuantity{Quantity: 6.67, Unit: "oz"}, Want: model.MaterialQuantity{Quantity: 6.75, Unit: "oz"}},
+		{Name: "half spoons", Input: model.MaterialQuantity{Quantity: 1.2, Unit: "tsp"}, Want: model.MaterialQuantity{Quantity: 1, Unit: "tsp"}},
+		{Name: "never nothing", Input: model.MaterialQuantity{Quantity: 0.1, Unit: "oz"}, Want: model.MaterialQuantity{Quantity: 0.25, Unit: "oz"}},
+		{Name: "few dashes", Input: model.MaterialQuantity{Quantity: 2.5, Unit: "dash"}, Want: model.MaterialQuantity{Quantity: 3, Unit: "dash"}},
+		{Name: "many dashes", Input: model.MaterialQuantity{Quantity: 20, Unit: "dash"}, Want: model.MaterialQuantity{Quantity: 20, Unit: "ml"}},
+		{Name: "many dashes in imperial", Input: model.MaterialQuantity{Quantity: 20, Unit: "dash"}, System: Imperial, Want: model.MaterialQuantity{Quantity: 0.75, Unit: "oz"}},
+		{Name: "unknown units", Input: model.MaterialQuantity{Quantity: 2.5, Unit: "個"}, Want: model.MaterialQuantity{Quantity: 2.5, Unit: "個"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Want, Practical(tc.Input, tc.System))
+		})
+	}
+}
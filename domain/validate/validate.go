@@ -0,0 +1,103 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/shake551/cocktails-api/domain/errs"
+)
+
+var v = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	// report fields by the name clients send them with
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return strings.ToLower(f.Name)
+		}
+		return name
+	})
+
+	v.RegisterValidation("kana", isKana)
+
+	return v
+}
+
+// isKana accepts readings written in hiragana or katakana, with long vowel marks, middle dots and spaces.
+func isKana(fl validator.FieldLevel) bool {
+	for _, r := range fl.Field().String() {
+		if !unicode.In(r, unicode.Hiragana, unicode.Katakana) && !strings.ContainsRune("ーｰ・･", r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Struct checks the `validate` tags of s.
+// A failure is returned as a validation error listing every invalid field.
+func Struct(s interface{}) error {
+	err := v.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	var ves validator.ValidationErrors
+	if !errors.As(err, &ves) {
+		return err
+	}
+
+	fields := make([]errs.FieldError, 0, len(ves))
+	for _, fe := range ves {
+		fields = append(fields, errs.FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Message: message(fe),
+		})
+	}
+	return errs.InvalidFields(fields...)
+}
+
+// fieldPath drops the struct name from a namespace like "CocktailParams.materials[0].name".
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "must not be empty"
+	case "min":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at least %s items", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s characters", fe.Param())
+	case "max":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at most %s items", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s characters", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be %s or more", fe.Param())
+	case "lte":
+		return fmt.Sprintf("must be %s or less", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "kana":
+		return "must be written in hiragana or katakana"
+	}
+	return fmt.Sprintf("failed on the %s rule", fe.Tag())
+}
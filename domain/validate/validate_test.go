@@ -0,0 +1,96 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shake551/cocktails-api/domain/errs"
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStruct(t *testing.T) {
+	rate := int64(120)
+
+	type testcase struct {
+		Name  string
+		Input interface{}
+		Want  []errs.FieldError
+	}
+
+	tests := []testcase{
+		{
+			Name: "valid cocktail",
+			Input: model.CocktailParams{
+				Name:      "カルーアミルク",
+				Reading:   "かるーあ・ミルク",
+				Materials: []model.MaterialParams{{Name: "カルーア", Quantity: model.MaterialQuantity{Quantity: 45, Unit: "ml"}}},
+			},
+		},
+		{
+			Name: "cocktail",
+			Input: model.CocktailParams{
+				Name:      strings.Repeat("あ", 129),
+				Reading:   "Kahlua",
+				Materials: []model.MaterialParams{{Name: "", Quantity: model.MaterialQuantity{Quantity: -1, Unit: "ml"}}},
+			},
+			Want: []errs.FieldError{
+				{Field: "name", Message: "must be at most 128 characters"},
+				{Field: "reading", Message: "must be written in hiragana or katakana"},
+				{Field: "materials[0].name", Message: "must not be empty"},
+				{Field: "materials[0].quantity.quantity", Message: "must be 0 or more"},
+			},
+		},
+		{
+			Name:  "cocktail without materials",
+			Input: model.CocktailParams{Name: "カルーアミルク"},
+			Want:  []errs.FieldError{{Field: "materials", Message: "must not be empty"}},
+		},
+		{
+			Name: "cocktail recipe",
+			Input: model.CocktailParams{
+				Name:      "カルーアミルク",
+				Materials: []model.MaterialParams{{Name: "カルーア", Quantity: model.MaterialQuantity{Quantity: 45, Unit: "ml"}}},
+				Method:    "throw",
+				Glass:     strings.Repeat("あ", 65),
+				Steps:     []string{"氷を入れたグラスに注ぐ", ""},
+			},
+			Want: []errs.FieldError{
+				{Field: "method", Message: "must be one of shake, stir, build, blend"},
+				{Field: "glass", Message: "must be at most 64 characters"},
+				{Field: "steps[1]", Message: "must not be empty"},
+			},
+		},
+		{
+			Name:  "shop",
+			Input: model.ShopParams{TaxRate: &rate},
+			Want: []errs.FieldError{
+				{Field: "name", Message: "must not be empty"},
+				{Field: "tax_rate", Message: "must be 100 or less"},
+			},
+		},
+		{
+			Name:  "shop cocktails",
+			Input: model.ShopCocktailParams{CocktailIDs: []int64{}},
+			Want:  []errs.FieldError{{Field: "cocktail_ids", Message: "must have at least 1 items"}},
+		},
+		{
+			Name:  "order",
+			Input: model.OrderParams{CocktailIDs: []int64{1, 0}},
+			Want:  []errs.FieldError{{Field: "cocktail_ids[1]", Message: "must be greater than 0"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := Struct(tc.Input)
+
+			if tc.Want == nil {
+				assert.Nil(t, err)
+				return
+			}
+			assert.Equal(t, errs.KindValidation, errs.KindOf(err))
+			assert.Equal(t, tc.Want, errs.FieldsOf(err))
+		})
+	}
+}
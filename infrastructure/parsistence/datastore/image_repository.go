@@ -0,0 +1,130 @@
+package datastore
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/shake551/cocktails-api/db"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"log"
+)
+
+// ImageRepository keeps images in cocktail_material_images and their variants in cocktail_material_image_variants.
+// The data columns are text, so the images are stored base64 encoded.
+type ImageRepository struct {
+	db db.Executor
+}
+
+// NewImageRepository returns a repository running its statements on e.
+func NewImageRepository(e db.Executor) *ImageRepository {
+	return &ImageRepository{db: e}
+}
+
+func (r ImageRepository) Create(ctx context.Context, data []byte) (int64, error) {
+	log.Printf("create image ... size: %d\n", len(data))
+
+	res, err := r.db.ExecContext(ctx, `INSERT INTO cocktail_material_images (data) VALUES (?)`, base64.StdEncoding.EncodeToString(data))
+	if err != nil {
+		log.Printf("failed to create image. err: %v", err)
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (r ImageRepository) Get(ctx context.Context, id int64) ([]byte, error) {
+	var encoded string
+	err := r.db.QueryRowContext(ctx, `SELECT data FROM cocktail_material_images WHERE id = ?`, id).Scan(&encoded)
+	if db.IsNoRows(err) {
+		return nil, repository.ErrImageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+func (r ImageRepository) PutVariants(ctx context.Context, id int64, variants map[string][]byte) error {
+	log.Printf("put image variants ... id: %d\n", id)
+
+	for name, data := range variants {
+		_, err := r.db.ExecContext(ctx, `
+			INSERT INTO cocktail_material_image_variants (image_id, name, data) VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE data = VALUES(data)`,
+			id, name, base64.StdEncoding.EncodeToString(data),
+		)
+		if err != nil {
+			log.Printf("failed to put image variant. err: %v", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r ImageRepository) GetVariant(ctx context.Context, id int64, name string) ([]byte, error) {
+	var encoded string
+	err := r.db.QueryRowContext(ctx, `SELECT data FROM cocktail_material_image_variants WHERE image_id = ? AND name = ?`, id, name).Scan(&encoded)
+	if db.IsNoRows(err) {
+		return nil, repository.ErrImageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+func (r ImageRepository) WithoutVariant(ctx context.Context, name string) ([]int64, error) {
+	q := `
+		SELECT id FROM cocktail_material_images
+		WHERE NOT EXISTS (
+			SELECT * FROM cocktail_material_image_variants
+			WHERE cocktail_material_image_variants.image_id = cocktail_material_images.id
+				AND cocktail_material_image_variants.name = ?
+		)
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, q, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r ImageRepository) Delete(ctx context.Context, id int64) error {
+	log.Printf("delete image ... id: %d\n", id)
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM cocktail_material_image_variants WHERE image_id = ?`, id); err != nil {
+		log.Printf("failed to delete image variants. err: %v", err)
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM cocktail_material_images WHERE id = ?`, id)
+	if err != nil {
+		log.Printf("failed to delete image. err: %v", err)
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return repository.ErrImageNotFound
+	}
+
+	return nil
+}
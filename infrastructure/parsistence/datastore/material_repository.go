@@ -0,0 +1,495 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/shake551/cocktails-api/db"
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/normalize"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/domain/units"
+	"log"
+	"time"
+)
+
+type MaterialRepository struct {
+	db db.Executor
+}
+
+// NewMaterialRepository returns a repository running its statements on e.
+func NewMaterialRepository(e db.Executor) *MaterialRepository {
+	return &MaterialRepository{db: e}
+}
+
+func (r MaterialRepository) GetLimit(ctx context.Context, limit int64, offset int64, keyword string) ([]model.MaterialItem, error) {
+	log.Printf("get materials with limit...")
+
+	var rows *sql.Rows
+	var err error
+
+	// the keyword is matched against the normalized name, like the names of cocktails are
+	if keyword != "" {
+		query := `SELECT id, name, abv, created_at, updated_at FROM materials WHERE INSTR(name_key, ?) > 0 ORDER BY id LIMIT ? OFFSET ?`
+		rows, err = r.db.QueryContext(ctx, query, normalize.Name(keyword), limit, offset)
+	} else {
+		query := `SELECT id, name, abv, created_at, updated_at FROM materials ORDER BY id LIMIT ? OFFSET ?`
+		rows, err = r.db.QueryContext(ctx, query, limit, offset)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var materials []model.MaterialItem
+	for rows.Next() {
+		m := model.MaterialItem{}
+		if err := rows.Scan(&m.ID, &m.Name, &m.ABV, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		materials = append(materials, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(materials) == 0 {
+		return []model.MaterialItem{}, nil
+	}
+
+	return materials, nil
+}
+
+func (r MaterialRepository) GetByID(ctx context.Context, id int64) (model.MaterialDetail, error) {
+	log.Printf("get material with material id ... id: %d\n", id)
+
+	d := model.MaterialDetail{}
+	err := r.db.QueryRowContext(ctx, `SELECT id, name, abv, created_at, updated_at FROM materials WHERE id = ?`, id).
+		Scan(&d.ID, &d.Name, &d.ABV, &d.CreatedAt, &d.UpdatedAt)
+	if db.IsNoRows(err) {
+		return model.MaterialDetail{}, repository.ErrMaterialNotFound
+	}
+	if err != nil {
+		return model.MaterialDetail{}, err
+	}
+
+	d.Aliases, err = materialAliases(ctx, r.db, id)
+	if err != nil {
+		return model.MaterialDetail{}, err
+	}
+
+	q := `
+		SELECT DISTINCT
+			cocktails.id,
+			cocktails.name,
+			cocktails.reading,
+			cocktails.image_url,
+			cocktails.created_at,
+			cocktails.updated_at
+		FROM cocktails
+		INNER JOIN cocktail_materials
+			ON cocktails.id = cocktail_materials.cocktail_id
+		WHERE cocktail_materials.material_id = ?
+		ORDER BY cocktails.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, q, id)
+	if err != nil {
+		return model.MaterialDetail{}, err
+	}
+	defer rows.Close()
+
+	d.Cocktails = []model.Cocktail{}
+	for rows.Next() {
+		nc := model.NullableCocktail{}
+		if err := rows.Scan(&nc.ID, &nc.Name, &nc.Reading, &nc.ImageURL, &nc.CreatedAt, &nc.UpdatedAt); err != nil {
+			return model.MaterialDetail{}, err
+		}
+
+		d.Cocktails = append(d.Cocktails, model.Cocktail{
+			ID:        nc.ID,
+			Name:      nc.Name,
+			Reading:   nc.Reading,
+			ImageURL:  nc.ImageURL.String,
+			CreatedAt: nc.CreatedAt,
+			UpdatedAt: nc.UpdatedAt,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return model.MaterialDetail{}, err
+	}
+
+	return d, nil
+}
+
+func (r MaterialRepository) Create(ctx context.Context, params model.MaterialNameParams) (*model.MaterialItem, error) {
+	log.Println("create material...")
+
+	found, err := findMaterialByName(ctx, r.db, params.Name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if found != 0 {
+		return nil, repository.ErrMaterialDuplicate
+	}
+
+	now := time.Now().Unix()
+	res, err := r.db.ExecContext(ctx, `INSERT INTO materials (name, name_key, created_at, updated_at) VALUES (?, ?, ?, ?)`, params.Name, normalize.Name(params.Name), now, now)
+	if err != nil {
+		log.Printf("failed to create material. err: %v", err)
+		return nil, err
+	}
+
+	materialID, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MaterialItem{ID: materialID, Name: params.Name, CreatedAt: now, UpdatedAt: now}, nil
+}
+
+func (r MaterialRepository) Rename(ctx context.Context, id int64, params model.MaterialNameParams) (*model.MaterialItem, error) {
+	log.Printf("rename material ... id: %d\n", id)
+
+	m := model.MaterialItem{}
+	err := r.db.QueryRowContext(ctx, `SELECT id, abv, created_at FROM materials WHERE id = ?`, id).Scan(&m.ID, &m.ABV, &m.CreatedAt)
+	if db.IsNoRows(err) {
+		return nil, repository.ErrMaterialNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := findMaterialByName(ctx, r.db, params.Name, id)
+	if err != nil {
+		return nil, err
+	}
+	if found != 0 {
+		return nil, repository.ErrMaterialDuplicate
+	}
+
+	now := time.Now().Unix()
+	_, err = r.db.ExecContext(ctx, `UPDATE materials SET name = ?, name_key = ?, updated_at = ? WHERE id = ?`, params.Name, normalize.Name(params.Name), now, id)
+	if err != nil {
+		log.Printf("failed to rename material. err: %v", err)
+		return nil, err
+	}
+
+	m.Name = params.Name
+	m.UpdatedAt = now
+	return &m, nil
+}
+
+func (r MaterialRepository) UpdateABV(ctx context.Context, id int64, params model.MaterialABVParams) (*model.MaterialItem, error) {
+	log.Printf("update material abv ... id: %d\n", id)
+
+	m := model.MaterialItem{}
+	now := time.Now().Unix()
+
+	err := db.InTx(ctx, r.db, func(tx db.Executor) error {
+		err := tx.QueryRowContext(ctx, `SELECT id, name, created_at FROM materials WHERE id = ?`, id).Scan(&m.ID, &m.Name, &m.CreatedAt)
+		if db.IsNoRows(err) {
+			return repository.ErrMaterialNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE materials SET abv = ?, updated_at = ? WHERE id = ?`, params.ABV, now, id)
+		if err != nil {
+			log.Printf("failed to update material abv. err: %v", err)
+			return err
+		}
+
+		return storeStrengthsUsing(ctx, tx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.ABV = params.ABV
+	m.UpdatedAt = now
+	return &m, nil
+}
+
+func (r MaterialRepository) AddAlias(ctx context.Context, materialID int64, params model.MaterialNameParams) (*model.MaterialAlias, error) {
+	log.Printf("add material alias ... id: %d\n", materialID)
+
+	a := model.MaterialAlias{MaterialID: materialID, Name: params.Name, CreatedAt: time.Now().Unix()}
+
+	err := db.InTx(ctx, r.db, func(tx db.Executor) error {
+		var locked int64
+		err := tx.QueryRowContext(ctx, `SELECT id FROM materials WHERE id = ? FOR UPDATE`, materialID).Scan(&locked)
+		if db.IsNoRows(err) {
+			return repository.ErrMaterialNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		found, err := findMaterialByName(ctx, tx, params.Name, 0)
+		if err != nil {
+			return err
+		}
+		if found != 0 {
+			return repository.ErrMaterialDuplicate
+		}
+
+		res, err := tx.ExecContext(ctx, `INSERT INTO material_aliases (material_id, name, name_key, created_at) VALUES (?, ?, ?, ?)`, materialID, a.Name, normalize.Name(a.Name), a.CreatedAt)
+		if err != nil {
+			log.Printf("failed to add material alias. err: %v", err)
+			return err
+		}
+
+		a.ID, err = res.LastInsertId()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+func (r MaterialRepository) DeleteAlias(ctx context.Context, materialID int64, aliasID int64) error {
+	log.Printf("delete material alias ... id: %d, alias id: %d\n", materialID, aliasID)
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM material_aliases WHERE id = ? AND material_id = ?`, aliasID, materialID)
+	if err != nil {
+		log.Printf("failed to delete material alias. err: %v", err)
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return repository.ErrMaterialAliasNotFound
+	}
+
+	return nil
+}
+
+// Merge moves every recipe row, shop stock and alias of the source material to the target one,
+// keeps the source name as an alias of the target and deletes the source.
+// Quantities of a cocktail or a shop using both materials are added up in the unit of the target,
+// and the merge is refused when they cannot be converted.
+func (r MaterialRepository) Merge(ctx context.Context, sourceID int64, targetID int64) (model.MaterialDetail, error) {
+	log.Printf("merge material ... source id: %d, target id: %d\n", sourceID, targetID)
+
+	now := time.Now().Unix()
+
+	err := db.InTx(ctx, r.db, func(tx db.Executor) error {
+		var sourceName string
+		var sourceABV *float64
+		err := tx.QueryRowContext(ctx, `SELECT name, abv FROM materials WHERE id = ? FOR UPDATE`, sourceID).Scan(&sourceName, &sourceABV)
+		if db.IsNoRows(err) {
+			return repository.ErrMaterialNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var locked int64
+		err = tx.QueryRowContext(ctx, `SELECT id FROM materials WHERE id = ? FOR UPDATE`, targetID).Scan(&locked)
+		if db.IsNoRows(err) {
+			return repository.ErrMaterialNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// the target keeps its own ABV, and takes the one of the source when it has none
+		_, err = tx.ExecContext(ctx, `UPDATE materials SET abv = COALESCE(abv, ?), updated_at = ? WHERE id = ?`, sourceABV, now, targetID)
+		if err != nil {
+			return err
+		}
+
+		if err := mergeRecipes(ctx, tx, sourceID, targetID); err != nil {
+			return err
+		}
+		if err := mergeStock(ctx, tx, sourceID, targetID, now); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE material_aliases SET material_id = ? WHERE material_id = ?`, targetID, sourceID)
+		if err != nil {
+			return err
+		}
+
+		found, err := findMaterialByName(ctx, tx, sourceName, sourceID)
+		if err != nil {
+			return err
+		}
+		if found == 0 {
+			_, err = tx.ExecContext(ctx, `INSERT INTO material_aliases (material_id, name, name_key, created_at) VALUES (?, ?, ?, ?)`, targetID, sourceName, normalize.Name(sourceName), now)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM materials WHERE id = ?`, sourceID)
+		if err != nil {
+			return err
+		}
+
+		// the recipes of the source now use the target, whose ABV may differ
+		return storeStrengthsUsing(ctx, tx, targetID)
+	})
+	if err != nil {
+		log.Printf("failed to merge material. err: %v", err)
+		return model.MaterialDetail{}, err
+	}
+
+	return r.GetByID(ctx, targetID)
+}
+
+// mergedQuantity is what a row of the target material holds once the rows of the source material are added in.
+type mergedQuantity struct {
+	id       int64
+	quantity model.MaterialQuantity
+}
+
+// mergeRecipes moves the recipe rows of the source material to the target one.
+// A cocktail calling for both keeps the row of the target with the quantities added up.
+func mergeRecipes(ctx context.Context, tx db.Executor, sourceID int64, targetID int64) error {
+	q := `
+		SELECT source.cocktail_id, source.quantity, source.unit, target.quantity, target.unit
+		FROM cocktail_materials AS source
+		INNER JOIN cocktail_materials AS target
+			ON target.cocktail_id = source.cocktail_id
+			AND target.material_id = ?
+		WHERE source.material_id = ?
+		ORDER BY source.cocktail_id
+	`
+	merged, err := mergedQuantities(ctx, tx, q, "cocktail_id", targetID, sourceID)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range merged {
+		_, err := tx.ExecContext(ctx, `UPDATE cocktail_materials SET quantity = ?, unit = ? WHERE cocktail_id = ? AND material_id = ?`, m.quantity.Quantity, m.quantity.Unit, m.id, targetID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM cocktail_materials WHERE cocktail_id = ? AND material_id = ?`, m.id, sourceID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE cocktail_materials SET material_id = ? WHERE material_id = ?`, targetID, sourceID)
+	return err
+}
+
+// mergeStock moves the stock shops hold of the source material to the target one.
+// A shop holding both keeps the stock of the target with the quantities added up.
+func mergeStock(ctx context.Context, tx db.Executor, sourceID int64, targetID int64, now int64) error {
+	q := `
+		SELECT source.shop_id, source.quantity, source.unit, target.quantity, target.unit
+		FROM shop_inventories AS source
+		INNER JOIN shop_inventories AS target
+			ON target.shop_id = source.shop_id
+			AND target.material_id = ?
+		WHERE source.material_id = ?
+		ORDER BY source.shop_id
+	`
+	merged, err := mergedQuantities(ctx, tx, q, "shop_id", targetID, sourceID)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range merged {
+		_, err := tx.ExecContext(ctx, `UPDATE shop_inventories SET quantity = ?, unit = ?, updated_at = ? WHERE shop_id = ? AND material_id = ?`, m.quantity.Quantity, m.quantity.Unit, now, m.id, targetID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM shop_inventories WHERE shop_id = ? AND material_id = ?`, m.id, sourceID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE shop_inventories SET material_id = ? WHERE material_id = ?`, targetID, sourceID)
+	if err != nil {
+		return err
+	}
+
+	// open orders give back to the target what they took of the source
+	_, err = tx.ExecContext(ctx, `UPDATE shop_order_deductions SET material_id = ? WHERE material_id = ?`, targetID, sourceID)
+	return err
+}
+
+// mergedQuantities adds up the quantities of the source and target rows the query selects, ordered by idColumn.
+// Quantities which cannot be converted into the unit of the target refuse the merge, since either would be lost.
+func mergedQuantities(ctx context.Context, tx db.Executor, query string, idColumn string, args ...interface{}) ([]mergedQuantity, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var merged []mergedQuantity
+	for rows.Next() {
+		var id int64
+		var sourceQuantity, targetQuantity sql.NullFloat64
+		var sourceUnit, targetUnit sql.NullString
+		if err := rows.Scan(&id, &sourceQuantity, &sourceUnit, &targetQuantity, &targetUnit); err != nil {
+			return nil, err
+		}
+
+		if len(merged) == 0 || merged[len(merged)-1].id != id {
+			merged = append(merged, mergedQuantity{id: id, quantity: model.MaterialQuantity{Quantity: targetQuantity.Float64, Unit: targetUnit.String}})
+		}
+		last := &merged[len(merged)-1]
+		sum, err := units.Add(last.quantity, model.MaterialQuantity{Quantity: sourceQuantity.Float64, Unit: sourceUnit.String})
+		if err != nil {
+			return nil, fmt.Errorf("%w. %s: %d, %v", repository.ErrMaterialUnitsDiffer, idColumn, id, err)
+		}
+		last.quantity = sum
+	}
+
+	return merged, rows.Err()
+}
+
+// materialAliases returns the aliases of the material in the order they were added.
+func materialAliases(ctx context.Context, e db.Executor, materialID int64) ([]model.MaterialAlias, error) {
+	rows, err := e.QueryContext(ctx, `SELECT id, material_id, name, created_at FROM material_aliases WHERE material_id = ? ORDER BY id`, materialID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := []model.MaterialAlias{}
+	for rows.Next() {
+		a := model.MaterialAlias{}
+		if err := rows.Scan(&a.ID, &a.MaterialID, &a.Name, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		aliases = append(aliases, a)
+	}
+
+	return aliases, rows.Err()
+}
+
+// findMaterialByName returns the id of another material than exceptID whose name or alias
+// matches the name once both are normalized, or 0 when there is none.
+func findMaterialByName(ctx context.Context, e db.Executor, name string, exceptID int64) (int64, error) {
+	q := `
+		SELECT id FROM materials WHERE name_key = ? AND id <> ?
+		UNION ALL
+		SELECT material_id FROM material_aliases WHERE name_key = ? AND material_id <> ?
+	`
+
+	key := normalize.Name(name)
+	var id int64
+	err := e.QueryRowContext(ctx, q, key, exceptID, key, exceptID).Scan(&id)
+	if db.IsNoRows(err) {
+		return 0, nil
+	}
+
+	return id, err
+}
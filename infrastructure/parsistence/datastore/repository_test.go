@@ -0,0 +1,77 @@
+package datastore
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/shake551/cocktails-api/db"
+	"github.com/shake551/cocktails-api/db/migrate"
+	"github.com/shake551/cocktails-api/db/migrations"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/infrastructure/parsistence/repositorytest"
+)
+
+var _ repository.CocktailRepository = (*CocktailRepository)(nil)
+var _ repository.MaterialRepository = (*MaterialRepository)(nil)
+var _ repository.ShopRepository = (*ShopRepository)(nil)
+var _ repository.ImageRepository = (*ImageRepository)(nil)
+var _ repository.UnitOfWork = (*UnitOfWork)(nil)
+
+// openDB connects to the MySQL database of dsn, migrates it and empties every table.
+// The database is for the tests only, as all of its rows are thrown away.
+func openDB(t *testing.T, dsn string) *sqlx.DB {
+	ctx := context.Background()
+	d, err := db.Initialize(db.Config{DSN: dsn})
+	if err != nil {
+		t.Fatalf("db.Initialize() error = %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+
+	m, err := migrate.New(d.DB, migrations.FS, migrations.Steps...)
+	assert.Nil(t, err)
+	_, err = m.Up(ctx)
+	assert.Nil(t, err)
+
+	var tables []string
+	err = d.SelectContext(ctx, &tables, `
+		SELECT table_name FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE' AND table_name <> 'schema_migrations'`)
+	assert.Nil(t, err)
+
+	// TRUNCATE also restarts AUTO_INCREMENT, so every test sees the ids from 1 like the other backends
+	conn, err := d.Connx(ctx)
+	assert.Nil(t, err)
+	defer conn.Close()
+	_, err = conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0")
+	assert.Nil(t, err)
+	for _, table := range tables {
+		_, err = conn.ExecContext(ctx, "TRUNCATE TABLE `"+table+"`")
+		assert.Nil(t, err)
+	}
+	_, err = conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1")
+	assert.Nil(t, err)
+
+	return d
+}
+
+func TestRepositories(t *testing.T) {
+	dsn := os.Getenv("TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("TEST_MYSQL_DSN is not set")
+	}
+
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Backend {
+		d := openDB(t, dsn)
+		return repositorytest.Backend{
+			Cocktail:   NewCocktailRepository(d),
+			Material:   NewMaterialRepository(d),
+			Shop:       NewShopRepository(d),
+			Image:      NewImageRepository(d),
+			UnitOfWork: NewUnitOfWork(d),
+		}
+	})
+}
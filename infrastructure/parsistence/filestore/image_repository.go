@@ -0,0 +1,191 @@
+// Package filestore keeps uploaded images as files in a local directory, for deployments which
+// would rather not grow the database with them.
+package filestore
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/shake551/cocktails-api/domain/repository"
+)
+
+// ImageRepository stores every image as a file named by its id, and its variants next to it as files named
+// by the id and the variant, like "12.thumbnail.jpg".
+type ImageRepository struct {
+	dir string
+}
+
+// NewImageRepository returns a repository keeping the images in dir, creating it when missing.
+func NewImageRepository(dir string) (*ImageRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &ImageRepository{dir: dir}, nil
+}
+
+// Create writes the data to a temporary file first and links it under the next free id, so a reader
+// never sees a half written image and two processes sharing the directory never take the same id.
+func (r ImageRepository) Create(ctx context.Context, data []byte) (int64, error) {
+	log.Printf("create image ... size: %d\n", len(data))
+
+	tmp, err := os.CreateTemp(r.dir, ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Printf("failed to write image. err: %v", err)
+		return 0, err
+	}
+
+	id, err := r.lastID()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		id++
+		err := os.Link(tmp.Name(), r.path(id))
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			log.Printf("failed to create image. err: %v", err)
+			return 0, err
+		}
+		return id, nil
+	}
+}
+
+// lastID returns the largest id in the directory.
+func (r ImageRepository) lastID() (int64, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var last int64
+	for _, e := range entries {
+		if id, err := strconv.ParseInt(e.Name(), 10, 64); err == nil && id > last {
+			last = id
+		}
+	}
+	return last, nil
+}
+
+func (r ImageRepository) Get(ctx context.Context, id int64) ([]byte, error) {
+	data, err := os.ReadFile(r.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, repository.ErrImageNotFound
+	}
+	return data, err
+}
+
+// PutVariants writes every variant to a temporary file and renames it into place, replacing any stored before.
+func (r ImageRepository) PutVariants(ctx context.Context, id int64, variants map[string][]byte) error {
+	log.Printf("put image variants ... id: %d\n", id)
+
+	for name, data := range variants {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.putVariant(id, name, data); err != nil {
+			log.Printf("failed to put image variant. err: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (r ImageRepository) putVariant(id int64, name string, data []byte) error {
+	tmp, err := os.CreateTemp(r.dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.variantPath(id, name))
+}
+
+func (r ImageRepository) GetVariant(ctx context.Context, id int64, name string) ([]byte, error) {
+	data, err := os.ReadFile(r.variantPath(id, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, repository.ErrImageNotFound
+	}
+	return data, err
+}
+
+// WithoutVariant lists the directory, so it also sees the images other processes sharing it stored.
+func (r ImageRepository) WithoutVariant(ctx context.Context, name string) ([]int64, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for _, e := range entries {
+		id, err := strconv.ParseInt(e.Name(), 10, 64)
+		if err != nil {
+			continue
+		}
+		_, err = os.Stat(r.variantPath(id, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			ids = append(ids, id)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// Delete removes the variants before the image, so that a failure leaves the image to be deleted again.
+func (r ImageRepository) Delete(ctx context.Context, id int64) error {
+	log.Printf("delete image ... id: %d\n", id)
+
+	variants, err := filepath.Glob(r.variantPath(id, "*"))
+	if err != nil {
+		return err
+	}
+	for _, v := range variants {
+		if err := os.Remove(v); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	err = os.Remove(r.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return repository.ErrImageNotFound
+	}
+	return err
+}
+
+func (r ImageRepository) path(id int64) string {
+	return filepath.Join(r.dir, strconv.FormatInt(id, 10))
+}
+
+// variantPath is not checked for separators in the name, since the names are only ever the ones photo.Variants makes.
+func (r ImageRepository) variantPath(id int64, name string) string {
+	return r.path(id) + "." + name
+}
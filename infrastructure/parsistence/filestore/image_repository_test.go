@@ -0,0 +1,96 @@
+package filestore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageRepository(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "images")
+	r, err := NewImageRepository(dir)
+	assert.Nil(t, err)
+	ctx := context.Background()
+
+	first, err := r.Create(ctx, []byte("first"))
+	assert.Nil(t, err)
+	second, err := r.Create(ctx, []byte("second"))
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{1, 2}, []int64{first, second})
+
+	data, err := r.Get(ctx, second)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("second"), data)
+
+	assert.Nil(t, r.Delete(ctx, first))
+	_, err = r.Get(ctx, first)
+	assert.ErrorIs(t, err, repository.ErrImageNotFound)
+	assert.ErrorIs(t, r.Delete(ctx, first), repository.ErrImageNotFound)
+
+	// only the images are left behind, not the temporary files they were written to
+	// only the other image is left
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestImageRepositoryCountsOtherWriters(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewImageRepository(dir)
+	assert.Nil(t, err)
+
+	// another process sharing the directory stored an image since
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "7"), []byte("other"), 0o644))
+
+	id, err := r.Create(context.Background(), []byte("mine"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(8), id)
+}
+
+func TestImageRepositoryVariants(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewImageRepository(dir)
+	assert.Nil(t, err)
+	ctx := context.Background()
+
+	id, err := r.Create(ctx, []byte("image"))
+	assert.Nil(t, err)
+
+	other, err := r.Create(ctx, []byte("other"))
+	assert.Nil(t, err)
+
+	_, err = r.GetVariant(ctx, id, "medium.jpg")
+	assert.ErrorIs(t, err, repository.ErrImageNotFound)
+	without, err := r.WithoutVariant(ctx, "thumbnail.jpg")
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{id, other}, without)
+
+	assert.Nil(t, r.PutVariants(ctx, id, map[string][]byte{"medium.jpg": []byte("old"), "thumbnail.jpg": []byte("jpeg")}))
+	without, err = r.WithoutVariant(ctx, "thumbnail.jpg")
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{other}, without)
+	assert.Nil(t, r.PutVariants(ctx, id, map[string][]byte{"medium.jpg": []byte("new")}))
+
+	got, err := r.GetVariant(ctx, id, "medium.jpg")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("new"), got)
+	got, err = r.GetVariant(ctx, id, "thumbnail.jpg")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("jpeg"), got)
+	_, err = r.GetVariant(ctx, id+1, "medium.jpg")
+	assert.ErrorIs(t, err, repository.ErrImageNotFound)
+
+	// deleting the image deletes its variants too
+	assert.Nil(t, r.Delete(ctx, id))
+	_, err = r.GetVariant(ctx, id, "medium.jpg")
+	assert.ErrorIs(t, err, repository.ErrImageNotFound)
+
+	// only the other image is left
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,275 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/domain/strength"
+)
+
+type CocktailRepository struct {
+	s *Store
+}
+
+func NewCocktailRepository(s *Store) *CocktailRepository {
+	return &CocktailRepository{s: s}
+}
+
+func (r CocktailRepository) GetLimit(ctx context.Context, limit int64, offset int64, filter model.CocktailFilter) ([]model.Cocktail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	cocktails := []model.Cocktail{}
+	for _, id := range r.s.cocktailIDs() {
+		c := r.s.cocktails[id]
+		if !r.hasMaterials(c, filter.Materials, true) || !r.hasMaterials(c, filter.ExcludeMaterials, false) {
+			continue
+		}
+		// cocktails whose strength cannot be estimated are left out, since they may be stronger
+		if filter.MaxABV != nil {
+			if s, ok := strength.Of(c.preparation.method, r.s.cocktailMaterials(c)); !ok || s.ABV > *filter.MaxABV {
+				continue
+			}
+		}
+
+		cocktails = append(cocktails, c.Cocktail)
+	}
+	if filter.Keyword != "" {
+		cocktails = rankByKeyword(cocktails, filter.Keyword)
+	}
+
+	start, end := paginate(len(cocktails), limit, offset)
+	return cocktails[start:end], nil
+}
+
+// hasMaterials reports whether every name matches (want) or does not match (!want) a material of the cocktail
+// by its name or one of its aliases.
+func (r CocktailRepository) hasMaterials(c *cocktailRow, names []string, want bool) bool {
+	for _, name := range names {
+		found := false
+		if named := r.s.materialByName(name, 0); named != nil {
+			for _, cm := range c.materials {
+				if cm.materialID == named.ID {
+					found = true
+					break
+				}
+			}
+		}
+		if found != want {
+			return false
+		}
+	}
+	return true
+}
+
+func (r CocktailRepository) GetByID(ctx context.Context, id int64) (model.CocktailDetail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.cocktail(id)
+	if !ok {
+		return model.CocktailDetail{}, fmt.Errorf("%w. cocktail_id: %d", repository.ErrCocktailNotFound, id)
+	}
+
+	d := model.CocktailDetail{
+		ID:        c.ID,
+		Name:      c.Name,
+		Reading:   c.Reading,
+		ImageURL:  c.ImageURL,
+		Materials: r.s.cocktailMaterials(c),
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+	c.preparation.fill(&d)
+
+	return d, nil
+}
+
+func (r CocktailRepository) Create(ctx context.Context, params model.CocktailParams) (*model.CocktailDetail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now().Unix()
+
+	r.s.lastCocktailID++
+	c := &cocktailRow{Cocktail: model.Cocktail{ID: r.s.lastCocktailID, Name: params.Name, Reading: params.Reading, CreatedAt: now, UpdatedAt: now}}
+	c.preparation = newPreparationRow(params)
+	r.s.cocktails[c.ID] = c
+
+	return r.setMaterials(c, params.Materials, now), nil
+}
+
+func (r CocktailRepository) GetListByIDs(ctx context.Context, ids []int64) ([]model.Cocktail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	wanted := map[int64]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	cocktails := []model.Cocktail{}
+	for _, id := range r.s.cocktailIDs() {
+		if wanted[id] {
+			cocktails = append(cocktails, r.s.cocktails[id].Cocktail)
+		}
+	}
+
+	return cocktails, nil
+}
+
+func (r CocktailRepository) Update(ctx context.Context, id int64, params model.CocktailParams) (*model.CocktailDetail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.cocktail(id)
+	if !ok {
+		return nil, repository.ErrCocktailNotFound
+	}
+
+	now := time.Now().Unix()
+	c.Name = params.Name
+	c.Reading = params.Reading
+	c.UpdatedAt = now
+	c.preparation = newPreparationRow(params)
+
+	return r.setMaterials(c, params.Materials, now), nil
+}
+
+// setMaterials replaces the recipe of the cocktail and returns its detail. The caller must hold the lock.
+func (r CocktailRepository) setMaterials(c *cocktailRow, params []model.MaterialParams, now int64) *model.CocktailDetail {
+	c.materials = nil
+	materials := []model.Material{}
+	for _, m := range params {
+		materialID := r.s.findOrCreateMaterial(m.Name, now)
+		c.materials = append(c.materials, cocktailMaterialRow{materialID: materialID, quantity: m.Quantity})
+		materials = append(materials, model.Material{ID: materialID, Name: m.Name, Quantity: m.Quantity})
+	}
+
+	d := &model.CocktailDetail{
+		ID:        c.ID,
+		Name:      c.Name,
+		Reading:   c.Reading,
+		ImageURL:  c.ImageURL,
+		Materials: materials,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+	c.preparation.fill(d)
+
+	return d
+}
+
+func (r CocktailRepository) UpdateImageURL(ctx context.Context, id int64, url string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.cocktail(id)
+	if !ok {
+		return repository.ErrCocktailNotFound
+	}
+
+	c.ImageURL = url
+	c.UpdatedAt = time.Now().Unix()
+	return nil
+}
+
+func (r CocktailRepository) Delete(ctx context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.cocktail(id)
+	if !ok {
+		return repository.ErrCocktailNotFound
+	}
+
+	// orders which are not provided yet must be served or removed before the cocktail disappears
+	for _, o := range r.s.orders {
+		if o.ShopCocktailID == id && isUnprovided(o.Status) {
+			return repository.ErrCocktailInUse
+		}
+	}
+
+	// the row is kept so that the orders and bills of the past still show what was served
+	for key := range r.s.shopCocktails {
+		if key.cocktailID == id {
+			delete(r.s.shopCocktails, key)
+		}
+	}
+	now := time.Now().Unix()
+	c.materials = nil
+	c.preparation = preparationRow{}
+	c.UpdatedAt = now
+	c.deletedAt = now
+
+	return nil
+}
+
+func (r CocktailRepository) GetMakeable(ctx context.Context, materialIDs []int64, materialNames []string, maxMissing int64) ([]model.MakeableCocktail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	ownedIDs := append([]int64{}, materialIDs...)
+	for _, name := range materialNames {
+		if m := r.s.materialByName(name, 0); m != nil {
+			ownedIDs = append(ownedIDs, m.ID)
+		}
+	}
+	owned := func(m model.Material) bool {
+		for _, id := range ownedIDs {
+			if m.ID == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	cocktails := []model.MakeableCocktail{}
+	for _, id := range r.s.cocktailIDs() {
+		c := r.s.cocktails[id]
+		materials := r.s.cocktailMaterials(c)
+		if len(materials) == 0 {
+			continue
+		}
+
+		missing := []model.Material{}
+		for _, m := range materials {
+			if !owned(m) {
+				missing = append(missing, m)
+			}
+		}
+		if int64(len(missing)) > maxMissing {
+			continue
+		}
+
+		cocktails = append(cocktails, model.MakeableCocktail{Cocktail: c.Cocktail, MissingMaterials: missing})
+	}
+
+	return cocktails, nil
+}
+
+// GetAllDetails returns every cocktail with its recipe, for building indexes over the whole catalog.
+func (r CocktailRepository) GetAllDetails(ctx context.Context) ([]model.CocktailDetail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	cocktails := []model.CocktailDetail{}
+	for _, id := range r.s.cocktailIDs() {
+		c := r.s.cocktails[id]
+		cocktails = append(cocktails, model.CocktailDetail{
+			ID:        c.ID,
+			Name:      c.Name,
+			Reading:   c.Reading,
+			ImageURL:  c.ImageURL,
+			Materials: append([]model.Material{}, r.s.cocktailMaterials(c)...),
+			Method:    c.preparation.method,
+			CreatedAt: c.CreatedAt,
+			UpdatedAt: c.UpdatedAt,
+		})
+	}
+
+	return cocktails, nil
+}
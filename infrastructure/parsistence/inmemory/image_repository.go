@@ -0,0 +1,88 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/shake551/cocktails-api/domain/repository"
+)
+
+type ImageRepository struct {
+	s *Store
+}
+
+func NewImageRepository(s *Store) *ImageRepository {
+	return &ImageRepository{s: s}
+}
+
+func (r ImageRepository) Create(ctx context.Context, data []byte) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.lastImageID++
+	r.s.images[r.s.lastImageID] = append([]byte(nil), data...)
+	return r.s.lastImageID, nil
+}
+
+func (r ImageRepository) Get(ctx context.Context, id int64) ([]byte, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	data, ok := r.s.images[id]
+	if !ok {
+		return nil, repository.ErrImageNotFound
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (r ImageRepository) PutVariants(ctx context.Context, id int64, variants map[string][]byte) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored, ok := r.s.variants[id]
+	if !ok {
+		stored = map[string][]byte{}
+		r.s.variants[id] = stored
+	}
+	for name, data := range variants {
+		stored[name] = append([]byte(nil), data...)
+	}
+	return nil
+}
+
+func (r ImageRepository) GetVariant(ctx context.Context, id int64, name string) ([]byte, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	data, ok := r.s.variants[id][name]
+	if !ok {
+		return nil, repository.ErrImageNotFound
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (r ImageRepository) WithoutVariant(ctx context.Context, name string) ([]int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var ids []int64
+	for id := range r.s.images {
+		if _, ok := r.s.variants[id][name]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func (r ImageRepository) Delete(ctx context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.images[id]; !ok {
+		return repository.ErrImageNotFound
+	}
+	delete(r.s.images, id)
+	delete(r.s.variants, id)
+	return nil
+}
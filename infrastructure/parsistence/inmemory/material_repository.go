@@ -0,0 +1,274 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/normalize"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/domain/units"
+)
+
+type MaterialRepository struct {
+	s *Store
+}
+
+func NewMaterialRepository(s *Store) *MaterialRepository {
+	return &MaterialRepository{s: s}
+}
+
+func (r MaterialRepository) GetLimit(ctx context.Context, limit int64, offset int64, keyword string) ([]model.MaterialItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := normalize.Name(keyword)
+	materials := []model.MaterialItem{}
+	for _, id := range r.s.materialIDs() {
+		m := r.s.materials[id]
+		if key != "" && !strings.Contains(normalize.Name(m.Name), key) {
+			continue
+		}
+
+		materials = append(materials, *m)
+	}
+
+	start, end := paginate(len(materials), limit, offset)
+	return materials[start:end], nil
+}
+
+func (r MaterialRepository) GetByID(ctx context.Context, id int64) (model.MaterialDetail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	m, ok := r.s.materials[id]
+	if !ok {
+		return model.MaterialDetail{}, repository.ErrMaterialNotFound
+	}
+
+	return r.s.materialDetail(m), nil
+}
+
+func (r MaterialRepository) Create(ctx context.Context, params model.MaterialNameParams) (*model.MaterialItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.materialByName(params.Name, 0) != nil {
+		return nil, repository.ErrMaterialDuplicate
+	}
+
+	now := time.Now().Unix()
+	r.s.lastMaterialID++
+	m := &model.MaterialItem{ID: r.s.lastMaterialID, Name: params.Name, CreatedAt: now, UpdatedAt: now}
+	r.s.materials[m.ID] = m
+
+	created := *m
+	return &created, nil
+}
+
+func (r MaterialRepository) Rename(ctx context.Context, id int64, params model.MaterialNameParams) (*model.MaterialItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	m, ok := r.s.materials[id]
+	if !ok {
+		return nil, repository.ErrMaterialNotFound
+	}
+	if r.s.materialByName(params.Name, id) != nil {
+		return nil, repository.ErrMaterialDuplicate
+	}
+
+	m.Name = params.Name
+	m.UpdatedAt = time.Now().Unix()
+
+	renamed := *m
+	return &renamed, nil
+}
+
+func (r MaterialRepository) UpdateABV(ctx context.Context, id int64, params model.MaterialABVParams) (*model.MaterialItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	m, ok := r.s.materials[id]
+	if !ok {
+		return nil, repository.ErrMaterialNotFound
+	}
+
+	// the stored ABV is never written through, so snapshots may share it, but it must not be the caller's
+	m.ABV = nil
+	if params.ABV != nil {
+		abv := *params.ABV
+		m.ABV = &abv
+	}
+	m.UpdatedAt = time.Now().Unix()
+
+	updated := *m
+	return &updated, nil
+}
+
+func (r MaterialRepository) AddAlias(ctx context.Context, materialID int64, params model.MaterialNameParams) (*model.MaterialAlias, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.materials[materialID]; !ok {
+		return nil, repository.ErrMaterialNotFound
+	}
+	if r.s.materialByName(params.Name, 0) != nil {
+		return nil, repository.ErrMaterialDuplicate
+	}
+
+	r.s.lastAliasID++
+	a := &model.MaterialAlias{ID: r.s.lastAliasID, MaterialID: materialID, Name: params.Name, CreatedAt: time.Now().Unix()}
+	r.s.aliases[a.ID] = a
+
+	added := *a
+	return &added, nil
+}
+
+func (r MaterialRepository) DeleteAlias(ctx context.Context, materialID int64, aliasID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	a, ok := r.s.aliases[aliasID]
+	if !ok || a.MaterialID != materialID {
+		return repository.ErrMaterialAliasNotFound
+	}
+
+	delete(r.s.aliases, aliasID)
+	return nil
+}
+
+// Merge moves every recipe row, shop stock and alias of the source material to the target one,
+// keeps the source name as an alias of the target and deletes the source.
+// Quantities of a cocktail or a shop using both materials are added up in the unit of the target,
+// and the merge is refused when they cannot be converted.
+func (r MaterialRepository) Merge(ctx context.Context, sourceID int64, targetID int64) (model.MaterialDetail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	source, ok := r.s.materials[sourceID]
+	if !ok {
+		return model.MaterialDetail{}, repository.ErrMaterialNotFound
+	}
+	target, ok := r.s.materials[targetID]
+	if !ok {
+		return model.MaterialDetail{}, repository.ErrMaterialNotFound
+	}
+
+	// every quantity is added up before anything is moved, so a refused merge leaves the store as it was
+	recipes := map[int64][]cocktailMaterialRow{}
+	for id, c := range r.s.cocktails {
+		merged, err := mergeRecipe(c.materials, sourceID, targetID)
+		if err != nil {
+			return model.MaterialDetail{}, fmt.Errorf("%w. cocktail_id: %d, %v", repository.ErrMaterialUnitsDiffer, id, err)
+		}
+		recipes[id] = merged
+	}
+
+	stock := map[inventoryKey]model.MaterialQuantity{}
+	for key, inv := range r.s.inventories {
+		if key.materialID != sourceID {
+			continue
+		}
+		targetKey := inventoryKey{shopID: key.shopID, materialID: targetID}
+		merged, ok := r.s.inventories[targetKey]
+		if !ok {
+			continue
+		}
+		sum, err := units.Add(model.MaterialQuantity{Quantity: merged.quantity, Unit: merged.unit}, model.MaterialQuantity{Quantity: inv.quantity, Unit: inv.unit})
+		if err != nil {
+			return model.MaterialDetail{}, fmt.Errorf("%w. shop_id: %d, %v", repository.ErrMaterialUnitsDiffer, key.shopID, err)
+		}
+		stock[targetKey] = sum
+	}
+
+	now := time.Now().Unix()
+	for id, c := range r.s.cocktails {
+		c.materials = recipes[id]
+	}
+	for key, inv := range r.s.inventories {
+		if key.materialID != sourceID {
+			continue
+		}
+
+		delete(r.s.inventories, key)
+		targetKey := inventoryKey{shopID: key.shopID, materialID: targetID}
+		sum, ok := stock[targetKey]
+		if !ok {
+			r.s.inventories[targetKey] = inv
+			continue
+		}
+		r.s.inventories[targetKey] = &inventoryRow{quantity: sum.Quantity, unit: sum.Unit, updatedAt: now}
+	}
+
+	for _, rows := range r.s.deductions {
+		for i := range rows {
+			if rows[i].materialID == sourceID {
+				rows[i].materialID = targetID
+			}
+		}
+	}
+	for _, a := range r.s.aliases {
+		if a.MaterialID == sourceID {
+			a.MaterialID = targetID
+		}
+	}
+	if r.s.materialByName(source.Name, sourceID) == nil {
+		r.s.lastAliasID++
+		r.s.aliases[r.s.lastAliasID] = &model.MaterialAlias{ID: r.s.lastAliasID, MaterialID: targetID, Name: source.Name, CreatedAt: now}
+	}
+
+	delete(r.s.materials, sourceID)
+	if target.ABV == nil {
+		target.ABV = source.ABV
+	}
+	target.UpdatedAt = now
+
+	return r.s.materialDetail(target), nil
+}
+
+// mergeRecipe returns the recipe with the rows of the source material moved to the target one.
+// A recipe calling for both keeps the row of the target with the quantities added up.
+func mergeRecipe(rows []cocktailMaterialRow, sourceID int64, targetID int64) ([]cocktailMaterialRow, error) {
+	target := -1
+	for i, cm := range rows {
+		if cm.materialID == targetID {
+			target = i
+			break
+		}
+	}
+
+	merged := make([]cocktailMaterialRow, 0, len(rows))
+	for _, cm := range rows {
+		if cm.materialID == sourceID {
+			if target < 0 {
+				merged = append(merged, cocktailMaterialRow{materialID: targetID, quantity: cm.quantity})
+			}
+			continue
+		}
+		merged = append(merged, cm)
+	}
+	if target < 0 {
+		return merged, nil
+	}
+
+	sum := rows[target].quantity
+	for _, cm := range rows {
+		if cm.materialID != sourceID {
+			continue
+		}
+		var err error
+		if sum, err = units.Add(sum, cm.quantity); err != nil {
+			return nil, err
+		}
+	}
+	for i := range merged {
+		if merged[i].materialID == targetID {
+			merged[i].quantity = sum
+			break
+		}
+	}
+	return merged, nil
+}
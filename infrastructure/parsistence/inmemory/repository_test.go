@@ -0,0 +1,27 @@
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/infrastructure/parsistence/repositorytest"
+)
+
+var _ repository.CocktailRepository = (*CocktailRepository)(nil)
+var _ repository.MaterialRepository = (*MaterialRepository)(nil)
+var _ repository.ShopRepository = (*ShopRepository)(nil)
+var _ repository.ImageRepository = (*ImageRepository)(nil)
+var _ repository.UnitOfWork = (*UnitOfWork)(nil)
+
+func TestRepositories(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Backend {
+		s := NewStore()
+		return repositorytest.Backend{
+			Cocktail:   NewCocktailRepository(s),
+			Material:   NewMaterialRepository(s),
+			Shop:       NewShopRepository(s),
+			Image:      NewImageRepository(s),
+			UnitOfWork: NewUnitOfWork(s),
+		}
+	})
+}
@@ -0,0 +1,486 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/domain/units"
+)
+
+type ShopRepository struct {
+	s *Store
+}
+
+func NewShopRepository(s *Store) *ShopRepository {
+	return &ShopRepository{s: s}
+}
+
+func (r ShopRepository) GetLimit(ctx context.Context, limit int64, offset int64) ([]model.Shop, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	ids := make([]int64, 0, len(r.s.shops))
+	for id := range r.s.shops {
+		ids = append(ids, id)
+	}
+
+	shops := []model.Shop{}
+	for _, id := range sortIDs(ids) {
+		shops = append(shops, *r.s.shops[id])
+	}
+
+	start, end := paginate(len(shops), limit, offset)
+	return shops[start:end], nil
+}
+
+func (r ShopRepository) Create(ctx context.Context, params model.ShopParams) (*model.Shop, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	taxRate := model.DefaultTaxRate
+	if params.TaxRate != nil {
+		taxRate = *params.TaxRate
+	}
+
+	r.s.lastShopID++
+	s := &model.Shop{ID: r.s.lastShopID, Name: params.Name, TaxRate: taxRate}
+	r.s.shops[s.ID] = s
+
+	created := *s
+	return &created, nil
+}
+
+func (r ShopRepository) GetByID(ctx context.Context, id int64) (model.Shop, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	s, ok := r.s.shops[id]
+	if !ok {
+		return model.Shop{}, fmt.Errorf("%w. shop_id: %d", repository.ErrShopNotFound, id)
+	}
+
+	return *s, nil
+}
+
+func (r ShopRepository) Update(ctx context.Context, id int64, params model.ShopParams) (*model.Shop, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	s, ok := r.s.shops[id]
+	if !ok {
+		return nil, repository.ErrShopNotFound
+	}
+
+	s.Name = params.Name
+	if params.TaxRate != nil {
+		s.TaxRate = *params.TaxRate
+	}
+
+	updated := *s
+	return &updated, nil
+}
+
+// menu returns the cocktails on the menu of the shop in the order they were added. The caller must hold the lock.
+func (r ShopRepository) menu(shopID int64) []shopCocktailKey {
+	var keys []shopCocktailKey
+	for key := range r.s.shopCocktails {
+		if key.shopID == shopID {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return r.s.shopCocktails[keys[i]].seq < r.s.shopCocktails[keys[j]].seq
+	})
+	return keys
+}
+
+func (r ShopRepository) GetShopCocktailList(ctx context.Context, shopID int64, limit int64, offset int64) ([]model.ShopMenuCocktail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	cocktails := []model.ShopMenuCocktail{}
+	for _, key := range r.menu(shopID) {
+		c, ok := r.s.cocktail(key.cocktailID)
+		if !ok {
+			continue
+		}
+
+		sc := r.s.shopCocktails[key]
+		cocktails = append(cocktails, model.ShopMenuCocktail{
+			ID:           c.ID,
+			Name:         c.Name,
+			Reading:      c.Reading,
+			ImageURL:     c.ImageURL,
+			Price:        sc.price,
+			Availability: sc.availability,
+			InStock:      r.s.inStock(shopID, c),
+			CreatedAt:    c.CreatedAt,
+			UpdatedAt:    c.UpdatedAt,
+		})
+	}
+
+	start, end := paginate(len(cocktails), limit, offset)
+	return cocktails[start:end], nil
+}
+
+func (r ShopRepository) AddShopCocktail(ctx context.Context, shopID int64, params model.ShopCocktailParams) ([]*model.ShopCocktail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, cID := range params.CocktailIDs {
+		if _, ok := r.s.cocktail(cID); !ok {
+			return nil, fmt.Errorf("%w. cocktail_id: %d", repository.ErrCocktailNotFound, cID)
+		}
+	}
+
+	var cocktails []*model.ShopCocktail
+	for _, cID := range params.CocktailIDs {
+		key := shopCocktailKey{shopID: shopID, cocktailID: cID}
+		sc, ok := r.s.shopCocktails[key]
+		if !ok {
+			r.s.lastMenuSeq++
+			sc = &shopCocktailRow{seq: r.s.lastMenuSeq}
+			r.s.shopCocktails[key] = sc
+		}
+
+		cocktails = append(cocktails, &model.ShopCocktail{ShopID: shopID, CocktailID: cID, Price: sc.price})
+	}
+
+	return cocktails, nil
+}
+
+func (r ShopRepository) UpdateShopCocktailPrice(ctx context.Context, shopID int64, cocktailID int64, params model.ShopCocktailPriceParams) (*model.ShopCocktail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	sc, ok := r.s.shopCocktails[shopCocktailKey{shopID: shopID, cocktailID: cocktailID}]
+	if !ok {
+		return nil, repository.ErrShopCocktailNotFound
+	}
+
+	sc.price = params.Price
+
+	return &model.ShopCocktail{ShopID: shopID, CocktailID: cocktailID, Price: params.Price}, nil
+}
+
+func (r ShopRepository) UpdateShopCocktailAvailability(ctx context.Context, shopID int64, cocktailID int64, params model.ShopCocktailAvailability) (*model.ShopCocktailAvailability, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	sc, ok := r.s.shopCocktails[shopCocktailKey{shopID: shopID, cocktailID: cocktailID}]
+	if !ok {
+		return nil, repository.ErrShopCocktailNotFound
+	}
+
+	sc.availability = model.ShopCocktailAvailability{SoldOut: params.SoldOut}
+	if params.SoldOut {
+		sc.availability.BackAt = params.BackAt
+	}
+
+	availability := sc.availability
+	return &availability, nil
+}
+
+func (r ShopRepository) GetShopCocktailDetail(ctx context.Context, shopID int64, cocktailID int64) (model.CocktailDetail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.cocktail(cocktailID)
+	if _, onMenu := r.s.shopCocktails[shopCocktailKey{shopID: shopID, cocktailID: cocktailID}]; !ok || !onMenu {
+		return model.CocktailDetail{}, fmt.Errorf("%w. shop_id: %d, cocktail_id: %d", repository.ErrShopCocktailNotFound, shopID, cocktailID)
+	}
+
+	d := model.CocktailDetail{
+		ID:        c.ID,
+		Name:      c.Name,
+		Reading:   c.Reading,
+		ImageURL:  c.ImageURL,
+		Materials: r.s.cocktailMaterials(c),
+	}
+	c.preparation.fill(&d)
+
+	return d, nil
+}
+
+// tableOrders lists the orders of the shop, optionally narrowed to one table and to unprovided ones.
+// The caller must hold the lock.
+func (r ShopRepository) tableOrders(shopID int64, tableID int64, unprovided bool) []*model.TableOrder {
+	orders := []*model.TableOrder{}
+	for _, id := range r.s.orderIDs() {
+		o := r.s.orders[id]
+		t, ok := r.s.tables[o.TableID]
+		if !ok || t.ShopID != shopID || (tableID != 0 && t.ID != tableID) {
+			continue
+		}
+		if unprovided && !isUnprovided(o.Status) {
+			continue
+		}
+		c, ok := r.s.cocktails[o.ShopCocktailID]
+		if !ok {
+			continue
+		}
+
+		orders = append(orders, &model.TableOrder{ID: o.ID, Name: c.Name, ImageURL: c.ImageURL, Status: o.Status})
+	}
+	return orders
+}
+
+func isUnprovided(status model.OrderStatus) bool {
+	for _, s := range model.UnprovidedOrderStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (r ShopRepository) GetUnprovidedOrderList(ctx context.Context, shopID int64, limit int64, offset int64) ([]*model.TableOrder, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	orders := r.tableOrders(shopID, 0, true)
+
+	start, end := paginate(len(orders), limit, offset)
+	return orders[start:end], nil
+}
+
+func (r ShopRepository) AddTable(ctx context.Context, shopID int64) (*model.Table, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.lastTableID++
+	t := &model.Table{ID: r.s.lastTableID, ShopID: shopID}
+	r.s.tables[t.ID] = t
+
+	created := *t
+	return &created, nil
+}
+
+func (r ShopRepository) GetTable(ctx context.Context, shopID int64, tableID int64) (*model.Table, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.tables[tableID]
+	if !ok || t.ShopID != shopID {
+		return &model.Table{}, fmt.Errorf("%w. shop_id: %d, table_id: %d", repository.ErrTableNotFound, shopID, tableID)
+	}
+
+	found := *t
+	return &found, nil
+}
+
+func (r ShopRepository) GetTableOrderList(ctx context.Context, shopID int64, tableID int64, unprovided bool) ([]*model.TableOrder, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if tableID == 0 {
+		return []*model.TableOrder{}, nil
+	}
+	return r.tableOrders(shopID, tableID, unprovided), nil
+}
+
+func (r ShopRepository) GetTableBillOrders(ctx context.Context, shopID int64, tableID int64) ([]model.BillOrder, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	orders := []model.BillOrder{}
+	for _, id := range r.s.orderIDs() {
+		o := r.s.orders[id]
+		t, ok := r.s.tables[o.TableID]
+		if !ok || t.ShopID != shopID || t.ID != tableID || o.Status == model.OrderStatusCancelled {
+			continue
+		}
+		c, ok := r.s.cocktails[o.ShopCocktailID]
+		if !ok {
+			continue
+		}
+
+		orders = append(orders, model.BillOrder{OrderID: o.ID, CocktailID: c.ID, Name: c.Name, Price: o.Price, CreatedAt: o.CreatedAt})
+	}
+
+	return orders, nil
+}
+
+func (r ShopRepository) Order(ctx context.Context, shopID int64, tableID int64, params model.OrderParams) ([]*model.Order, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now().Unix()
+
+	if t, ok := r.s.tables[tableID]; !ok || t.ShopID != shopID {
+		return nil, fmt.Errorf("%w. shop_id: %d, table_id: %d", repository.ErrTableNotFound, shopID, tableID)
+	}
+
+	// check the whole order against a copy of the stock first, so a failure leaves nothing half applied
+	stock := map[inventoryKey]float64{}
+	for key, inv := range r.s.inventories {
+		if key.shopID == shopID {
+			stock[key] = inv.quantity
+		}
+	}
+
+	deductions := make([][]deductionRow, len(params.CocktailIDs))
+	for i, cID := range params.CocktailIDs {
+		sc, ok := r.s.shopCocktails[shopCocktailKey{shopID: shopID, cocktailID: cID}]
+		c, exists := r.s.cocktail(cID)
+		if !ok || !exists {
+			return nil, repository.ErrShopCocktailNotFound
+		}
+		if sc.availability.IsSoldOut(now) {
+			return nil, fmt.Errorf("%w. cocktail_id: %d", repository.ErrSoldOut, cID)
+		}
+
+		for _, cm := range c.materials {
+			key := inventoryKey{shopID: shopID, materialID: cm.materialID}
+			inv, ok := r.s.inventories[key]
+			if !ok || cm.quantity.Quantity == 0 {
+				continue
+			}
+			left, err := units.Sub(model.MaterialQuantity{Quantity: stock[key], Unit: inv.unit}, cm.quantity)
+			if err != nil {
+				return nil, fmt.Errorf("%w. material_id: %d, recipe: %s, stock: %s, %v", repository.ErrInventoryUnitMismatch, cm.materialID, cm.quantity.Unit, inv.unit, err)
+			}
+			if left.Quantity < 0 {
+				return nil, fmt.Errorf("%w. material_id: %d", repository.ErrOutOfStock, cm.materialID)
+			}
+			deductions[i] = append(deductions[i], deductionRow{materialID: cm.materialID, quantity: stock[key] - left.Quantity, unit: inv.unit})
+			stock[key] = left.Quantity
+		}
+	}
+
+	for key, quantity := range stock {
+		inv := r.s.inventories[key]
+		if inv.quantity != quantity {
+			inv.quantity = quantity
+			inv.updatedAt = now
+		}
+	}
+
+	var orders []*model.Order
+	for i, cID := range params.CocktailIDs {
+		r.s.lastOrderID++
+		o := &model.Order{
+			ID:             r.s.lastOrderID,
+			TableID:        tableID,
+			ShopCocktailID: cID,
+			Price:          r.s.shopCocktails[shopCocktailKey{shopID: shopID, cocktailID: cID}].price,
+			CreatedAt:      now,
+		}
+		o.SetStatus(model.OrderStatusAccepted, now)
+		r.s.orders[o.ID] = o
+		r.s.deductions[o.ID] = deductions[i]
+
+		created := *o
+		orders = append(orders, &created)
+	}
+
+	return orders, nil
+}
+
+func (r ShopRepository) GetOrder(ctx context.Context, shopID int64, tableID int64, orderID int64) (*model.Order, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	o, ok := r.s.orders[orderID]
+	if !ok || o.TableID != tableID {
+		return nil, repository.ErrOrderNotFound
+	}
+	if t, ok := r.s.tables[tableID]; !ok || t.ShopID != shopID {
+		return nil, repository.ErrOrderNotFound
+	}
+
+	found := *o
+	return &found, nil
+}
+
+func (r ShopRepository) UpdateOrderStatus(ctx context.Context, orderID int64, from model.OrderStatus, to model.OrderStatus, at int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	o, ok := r.s.orders[orderID]
+	if !ok || o.Status != from {
+		return fmt.Errorf("%w. order is no longer %s", repository.ErrInvalidOrderTransition, from)
+	}
+
+	o.SetStatus(to, at)
+	return nil
+}
+
+func (r ShopRepository) GetInventory(ctx context.Context, shopID int64) ([]model.InventoryItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	items := []model.InventoryItem{}
+	for _, id := range r.s.materialIDs() {
+		inv, ok := r.s.inventories[inventoryKey{shopID: shopID, materialID: id}]
+		if !ok {
+			continue
+		}
+
+		items = append(items, model.InventoryItem{
+			MaterialID:   id,
+			MaterialName: r.s.materials[id].Name,
+			Quantity:     inv.quantity,
+			Unit:         inv.unit,
+			UpdatedAt:    inv.updatedAt,
+		})
+	}
+
+	return items, nil
+}
+
+func (r ShopRepository) UpdateInventory(ctx context.Context, shopID int64, params model.InventoryParams) ([]model.InventoryItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, m := range params.Materials {
+		if _, ok := r.s.materials[m.MaterialID]; !ok {
+			return nil, repository.ErrMaterialNotFound
+		}
+	}
+
+	now := time.Now().Unix()
+
+	items := []model.InventoryItem{}
+	for _, m := range params.Materials {
+		r.s.inventories[inventoryKey{shopID: shopID, materialID: m.MaterialID}] = &inventoryRow{quantity: m.Quantity, unit: m.Unit, updatedAt: now}
+
+		items = append(items, model.InventoryItem{
+			MaterialID:   m.MaterialID,
+			MaterialName: r.s.materials[m.MaterialID].Name,
+			Quantity:     m.Quantity,
+			Unit:         m.Unit,
+			UpdatedAt:    now,
+		})
+	}
+
+	return items, nil
+}
+
+func (r ShopRepository) RestoreInventory(ctx context.Context, shopID int64, orderID int64, at int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, d := range r.s.deductions[orderID] {
+		inv, ok := r.s.inventories[inventoryKey{shopID: shopID, materialID: d.materialID}]
+		if !ok {
+			continue
+		}
+		// giving the deduction back is taking out a negative amount of it
+		restored, err := units.Sub(model.MaterialQuantity{Quantity: inv.quantity, Unit: inv.unit}, model.MaterialQuantity{Quantity: -d.quantity, Unit: d.unit})
+		if err != nil {
+			log.Printf("cannot restore inventory. order_id: %d, material_id: %d, taken: %s, stock: %s, err: %v", orderID, d.materialID, d.unit, inv.unit, err)
+			continue
+		}
+		inv.quantity = restored.Quantity
+		inv.updatedAt = at
+	}
+	delete(r.s.deductions, orderID)
+
+	return nil
+}
@@ -0,0 +1,362 @@
+package inmemory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/normalize"
+	"github.com/shake551/cocktails-api/domain/units"
+)
+
+// Store holds every table of the in-memory backend.
+// Repositories created from the same Store see each other's writes, like tables of one database.
+type Store struct {
+	mu sync.Mutex
+
+	cocktails     map[int64]*cocktailRow
+	materials     map[int64]*model.MaterialItem
+	aliases       map[int64]*model.MaterialAlias
+	shops         map[int64]*model.Shop
+	shopCocktails map[shopCocktailKey]*shopCocktailRow
+	tables        map[int64]*model.Table
+	orders        map[int64]*model.Order
+	inventories   map[inventoryKey]*inventoryRow
+	deductions    map[int64][]deductionRow
+	// images and their variants are kept out of snapshots, since image data does not take part in units of work on any backend
+	images   map[int64][]byte
+	variants map[int64]map[string][]byte
+
+	lastCocktailID int64
+	lastMaterialID int64
+	lastAliasID    int64
+	lastShopID     int64
+	lastTableID    int64
+	lastOrderID    int64
+	lastMenuSeq    int64
+	lastImageID    int64
+}
+
+type cocktailRow struct {
+	model.Cocktail
+	materials   []cocktailMaterialRow
+	preparation preparationRow
+	// deletedAt is set once the cocktail is deleted. The row stays for the orders which still refer to it.
+	deletedAt int64
+}
+
+type preparationRow struct {
+	method  model.Method
+	glass   string
+	garnish string
+	notes   string
+	steps   []string
+}
+
+func newPreparationRow(params model.CocktailParams) preparationRow {
+	return preparationRow{
+		method:  params.Method,
+		glass:   params.Glass,
+		garnish: params.Garnish,
+		notes:   params.Notes,
+		steps:   append([]string{}, params.Steps...),
+	}
+}
+
+// fill copies how the cocktail is prepared into its detail.
+func (p preparationRow) fill(d *model.CocktailDetail) {
+	d.Method = p.method
+	d.Glass = p.glass
+	d.Garnish = p.garnish
+	d.Notes = p.notes
+	d.Steps = append([]string{}, p.steps...)
+}
+
+type cocktailMaterialRow struct {
+	materialID int64
+	quantity   model.MaterialQuantity
+}
+
+type shopCocktailKey struct {
+	shopID     int64
+	cocktailID int64
+}
+
+type shopCocktailRow struct {
+	seq          int64
+	price        int64
+	availability model.ShopCocktailAvailability
+}
+
+type inventoryKey struct {
+	shopID     int64
+	materialID int64
+}
+
+type inventoryRow struct {
+	quantity  float64
+	unit      string
+	updatedAt int64
+}
+
+// deductionRow is what an order took of a material, in the unit the shop stock was kept in.
+type deductionRow struct {
+	materialID int64
+	quantity   float64
+	unit       string
+}
+
+func NewStore() *Store {
+	return &Store{
+		cocktails:     map[int64]*cocktailRow{},
+		materials:     map[int64]*model.MaterialItem{},
+		aliases:       map[int64]*model.MaterialAlias{},
+		shops:         map[int64]*model.Shop{},
+		shopCocktails: map[shopCocktailKey]*shopCocktailRow{},
+		tables:        map[int64]*model.Table{},
+		orders:        map[int64]*model.Order{},
+		inventories:   map[inventoryKey]*inventoryRow{},
+		deductions:    map[int64][]deductionRow{},
+		images:        map[int64][]byte{},
+		variants:      map[int64]map[string][]byte{},
+	}
+}
+
+// snapshot deep copies the tables and counters of the Store.
+func (s *Store) snapshot() *Store {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := NewStore()
+	for id, row := range s.cocktails {
+		copied := *row
+		copied.materials = append([]cocktailMaterialRow(nil), row.materials...)
+		copied.preparation.steps = append([]string{}, row.preparation.steps...)
+		c.cocktails[id] = &copied
+	}
+	for id, m := range s.materials {
+		copied := *m
+		c.materials[id] = &copied
+	}
+	for id, a := range s.aliases {
+		copied := *a
+		c.aliases[id] = &copied
+	}
+	for id, shop := range s.shops {
+		copied := *shop
+		c.shops[id] = &copied
+	}
+	for key, sc := range s.shopCocktails {
+		copied := *sc
+		c.shopCocktails[key] = &copied
+	}
+	for id, t := range s.tables {
+		copied := *t
+		c.tables[id] = &copied
+	}
+	for id, o := range s.orders {
+		copied := *o
+		c.orders[id] = &copied
+	}
+	for key, inv := range s.inventories {
+		copied := *inv
+		c.inventories[key] = &copied
+	}
+	for id, rows := range s.deductions {
+		c.deductions[id] = append([]deductionRow(nil), rows...)
+	}
+
+	c.lastCocktailID = s.lastCocktailID
+	c.lastMaterialID = s.lastMaterialID
+	c.lastAliasID = s.lastAliasID
+	c.lastShopID = s.lastShopID
+	c.lastTableID = s.lastTableID
+	c.lastOrderID = s.lastOrderID
+	c.lastMenuSeq = s.lastMenuSeq
+
+	return c
+}
+
+// restore puts back the tables and counters of a snapshot.
+func (s *Store) restore(c *Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cocktails = c.cocktails
+	s.materials = c.materials
+	s.aliases = c.aliases
+	s.shops = c.shops
+	s.shopCocktails = c.shopCocktails
+	s.tables = c.tables
+	s.orders = c.orders
+	s.inventories = c.inventories
+	s.deductions = c.deductions
+
+	s.lastCocktailID = c.lastCocktailID
+	s.lastMaterialID = c.lastMaterialID
+	s.lastAliasID = c.lastAliasID
+	s.lastShopID = c.lastShopID
+	s.lastTableID = c.lastTableID
+	s.lastOrderID = c.lastOrderID
+	s.lastMenuSeq = c.lastMenuSeq
+}
+
+// findOrCreateMaterial returns the id of the material matching the name or one of its aliases, creating it when missing.
+// The caller must hold the lock.
+func (s *Store) findOrCreateMaterial(name string, now int64) int64 {
+	if m := s.materialByName(name, 0); m != nil {
+		return m.ID
+	}
+
+	s.lastMaterialID++
+	s.materials[s.lastMaterialID] = &model.MaterialItem{ID: s.lastMaterialID, Name: name, CreatedAt: now, UpdatedAt: now}
+	return s.lastMaterialID
+}
+
+// materialByName finds another material than exceptID whose name or alias matches the name once both are normalized.
+func (s *Store) materialByName(name string, exceptID int64) *model.MaterialItem {
+	key := normalize.Name(name)
+	for _, id := range s.materialIDs() {
+		if m := s.materials[id]; id != exceptID && normalize.Name(m.Name) == key {
+			return m
+		}
+	}
+	for _, id := range s.aliasIDs() {
+		if a := s.aliases[id]; a.MaterialID != exceptID && normalize.Name(a.Name) == key {
+			return s.materials[a.MaterialID]
+		}
+	}
+	return nil
+}
+
+// materialDetail resolves the aliases of the material and the cocktails using it. The caller must hold the lock.
+func (s *Store) materialDetail(m *model.MaterialItem) model.MaterialDetail {
+	d := model.MaterialDetail{ID: m.ID, Name: m.Name, ABV: m.ABV, Aliases: s.materialAliases(m.ID), Cocktails: []model.Cocktail{}, CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt}
+	for _, cocktailID := range s.cocktailIDs() {
+		c := s.cocktails[cocktailID]
+		for _, cm := range c.materials {
+			if cm.materialID == m.ID {
+				d.Cocktails = append(d.Cocktails, c.Cocktail)
+				break
+			}
+		}
+	}
+	return d
+}
+
+// materialAliases returns the aliases of the material in the order they were added. The caller must hold the lock.
+func (s *Store) materialAliases(materialID int64) []model.MaterialAlias {
+	aliases := []model.MaterialAlias{}
+	for _, id := range s.aliasIDs() {
+		if a := s.aliases[id]; a.MaterialID == materialID {
+			aliases = append(aliases, *a)
+		}
+	}
+	return aliases
+}
+
+// cocktailMaterials resolves the recipe of the cocktail. The caller must hold the lock.
+func (s *Store) cocktailMaterials(c *cocktailRow) []model.Material {
+	materials := []model.Material{}
+	for _, cm := range c.materials {
+		m, ok := s.materials[cm.materialID]
+		if !ok {
+			continue
+		}
+		materials = append(materials, model.Material{ID: m.ID, Name: m.Name, ABV: m.ABV, Quantity: cm.quantity})
+	}
+	return materials
+}
+
+// inStock reports whether the shop stock covers the recipe of the cocktail.
+// Materials the shop does not track, or the recipe gives no quantity of, never run out,
+// while stock kept in a unit the recipe cannot be converted to cannot be ordered and so counts as out.
+func (s *Store) inStock(shopID int64, c *cocktailRow) bool {
+	for _, cm := range c.materials {
+		inv, ok := s.inventories[inventoryKey{shopID: shopID, materialID: cm.materialID}]
+		if !ok || cm.quantity.Quantity == 0 {
+			continue
+		}
+		left, err := units.Sub(model.MaterialQuantity{Quantity: inv.quantity, Unit: inv.unit}, cm.quantity)
+		if err != nil || left.Quantity < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// cocktail returns the cocktail unless it is missing or deleted. The caller must hold the lock.
+func (s *Store) cocktail(id int64) (*cocktailRow, bool) {
+	c, ok := s.cocktails[id]
+	if !ok || c.deletedAt != 0 {
+		return nil, false
+	}
+	return c, true
+}
+
+// cocktailIDs returns the ids of the cocktails which are not deleted.
+func (s *Store) cocktailIDs() []int64 {
+	ids := make([]int64, 0, len(s.cocktails))
+	for id, c := range s.cocktails {
+		if c.deletedAt == 0 {
+			ids = append(ids, id)
+		}
+	}
+	return sortIDs(ids)
+}
+
+func (s *Store) materialIDs() []int64 {
+	ids := make([]int64, 0, len(s.materials))
+	for id := range s.materials {
+		ids = append(ids, id)
+	}
+	return sortIDs(ids)
+}
+
+func (s *Store) aliasIDs() []int64 {
+	ids := make([]int64, 0, len(s.aliases))
+	for id := range s.aliases {
+		ids = append(ids, id)
+	}
+	return sortIDs(ids)
+}
+
+func (s *Store) orderIDs() []int64 {
+	ids := make([]int64, 0, len(s.orders))
+	for id := range s.orders {
+		ids = append(ids, id)
+	}
+	return sortIDs(ids)
+}
+
+func sortIDs(ids []int64) []int64 {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// rankByKeyword keeps the cocktails whose name or reading contains the keyword once normalized,
+// putting the ones starting with it first and keeping the order otherwise.
+func rankByKeyword(cocktails []model.Cocktail, keyword string) []model.Cocktail {
+	ranked := []model.Cocktail{}
+	for rank := 0; rank <= 1; rank++ {
+		for _, c := range cocktails {
+			if normalize.Rank(keyword, c.Name, c.Reading) == rank {
+				ranked = append(ranked, c)
+			}
+		}
+	}
+	return ranked
+}
+
+// paginate returns the bounds of the page within n rows, like LIMIT ? OFFSET ? does.
+func paginate(n int, limit int64, offset int64) (int, int) {
+	start := int(offset)
+	if start > n || start < 0 {
+		start = n
+	}
+	end := n
+	if limit >= 0 && start+int(limit) < n {
+		end = start + int(limit)
+	}
+	return start, end
+}
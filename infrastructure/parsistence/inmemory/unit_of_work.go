@@ -0,0 +1,36 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/shake551/cocktails-api/domain/repository"
+)
+
+// UnitOfWork rolls back by restoring a snapshot of the Store taken before fn.
+// Units of work run one at a time, but a write made outside of them while fn runs is lost on rollback.
+type UnitOfWork struct {
+	s  *Store
+	mu sync.Mutex
+}
+
+func NewUnitOfWork(s *Store) *UnitOfWork {
+	return &UnitOfWork{s: s}
+}
+
+func (u *UnitOfWork) Do(ctx context.Context, fn func(repos repository.Repositories) error) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	snapshot := u.s.snapshot()
+	err := fn(repository.Repositories{
+		Cocktail: NewCocktailRepository(u.s),
+		Material: NewMaterialRepository(u.s),
+		Shop:     NewShopRepository(u.s),
+	})
+	if err != nil {
+		u.s.restore(snapshot)
+	}
+
+	return err
+}
@@ -0,0 +1,506 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func createCocktail(t *testing.T, r repository.CocktailRepository, name string, materials ...string) *model.CocktailDetail {
+	params := model.CocktailParams{Name: name}
+	for _, m := range materials {
+		params.Materials = append(params.Materials, model.MaterialParams{Name: m, Quantity: model.MaterialQuantity{Quantity: 30, Unit: "ml"}})
+	}
+
+	c, err := r.Create(context.Background(), params)
+	assert.Nil(t, err)
+	return c
+}
+
+func testCocktailGetLimit(t *testing.T, b Backend) {
+	r := b.Cocktail
+	createCocktail(t, r, "カルーアミルク", "カルーア", "牛乳")
+	createCocktail(t, r, "スコッチ・オーレ", "スコッチ", "牛乳")
+	createCocktail(t, r, "ジントニック", "ジン", "トニックウォーター")
+	createCocktail(t, r, "シャーリー・テンプル", "ジンジャーエール", "グレナデン・シロップ")
+
+	type testcase struct {
+		Name   string
+		Limit  int64
+		Offset int64
+		Filter model.CocktailFilter
+		Want   []int64
+	}
+
+	tests := []testcase{
+		{Name: "all", Limit: 10, Want: []int64{1, 2, 3, 4}},
+		{Name: "paged", Limit: 1, Offset: 1, Want: []int64{2}},
+		{Name: "keyword", Limit: 10, Filter: model.CocktailFilter{Keyword: "ミルク"}, Want: []int64{1}},
+		{Name: "material", Limit: 10, Filter: model.CocktailFilter{Materials: []string{"牛乳"}}, Want: []int64{1, 2}},
+		{Name: "exclude material", Limit: 10, Filter: model.CocktailFilter{Materials: []string{"牛乳"}, ExcludeMaterials: []string{"カルーア"}}, Want: []int64{2}},
+		{Name: "whole material name", Limit: 10, Filter: model.CocktailFilter{Materials: []string{"ジン"}}, Want: []int64{3}},
+		{Name: "exclude whole material name", Limit: 10, Filter: model.CocktailFilter{ExcludeMaterials: []string{"ジン"}}, Want: []int64{1, 2, 4}},
+		{Name: "out of range", Limit: 10, Offset: 5, Want: []int64{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			cocktails, err := r.GetLimit(context.Background(), tc.Limit, tc.Offset, tc.Filter)
+
+			ids := []int64{}
+			for _, c := range cocktails {
+				ids = append(ids, c.ID)
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, ids)
+		})
+	}
+}
+
+func testCocktailGetLimitKeyword(t *testing.T, b Backend) {
+	r := b.Cocktail
+	createCocktail(t, r, "ピンク・ジン", "ジン", "アンゴスチュラ・ビターズ")
+	createCocktail(t, r, "ジントニック", "ジン", "トニックウォーター")
+	createCocktail(t, r, "スコッチ・オーレ", "スコッチ", "牛乳")
+	_, err := r.Create(context.Background(), model.CocktailParams{
+		Name:      "雪国",
+		Reading:   "ゆきぐに",
+		Materials: []model.MaterialParams{{Name: "ウォッカ"}},
+	})
+	assert.Nil(t, err)
+
+	type testcase struct {
+		Name    string
+		Keyword string
+		Limit   int64
+		Offset  int64
+		Want    []int64
+	}
+
+	tests := []testcase{
+		{Name: "hiragana", Keyword: "すこっち", Limit: 10, Want: []int64{3}},
+		{Name: "half-width katakana", Keyword: "ｵｰﾚ", Limit: 10, Want: []int64{3}},
+		{Name: "reading", Keyword: "ゆき", Limit: 10, Want: []int64{4}},
+		{Name: "prefix first", Keyword: "じん", Limit: 10, Want: []int64{2, 1}},
+		{Name: "paged after ranking", Keyword: "じん", Limit: 1, Offset: 1, Want: []int64{1}},
+		{Name: "no match", Keyword: "てきーら", Limit: 10, Want: []int64{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			cocktails, err := r.GetLimit(context.Background(), tc.Limit, tc.Offset, model.CocktailFilter{Keyword: tc.Keyword})
+
+			ids := []int64{}
+			for _, c := range cocktails {
+				ids = append(ids, c.ID)
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, ids)
+		})
+	}
+
+	// a renamed cocktail is found by its new name only
+	_, err = r.Update(context.Background(), 3, model.CocktailParams{Name: "ゴッドファーザー", Materials: []model.MaterialParams{{Name: "スコッチ"}}})
+	assert.Nil(t, err)
+	cocktails, err := r.GetLimit(context.Background(), 10, 0, model.CocktailFilter{Keyword: "ごっど"})
+	assert.Nil(t, err)
+	assert.Len(t, cocktails, 1)
+	cocktails, err = r.GetLimit(context.Background(), 10, 0, model.CocktailFilter{Keyword: "すこっち"})
+	assert.Nil(t, err)
+	assert.Empty(t, cocktails)
+}
+
+func testCocktailCreateSharesMaterials(t *testing.T, b Backend) {
+	r := b.Cocktail
+	first := createCocktail(t, r, "カルーアミルク", "カルーア", "牛乳")
+	second := createCocktail(t, r, "スコッチ・オーレ", "スコッチ", "牛乳")
+
+	assert.Equal(t, first.Materials[1].ID, second.Materials[1].ID)
+
+	m, err := b.Material.GetByID(context.Background(), first.Materials[1].ID)
+	assert.Nil(t, err)
+	assert.Len(t, m.Cocktails, 2)
+}
+
+func testCocktailFractionalQuantity(t *testing.T, b Backend) {
+	r := b.Cocktail
+	ctx := context.Background()
+
+	c, err := r.Create(ctx, model.CocktailParams{
+		Name: "ダイキリ",
+		Materials: []model.MaterialParams{
+			{Name: "ラム", Quantity: model.MaterialQuantity{Quantity: 1.5, Unit: "oz"}},
+			{Name: "シュガーシロップ", Quantity: model.MaterialQuantity{Quantity: 0.25, Unit: "oz"}},
+		},
+	})
+	assert.Nil(t, err)
+
+	got, err := r.GetByID(ctx, c.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1.5, got.Materials[0].Quantity.Quantity)
+	assert.Equal(t, 0.25, got.Materials[1].Quantity.Quantity)
+}
+
+func testCocktailGetByIDNotFound(t *testing.T, b Backend) {
+	r := b.Cocktail
+
+	_, err := r.GetByID(context.Background(), 1)
+
+	assert.ErrorIs(t, err, repository.ErrCocktailNotFound)
+}
+
+func testCocktailGetByIDWithoutMaterials(t *testing.T, b Backend) {
+	r := b.Cocktail
+	ctx := context.Background()
+	c, err := r.Create(ctx, model.CocktailParams{Name: "水割り"})
+	assert.Nil(t, err)
+
+	got, err := r.GetByID(ctx, c.ID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "水割り", got.Name)
+	assert.Equal(t, []model.Material{}, got.Materials)
+	assert.NotZero(t, got.CreatedAt)
+}
+
+func testCocktailGetMakeable(t *testing.T, b Backend) {
+	r := b.Cocktail
+	createCocktail(t, r, "カルーアミルク", "カルーア", "牛乳")
+	createCocktail(t, r, "ジントニック", "ジン", "トニックウォーター")
+
+	cocktails, err := r.GetMakeable(context.Background(), nil, []string{"牛乳"}, 1)
+
+	assert.Nil(t, err)
+	assert.Len(t, cocktails, 1)
+	assert.Equal(t, "カルーアミルク", cocktails[0].Cocktail.Name)
+	assert.Equal(t, "カルーア", cocktails[0].MissingMaterials[0].Name)
+}
+
+func testMaterialCreateDuplicate(t *testing.T, b Backend) {
+	createCocktail(t, b.Cocktail, "カルーアミルク", "カルーア", "牛乳")
+
+	_, err := b.Material.Create(context.Background(), model.MaterialNameParams{Name: "牛乳"})
+
+	assert.ErrorIs(t, err, repository.ErrMaterialDuplicate)
+}
+
+func testMaterialMatchesNormalizedNamesAndAliases(t *testing.T, b Backend) {
+	ctx := context.Background()
+	cr := b.Cocktail
+	mr := b.Material
+	first := createCocktail(t, cr, "ジントニック", "ジン", "トニックウォーター")
+
+	alias, err := mr.AddAlias(ctx, first.Materials[0].ID, model.MaterialNameParams{Name: "Gin"})
+	assert.Nil(t, err)
+
+	second := createCocktail(t, cr, "ギムレット", "ｼﾞﾝ", "ライムジュース")
+	third := createCocktail(t, cr, "ジンリッキー", "ＧＩＮ", "ソーダ")
+	assert.Equal(t, first.Materials[0].ID, second.Materials[0].ID)
+	assert.Equal(t, first.Materials[0].ID, third.Materials[0].ID)
+
+	// filters name materials the same way
+	cocktails, err := cr.GetLimit(ctx, 10, 0, model.CocktailFilter{Materials: []string{"gin"}})
+	assert.Nil(t, err)
+	assert.Len(t, cocktails, 3)
+	cocktails, err = cr.GetLimit(ctx, 10, 0, model.CocktailFilter{ExcludeMaterials: []string{"じん"}})
+	assert.Nil(t, err)
+	assert.Empty(t, cocktails)
+	makeable, err := cr.GetMakeable(ctx, nil, []string{"GIN", "そーだ"}, 0)
+	assert.Nil(t, err)
+	assert.Len(t, makeable, 1)
+	assert.Equal(t, "ジンリッキー", makeable[0].Cocktail.Name)
+
+	_, err = mr.Create(ctx, model.MaterialNameParams{Name: "じん"})
+	assert.ErrorIs(t, err, repository.ErrMaterialDuplicate)
+	_, err = mr.AddAlias(ctx, first.Materials[1].ID, model.MaterialNameParams{Name: "gin"})
+	assert.ErrorIs(t, err, repository.ErrMaterialDuplicate)
+	_, err = mr.Rename(ctx, first.Materials[0].ID, model.MaterialNameParams{Name: "ｼﾞﾝ"})
+	assert.Nil(t, err)
+
+	assert.Nil(t, mr.DeleteAlias(ctx, first.Materials[0].ID, alias.ID))
+	assert.ErrorIs(t, mr.DeleteAlias(ctx, first.Materials[0].ID, alias.ID), repository.ErrMaterialAliasNotFound)
+
+	m, err := mr.GetByID(ctx, first.Materials[0].ID)
+	assert.Nil(t, err)
+	assert.Empty(t, m.Aliases)
+}
+
+func testMaterialGetLimitKeyword(t *testing.T, b Backend) {
+	ctx := context.Background()
+	createCocktail(t, b.Cocktail, "ジントニック", "ジン", "トニックウォーター")
+	createCocktail(t, b.Cocktail, "シャーリー・テンプル", "ジンジャーエール", "グレナデン・シロップ")
+	createCocktail(t, b.Cocktail, "ジンバック", "Dry Gin")
+
+	type testcase struct {
+		Keyword string
+		Want    []string
+	}
+
+	tests := []testcase{
+		{Keyword: "じん", Want: []string{"ジン", "ジンジャーエール"}},
+		{Keyword: "ｳｫｰﾀｰ", Want: []string{"トニックウォーター"}},
+		{Keyword: "GIN", Want: []string{"Dry Gin"}},
+		{Keyword: "グレナデンシロップ", Want: []string{"グレナデン・シロップ"}},
+		{Keyword: "ウォッカ", Want: []string{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Keyword, func(t *testing.T) {
+			materials, err := b.Material.GetLimit(ctx, 10, 0, tc.Keyword)
+			assert.Nil(t, err)
+			names := []string{}
+			for _, m := range materials {
+				names = append(names, m.Name)
+			}
+			assert.Equal(t, tc.Want, names)
+		})
+	}
+}
+
+func testMaterialMerge(t *testing.T, b Backend) {
+	sr := newMenu(t, b)
+	ctx := context.Background()
+	cr := b.Cocktail
+	mr := b.Material
+	createCocktail(t, cr, "カルーアミルク(ロング)", "カルーア", "ミルク")
+	_, err := sr.UpdateInventory(ctx, 1, model.InventoryParams{Materials: []model.InventoryMaterialParams{
+		{MaterialID: 2, Quantity: 500, Unit: "ml"},
+		{MaterialID: 3, Quantity: 200, Unit: "ml"},
+	}})
+	assert.Nil(t, err)
+	_, err = mr.AddAlias(ctx, 3, model.MaterialNameParams{Name: "Milk"})
+	assert.Nil(t, err)
+
+	d, err := mr.Merge(ctx, 3, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, "牛乳", d.Name)
+	assert.Len(t, d.Cocktails, 2)
+	names := []string{}
+	for _, a := range d.Aliases {
+		names = append(names, a.Name)
+	}
+	assert.Equal(t, []string{"Milk", "ミルク"}, names)
+
+	_, err = mr.GetByID(ctx, 3)
+	assert.ErrorIs(t, err, repository.ErrMaterialNotFound)
+
+	inventory, err := sr.GetInventory(ctx, 1)
+	assert.Nil(t, err)
+	assert.Len(t, inventory, 1)
+	assert.Equal(t, 700.0, inventory[0].Quantity)
+
+	c := createCocktail(t, cr, "ホワイトルシアン", "ウォッカ", "カルーア", "みるく")
+	assert.Equal(t, int64(2), c.Materials[2].ID)
+
+	_, err = mr.Merge(ctx, 3, 2)
+	assert.ErrorIs(t, err, repository.ErrMaterialNotFound)
+}
+
+func testMaterialMergeAddsUpQuantities(t *testing.T, b Backend) {
+	sr := newMenu(t, b)
+	ctx := context.Background()
+	cr := b.Cocktail
+	mr := b.Material
+	c, err := cr.Create(ctx, model.CocktailParams{Name: "ミルクセーキ", Materials: []model.MaterialParams{
+		{Name: "牛乳", Quantity: model.MaterialQuantity{Quantity: 30, Unit: "ml"}},
+		{Name: "卵", Quantity: model.MaterialQuantity{Quantity: 1, Unit: "個"}},
+		{Name: "ミルク", Quantity: model.MaterialQuantity{Quantity: 1, Unit: "oz"}},
+	}})
+	assert.Nil(t, err)
+	milk := c.Materials[2].ID
+	_, err = sr.UpdateInventory(ctx, 1, model.InventoryParams{Materials: []model.InventoryMaterialParams{
+		{MaterialID: 2, Quantity: 1, Unit: "l"},
+		{MaterialID: milk, Quantity: 200, Unit: "ml"},
+	}})
+	assert.Nil(t, err)
+
+	_, err = mr.Merge(ctx, milk, 2)
+	assert.Nil(t, err)
+
+	// the recipe keeps one row of milk, and no stock is lost
+	got, err := cr.GetByID(ctx, c.ID)
+	assert.Nil(t, err)
+	assert.Len(t, got.Materials, 2)
+	assert.Equal(t, int64(2), got.Materials[0].ID)
+	assert.Equal(t, model.MaterialQuantity{Quantity: 60, Unit: "ml"}, got.Materials[0].Quantity)
+	inventory, err := sr.GetInventory(ctx, 1)
+	assert.Nil(t, err)
+	assert.Len(t, inventory, 1)
+	assert.Equal(t, 1.2, inventory[0].Quantity)
+	assert.Equal(t, "l", inventory[0].Unit)
+
+	// stock which cannot be converted refuses the merge and is left as it was
+	_, err = sr.UpdateInventory(ctx, 1, model.InventoryParams{Materials: []model.InventoryMaterialParams{
+		{MaterialID: 1, Quantity: 2, Unit: "本"},
+		{MaterialID: 2, Quantity: 1, Unit: "l"},
+	}})
+	assert.Nil(t, err)
+	_, err = mr.Merge(ctx, 2, 1)
+	assert.ErrorIs(t, err, repository.ErrMaterialUnitsDiffer)
+	inventory, err = sr.GetInventory(ctx, 1)
+	assert.Nil(t, err)
+	assert.Len(t, inventory, 2)
+	_, err = mr.GetByID(ctx, 2)
+	assert.Nil(t, err)
+}
+
+func testUnitOfWorkRollsBack(t *testing.T, b Backend) {
+	ctx := context.Background()
+	createCocktail(t, b.Cocktail, "カルーアミルク", "カルーア", "牛乳")
+
+	err := b.UnitOfWork.Do(ctx, func(repos repository.Repositories) error {
+		_, err := repos.Cocktail.Update(ctx, 1, model.CocktailParams{Name: "ホワイトルシアン", Materials: []model.MaterialParams{{Name: "ウォッカ"}}})
+		assert.Nil(t, err)
+		return repository.ErrMaterialDuplicate
+	})
+	assert.ErrorIs(t, err, repository.ErrMaterialDuplicate)
+
+	c, err := b.Cocktail.GetByID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "カルーアミルク", c.Name)
+	assert.Len(t, c.Materials, 2)
+
+	materials, err := b.Material.GetLimit(ctx, 10, 0, "ウォッカ")
+	assert.Nil(t, err)
+	assert.Empty(t, materials)
+}
+
+func testCocktailGetLimitMaxABV(t *testing.T, b Backend) {
+	ctx := context.Background()
+	cr := b.Cocktail
+	mr := b.Material
+	_, err := cr.Create(ctx, model.CocktailParams{
+		Name:   "カルーアミルク",
+		Method: model.MethodBuild,
+		Materials: []model.MaterialParams{
+			{Name: "カルーア", Quantity: model.MaterialQuantity{Quantity: 45, Unit: "ml"}},
+			{Name: "牛乳", Quantity: model.MaterialQuantity{Quantity: 90, Unit: "ml"}},
+		},
+	})
+	assert.Nil(t, err)
+	_, err = cr.Create(ctx, model.CocktailParams{
+		Name:   "マティーニ",
+		Method: model.MethodStir,
+		Materials: []model.MaterialParams{
+			{Name: "ジン", Quantity: model.MaterialQuantity{Quantity: 50, Unit: "ml"}},
+			{Name: "ドライ・ベルモット", Quantity: model.MaterialQuantity{Quantity: 10, Unit: "ml"}},
+		},
+	})
+	assert.Nil(t, err)
+	createCocktail(t, cr, "ジントニック", "ジン", "トニックウォーター")
+	createCocktail(t, cr, "シャーリー・テンプル", "ジンジャーエール", "グレナデン・シロップ")
+
+	for id, abv := range map[int64]float64{1: 20, 3: 40, 4: 18} {
+		v := abv
+		_, err := mr.UpdateABV(ctx, id, model.MaterialABVParams{ABV: &v})
+		assert.Nil(t, err)
+	}
+	_, err = mr.UpdateABV(ctx, 100, model.MaterialABVParams{})
+	assert.ErrorIs(t, err, repository.ErrMaterialNotFound)
+
+	d, err := cr.GetByID(ctx, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 40.0, *d.Materials[0].ABV)
+
+	type testcase struct {
+		Name   string
+		MaxABV float64
+		Limit  int64
+		Offset int64
+		Want   []int64
+	}
+
+	// the cocktail without any known ABV is never listed
+	tests := []testcase{
+		{Name: "weak only", MaxABV: 10, Limit: 10, Want: []int64{1}},
+		{Name: "all estimated", MaxABV: 100, Limit: 10, Want: []int64{1, 2, 3}},
+		{Name: "paged after filtering", MaxABV: 100, Limit: 1, Offset: 1, Want: []int64{2}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			maxABV := tc.MaxABV
+			cocktails, err := cr.GetLimit(ctx, tc.Limit, tc.Offset, model.CocktailFilter{MaxABV: &maxABV})
+
+			ids := []int64{}
+			for _, c := range cocktails {
+				ids = append(ids, c.ID)
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, ids)
+		})
+	}
+}
+
+func testCocktailUpdateImageURL(t *testing.T, b Backend) {
+	r := b.Cocktail
+	ctx := context.Background()
+	c := createCocktail(t, r, "カルーアミルク", "カルーア", "牛乳")
+
+	assert.Nil(t, r.UpdateImageURL(ctx, c.ID, "/images/1"))
+
+	got, err := r.GetByID(ctx, c.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "/images/1", got.ImageURL)
+	assert.Len(t, got.Materials, 2)
+
+	assert.ErrorIs(t, r.UpdateImageURL(ctx, c.ID+1, "/images/1"), repository.ErrCocktailNotFound)
+}
+
+func testImageRepository(t *testing.T, b Backend) {
+	r := b.Image
+	ctx := context.Background()
+	data := []byte("\x89PNG\r\n\x1a\n\x00\xff")
+
+	id, err := r.Create(ctx, data)
+	assert.Nil(t, err)
+
+	got, err := r.Get(ctx, id)
+	assert.Nil(t, err)
+	assert.Equal(t, data, got)
+
+	assert.Nil(t, r.Delete(ctx, id))
+	_, err = r.Get(ctx, id)
+	assert.ErrorIs(t, err, repository.ErrImageNotFound)
+	assert.ErrorIs(t, r.Delete(ctx, id), repository.ErrImageNotFound)
+}
+
+func testImageRepositoryVariants(t *testing.T, b Backend) {
+	r := b.Image
+	ctx := context.Background()
+
+	id, err := r.Create(ctx, []byte("image"))
+	assert.Nil(t, err)
+
+	other, err := r.Create(ctx, []byte("other"))
+	assert.Nil(t, err)
+
+	_, err = r.GetVariant(ctx, id, "medium.jpg")
+	assert.ErrorIs(t, err, repository.ErrImageNotFound)
+	without, err := r.WithoutVariant(ctx, "thumbnail.jpg")
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{id, other}, without)
+
+	assert.Nil(t, r.PutVariants(ctx, id, map[string][]byte{"medium.jpg": []byte("old"), "thumbnail.jpg": []byte("jpeg")}))
+	without, err = r.WithoutVariant(ctx, "thumbnail.jpg")
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{other}, without)
+	assert.Nil(t, r.PutVariants(ctx, id, map[string][]byte{"medium.jpg": []byte("new")}))
+
+	got, err := r.GetVariant(ctx, id, "medium.jpg")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("new"), got)
+	got, err = r.GetVariant(ctx, id, "thumbnail.jpg")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("jpeg"), got)
+	_, err = r.GetVariant(ctx, id+1, "medium.jpg")
+	assert.ErrorIs(t, err, repository.ErrImageNotFound)
+
+	// deleting the image deletes its variants too
+	assert.Nil(t, r.Delete(ctx, id))
+	_, err = r.GetVariant(ctx, id, "medium.jpg")
+	assert.ErrorIs(t, err, repository.ErrImageNotFound)
+}
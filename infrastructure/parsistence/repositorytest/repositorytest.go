@@ -0,0 +1,63 @@
+// Package repositorytest is the contract every repository backend has to keep.
+// Each backend runs the same suite from its own tests.
+package repositorytest
+
+import (
+	"testing"
+
+	"github.com/shake551/cocktails-api/domain/repository"
+)
+
+// Backend is one set of repositories sharing the same storage.
+type Backend struct {
+	Cocktail   repository.CocktailRepository
+	Material   repository.MaterialRepository
+	Shop       repository.ShopRepository
+	Image      repository.ImageRepository
+	UnitOfWork repository.UnitOfWork
+}
+
+// Run runs the whole suite, calling open for an empty backend in every test.
+func Run(t *testing.T, open func(t *testing.T) Backend) {
+	tests := []struct {
+		Name string
+		Test func(t *testing.T, b Backend)
+	}{
+		{"CocktailGetLimit", testCocktailGetLimit},
+		{"CocktailGetLimitKeyword", testCocktailGetLimitKeyword},
+		{"CocktailCreateSharesMaterials", testCocktailCreateSharesMaterials},
+		{"CocktailFractionalQuantity", testCocktailFractionalQuantity},
+		{"CocktailGetByIDNotFound", testCocktailGetByIDNotFound},
+		{"CocktailGetByIDWithoutMaterials", testCocktailGetByIDWithoutMaterials},
+		{"CocktailGetMakeable", testCocktailGetMakeable},
+		{"CocktailGetLimitMaxABV", testCocktailGetLimitMaxABV},
+		{"CocktailUpdateImageURL", testCocktailUpdateImageURL},
+		{"CocktailPreparation", testCocktailPreparation},
+		{"ShopCocktailDetailRecipe", testShopCocktailDetailRecipe},
+		{"MaterialCreateDuplicate", testMaterialCreateDuplicate},
+		{"MaterialMatchesNormalizedNamesAndAliases", testMaterialMatchesNormalizedNamesAndAliases},
+		{"MaterialGetLimitKeyword", testMaterialGetLimitKeyword},
+		{"MaterialMerge", testMaterialMerge},
+		{"MaterialMergeAddsUpQuantities", testMaterialMergeAddsUpQuantities},
+		{"UnitOfWorkRollsBack", testUnitOfWorkRollsBack},
+		{"ImageRepository", testImageRepository},
+		{"ImageRepositoryVariants", testImageRepositoryVariants},
+		{"OrderDeductsInventory", testOrderDeductsInventory},
+		{"OrderIsAtomic", testOrderIsAtomic},
+		{"OrderInventoryUnitMismatch", testOrderInventoryUnitMismatch},
+		{"OrderConvertsInventoryUnits", testOrderConvertsInventoryUnits},
+		{"OrderSoldOut", testOrderSoldOut},
+		{"UpdateOrderStatus", testUpdateOrderStatus},
+		{"CancelOrderRestoresInventory", testCancelOrderRestoresInventory},
+		{"CancelOrderAfterRecipeChange", testCancelOrderAfterRecipeChange},
+		{"DeleteCocktailInUse", testDeleteCocktailInUse},
+		{"GetTableNotFound", testGetTableNotFound},
+		{"OrderTableOfAnotherShop", testOrderTableOfAnotherShop},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Test(t, open(t))
+		})
+	}
+}
@@ -0,0 +1,370 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMenu prepares a shop with one table and a カルーアミルク on its menu.
+func newMenu(t *testing.T, b Backend) repository.ShopRepository {
+	ctx := context.Background()
+	createCocktail(t, b.Cocktail, "カルーアミルク", "カルーア", "牛乳")
+
+	r := b.Shop
+	_, err := r.Create(ctx, model.ShopParams{Name: "bar"})
+	assert.Nil(t, err)
+	_, err = r.AddTable(ctx, 1)
+	assert.Nil(t, err)
+	_, err = r.AddShopCocktail(ctx, 1, model.ShopCocktailParams{CocktailIDs: []int64{1}})
+	assert.Nil(t, err)
+	_, err = r.UpdateShopCocktailPrice(ctx, 1, 1, model.ShopCocktailPriceParams{Price: 700})
+	assert.Nil(t, err)
+
+	return r
+}
+
+func testOrderDeductsInventory(t *testing.T, b Backend) {
+	r := newMenu(t, b)
+	ctx := context.Background()
+	_, err := r.UpdateInventory(ctx, 1, model.InventoryParams{Materials: []model.InventoryMaterialParams{{MaterialID: 1, Quantity: 50, Unit: "ml"}}})
+	assert.Nil(t, err)
+
+	orders, err := r.Order(ctx, 1, 1, model.OrderParams{CocktailIDs: []int64{1}})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(700), orders[0].Price)
+	assert.Equal(t, model.OrderStatusAccepted, orders[0].Status)
+
+	inventory, err := r.GetInventory(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 20.0, inventory[0].Quantity)
+
+	_, err = r.Order(ctx, 1, 1, model.OrderParams{CocktailIDs: []int64{1}})
+	assert.ErrorIs(t, err, repository.ErrOutOfStock)
+
+	menu, err := r.GetShopCocktailList(ctx, 1, 10, 0)
+	assert.Nil(t, err)
+	assert.False(t, menu[0].InStock)
+}
+
+func testOrderIsAtomic(t *testing.T, b Backend) {
+	r := newMenu(t, b)
+	ctx := context.Background()
+	_, err := r.UpdateInventory(ctx, 1, model.InventoryParams{Materials: []model.InventoryMaterialParams{{MaterialID: 1, Quantity: 50, Unit: "ml"}}})
+	assert.Nil(t, err)
+
+	_, err = r.Order(ctx, 1, 1, model.OrderParams{CocktailIDs: []int64{1, 1}})
+	assert.ErrorIs(t, err, repository.ErrOutOfStock)
+
+	inventory, err := r.GetInventory(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 50.0, inventory[0].Quantity)
+
+	orders, err := r.GetTableOrderList(ctx, 1, 1, false)
+	assert.Nil(t, err)
+	assert.Empty(t, orders)
+}
+
+func testOrderInventoryUnitMismatch(t *testing.T, b Backend) {
+	r := newMenu(t, b)
+	ctx := context.Background()
+	// the ice is used without a quantity, so its stock is never compared with the recipe
+	_, err := b.Cocktail.Update(ctx, 1, model.CocktailParams{Name: "カルーアミルク", Materials: []model.MaterialParams{
+		{Name: "カルーア", Quantity: model.MaterialQuantity{Quantity: 30, Unit: "ml"}},
+		{Name: "牛乳", Quantity: model.MaterialQuantity{Quantity: 30, Unit: "ml"}},
+		{Name: "氷"},
+	}})
+	assert.Nil(t, err)
+	_, err = r.UpdateInventory(ctx, 1, model.InventoryParams{Materials: []model.InventoryMaterialParams{
+		{MaterialID: 1, Quantity: 50, Unit: "ml"},
+		{MaterialID: 2, Quantity: 1, Unit: "本"},
+		{MaterialID: 3, Quantity: 10, Unit: "個"},
+	}})
+	assert.Nil(t, err)
+
+	_, err = r.Order(ctx, 1, 1, model.OrderParams{CocktailIDs: []int64{1}})
+	assert.ErrorIs(t, err, repository.ErrInventoryUnitMismatch)
+
+	inventory, err := r.GetInventory(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 50.0, inventory[0].Quantity)
+	menu, err := r.GetShopCocktailList(ctx, 1, 10, 0)
+	assert.Nil(t, err)
+	assert.False(t, menu[0].InStock)
+
+	_, err = r.UpdateInventory(ctx, 1, model.InventoryParams{Materials: []model.InventoryMaterialParams{{MaterialID: 2, Quantity: 500, Unit: "ml"}}})
+	assert.Nil(t, err)
+	menu, err = r.GetShopCocktailList(ctx, 1, 10, 0)
+	assert.Nil(t, err)
+	assert.True(t, menu[0].InStock)
+
+	_, err = r.Order(ctx, 1, 1, model.OrderParams{CocktailIDs: []int64{1}})
+	assert.Nil(t, err)
+
+	inventory, err = r.GetInventory(ctx, 1)
+	assert.Nil(t, err)
+	quantities := map[int64]float64{}
+	for _, inv := range inventory {
+		quantities[inv.MaterialID] = inv.Quantity
+	}
+	assert.Equal(t, map[int64]float64{1: 20, 2: 470, 3: 10}, quantities)
+}
+
+func testOrderConvertsInventoryUnits(t *testing.T, b Backend) {
+	r := newMenu(t, b)
+	ctx := context.Background()
+	// the recipe is in millilitres while the stock is counted in ounces and litres
+	_, err := r.UpdateInventory(ctx, 1, model.InventoryParams{Materials: []model.InventoryMaterialParams{
+		{MaterialID: 1, Quantity: 1.5, Unit: "oz"},
+		{MaterialID: 2, Quantity: 1, Unit: "L"},
+	}})
+	assert.Nil(t, err)
+
+	menu, err := r.GetShopCocktailList(ctx, 1, 10, 0)
+	assert.Nil(t, err)
+	assert.True(t, menu[0].InStock)
+
+	_, err = r.Order(ctx, 1, 1, model.OrderParams{CocktailIDs: []int64{1}})
+	assert.Nil(t, err)
+
+	quantities := func() map[int64]float64 {
+		inventory, err := r.GetInventory(ctx, 1)
+		assert.Nil(t, err)
+		q := map[int64]float64{}
+		for _, inv := range inventory {
+			q[inv.MaterialID] = inv.Quantity
+		}
+		return q
+	}
+	got := quantities()
+	assert.InDelta(t, 0.5, got[1], 1e-9)
+	assert.InDelta(t, 0.97, got[2], 1e-9)
+
+	// half an ounce is 15 ml, less than the recipe needs
+	menu, err = r.GetShopCocktailList(ctx, 1, 10, 0)
+	assert.Nil(t, err)
+	assert.False(t, menu[0].InStock)
+	_, err = r.Order(ctx, 1, 1, model.OrderParams{CocktailIDs: []int64{1}})
+	assert.ErrorIs(t, err, repository.ErrOutOfStock)
+
+	assert.Nil(t, r.UpdateOrderStatus(ctx, 1, model.OrderStatusAccepted, model.OrderStatusCancelled, 100))
+	assert.Nil(t, r.RestoreInventory(ctx, 1, 1, 100))
+	got = quantities()
+	assert.InDelta(t, 1.5, got[1], 1e-9)
+	assert.InDelta(t, 1, got[2], 1e-9)
+}
+
+func testOrderSoldOut(t *testing.T, b Backend) {
+	r := newMenu(t, b)
+	ctx := context.Background()
+	_, err := r.UpdateShopCocktailAvailability(ctx, 1, 1, model.ShopCocktailAvailability{SoldOut: true})
+	assert.Nil(t, err)
+
+	_, err = r.Order(ctx, 1, 1, model.OrderParams{CocktailIDs: []int64{1}})
+
+	assert.ErrorIs(t, err, repository.ErrSoldOut)
+}
+
+func testUpdateOrderStatus(t *testing.T, b Backend) {
+	r := newMenu(t, b)
+	ctx := context.Background()
+	_, err := r.Order(ctx, 1, 1, model.OrderParams{CocktailIDs: []int64{1}})
+	assert.Nil(t, err)
+
+	assert.Nil(t, r.UpdateOrderStatus(ctx, 1, model.OrderStatusAccepted, model.OrderStatusServed, 100))
+	assert.ErrorIs(t, r.UpdateOrderStatus(ctx, 1, model.OrderStatusAccepted, model.OrderStatusCancelled, 101), repository.ErrInvalidOrderTransition)
+
+	o, err := r.GetOrder(ctx, 1, 1, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, model.OrderStatusServed, o.Status)
+	assert.Equal(t, int64(100), o.ServedAt)
+
+	unprovided, err := r.GetUnprovidedOrderList(ctx, 1, 10, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, unprovided)
+}
+
+func testCancelOrderRestoresInventory(t *testing.T, b Backend) {
+	r := newMenu(t, b)
+	ctx := context.Background()
+	_, err := r.UpdateInventory(ctx, 1, model.InventoryParams{Materials: []model.InventoryMaterialParams{{MaterialID: 1, Quantity: 50, Unit: "ml"}}})
+	assert.Nil(t, err)
+	_, err = r.Order(ctx, 1, 1, model.OrderParams{CocktailIDs: []int64{1}})
+	assert.Nil(t, err)
+
+	err = b.UnitOfWork.Do(ctx, func(repos repository.Repositories) error {
+		if err := repos.Shop.UpdateOrderStatus(ctx, 1, model.OrderStatusAccepted, model.OrderStatusCancelled, 100); err != nil {
+			return err
+		}
+		return repos.Shop.RestoreInventory(ctx, 1, 1, 100)
+	})
+	assert.Nil(t, err)
+
+	inventory, err := r.GetInventory(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 50.0, inventory[0].Quantity)
+	assert.Equal(t, int64(100), inventory[0].UpdatedAt)
+
+	// an order gives back once, and an untracked shop or unknown order has nothing to give back
+	assert.Nil(t, r.RestoreInventory(ctx, 1, 1, 101))
+	assert.Nil(t, r.RestoreInventory(ctx, 2, 1, 101))
+	assert.Nil(t, r.RestoreInventory(ctx, 1, 2, 101))
+	inventory, err = r.GetInventory(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 50.0, inventory[0].Quantity)
+	assert.Equal(t, int64(100), inventory[0].UpdatedAt)
+}
+
+func testCancelOrderAfterRecipeChange(t *testing.T, b Backend) {
+	r := newMenu(t, b)
+	ctx := context.Background()
+	_, err := r.UpdateInventory(ctx, 1, model.InventoryParams{Materials: []model.InventoryMaterialParams{
+		{MaterialID: 1, Quantity: 50, Unit: "ml"},
+		{MaterialID: 2, Quantity: 100, Unit: "ml"},
+	}})
+	assert.Nil(t, err)
+	_, err = r.Order(ctx, 1, 1, model.OrderParams{CocktailIDs: []int64{1}})
+	assert.Nil(t, err)
+
+	// the recipe changes while the order is open
+	_, err = b.Cocktail.Update(ctx, 1, model.CocktailParams{Name: "カルーアミルク", Materials: []model.MaterialParams{
+		{Name: "カルーア", Quantity: model.MaterialQuantity{Quantity: 45, Unit: "ml"}},
+	}})
+	assert.Nil(t, err)
+
+	assert.Nil(t, r.UpdateOrderStatus(ctx, 1, model.OrderStatusAccepted, model.OrderStatusCancelled, 100))
+	assert.Nil(t, r.RestoreInventory(ctx, 1, 1, 100))
+
+	inventory, err := r.GetInventory(ctx, 1)
+	assert.Nil(t, err)
+	got := map[int64]float64{}
+	for _, inv := range inventory {
+		got[inv.MaterialID] = inv.Quantity
+	}
+	assert.Equal(t, map[int64]float64{1: 50, 2: 100}, got)
+}
+
+func testDeleteCocktailInUse(t *testing.T, b Backend) {
+	r := newMenu(t, b)
+	ctx := context.Background()
+	cr := b.Cocktail
+	_, err := r.Order(ctx, 1, 1, model.OrderParams{CocktailIDs: []int64{1}})
+	assert.Nil(t, err)
+
+	assert.ErrorIs(t, cr.Delete(ctx, 1), repository.ErrCocktailInUse)
+
+	assert.Nil(t, r.UpdateOrderStatus(ctx, 1, model.OrderStatusAccepted, model.OrderStatusServed, 100))
+	assert.Nil(t, cr.Delete(ctx, 1))
+
+	_, err = r.GetShopCocktailDetail(ctx, 1, 1)
+	assert.ErrorIs(t, err, repository.ErrShopCocktailNotFound)
+	_, err = cr.GetByID(ctx, 1)
+	assert.ErrorIs(t, err, repository.ErrCocktailNotFound)
+	cocktails, err := cr.GetLimit(ctx, 10, 0, model.CocktailFilter{})
+	assert.Nil(t, err)
+	assert.Empty(t, cocktails)
+	assert.ErrorIs(t, cr.Delete(ctx, 1), repository.ErrCocktailNotFound)
+
+	// the served order still shows on the bill of the table
+	bill, err := r.GetTableBillOrders(ctx, 1, 1)
+	assert.Nil(t, err)
+	assert.Len(t, bill, 1)
+	assert.Equal(t, "カルーアミルク", bill[0].Name)
+	assert.Equal(t, int64(700), bill[0].Price)
+}
+
+func testGetTableNotFound(t *testing.T, b Backend) {
+	r := newMenu(t, b)
+
+	_, err := r.GetTable(context.Background(), 2, 1)
+
+	assert.ErrorIs(t, err, repository.ErrTableNotFound)
+}
+
+func testOrderTableOfAnotherShop(t *testing.T, b Backend) {
+	r := newMenu(t, b)
+	ctx := context.Background()
+	_, err := r.Create(ctx, model.ShopParams{Name: "pub"})
+	assert.Nil(t, err)
+	_, err = r.AddShopCocktail(ctx, 2, model.ShopCocktailParams{CocktailIDs: []int64{1}})
+	assert.Nil(t, err)
+
+	// table 1 belongs to the first shop
+	_, err = r.Order(ctx, 2, 1, model.OrderParams{CocktailIDs: []int64{1}})
+	assert.ErrorIs(t, err, repository.ErrTableNotFound)
+	_, err = r.Order(ctx, 1, 2, model.OrderParams{CocktailIDs: []int64{1}})
+	assert.ErrorIs(t, err, repository.ErrTableNotFound)
+
+	orders, err := r.GetTableOrderList(ctx, 1, 1, false)
+	assert.Nil(t, err)
+	assert.Empty(t, orders)
+}
+
+func testCocktailPreparation(t *testing.T, b Backend) {
+	sr := newMenu(t, b)
+	ctx := context.Background()
+	cr := b.Cocktail
+
+	c, err := cr.GetByID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, model.Method(""), c.Method)
+	assert.Equal(t, []string{}, c.Steps)
+
+	params := model.CocktailParams{
+		Name:      "カルーアミルク",
+		Materials: []model.MaterialParams{{Name: "カルーア"}, {Name: "牛乳"}},
+		Method:    model.MethodBuild,
+		Glass:     "ロックグラス",
+		Garnish:   "なし",
+		Notes:     "牛乳は冷やしておく",
+		Steps:     []string{"氷を入れたグラスにカルーアを注ぐ", "牛乳を注ぐ", "軽くステアする"},
+	}
+	_, err = cr.Update(ctx, 1, params)
+	assert.Nil(t, err)
+
+	params.Steps = params.Steps[1:]
+	updated, err := cr.Update(ctx, 1, params)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"牛乳を注ぐ", "軽くステアする"}, updated.Steps)
+
+	for _, get := range []func() (model.CocktailDetail, error){
+		func() (model.CocktailDetail, error) { return cr.GetByID(ctx, 1) },
+		func() (model.CocktailDetail, error) { return sr.GetShopCocktailDetail(ctx, 1, 1) },
+	} {
+		d, err := get()
+		assert.Nil(t, err)
+		assert.Equal(t, model.MethodBuild, d.Method)
+		assert.Equal(t, "ロックグラス", d.Glass)
+		assert.Equal(t, "なし", d.Garnish)
+		assert.Equal(t, "牛乳は冷やしておく", d.Notes)
+		assert.Equal(t, []string{"牛乳を注ぐ", "軽くステアする"}, d.Steps)
+	}
+}
+
+func testShopCocktailDetailRecipe(t *testing.T, b Backend) {
+	sr := newMenu(t, b)
+	ctx := context.Background()
+	abv := 20.0
+	_, err := b.Material.UpdateABV(ctx, 1, model.MaterialABVParams{ABV: &abv})
+	assert.Nil(t, err)
+
+	d, err := sr.GetShopCocktailDetail(ctx, 1, 1)
+	assert.Nil(t, err)
+	assert.Len(t, d.Materials, 2)
+	assert.Equal(t, "カルーア", d.Materials[0].Name)
+	assert.Equal(t, model.MaterialQuantity{Quantity: 30, Unit: "ml"}, d.Materials[0].Quantity)
+	assert.Equal(t, 20.0, *d.Materials[0].ABV)
+
+	// a cocktail without materials is on the menu all the same
+	_, err = b.Cocktail.Create(ctx, model.CocktailParams{Name: "水"})
+	assert.Nil(t, err)
+	_, err = sr.AddShopCocktail(ctx, 1, model.ShopCocktailParams{CocktailIDs: []int64{2}})
+	assert.Nil(t, err)
+	d, err = sr.GetShopCocktailDetail(ctx, 1, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, "水", d.Name)
+	assert.Equal(t, []model.Material{}, d.Materials)
+}
@@ -0,0 +1,722 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/shake551/cocktails-api/db"
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/normalize"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/domain/strength"
+)
+
+type CocktailRepository struct {
+	db db.Executor
+}
+
+// NewCocktailRepository returns a repository running its statements on e.
+// Given a transaction, every call joins it instead of committing on its own.
+func NewCocktailRepository(e db.Executor) *CocktailRepository {
+	return &CocktailRepository{db: e}
+}
+
+func (r CocktailRepository) GetLimit(ctx context.Context, limit int64, offset int64, filter model.CocktailFilter) ([]model.Cocktail, error) {
+	log.Printf("get cocktails with limit...")
+
+	var conditions []string
+	var args []interface{}
+
+	// a material is named by its name or one of its aliases, once normalized
+	materialExistsQuery := `EXISTS (
+		SELECT * FROM cocktail_materials
+		INNER JOIN materials
+			ON cocktail_materials.material_id = materials.id
+		WHERE cocktail_materials.cocktail_id = cocktails.id
+			AND (materials.name_key = ?
+				OR materials.id IN (SELECT material_id FROM material_aliases WHERE name_key = ?)))`
+	for _, m := range filter.Materials {
+		conditions = append(conditions, materialExistsQuery)
+		args = append(args, normalize.Name(m), normalize.Name(m))
+	}
+	for _, m := range filter.ExcludeMaterials {
+		conditions = append(conditions, `NOT `+materialExistsQuery)
+		args = append(args, normalize.Name(m), normalize.Name(m))
+	}
+
+	// the keyword is matched against the normalized name and reading, putting the cocktails starting with it first
+	order := `id`
+	var orderArgs []interface{}
+	if filter.Keyword != "" {
+		key := normalize.Name(filter.Keyword)
+		conditions = append(conditions, `(INSTR(name_key, ?) > 0 OR INSTR(reading_key, ?) > 0)`)
+		args = append(args, key, key)
+		order = `CASE WHEN INSTR(name_key, ?) = 1 OR INSTR(reading_key, ?) = 1 THEN 0 ELSE 1 END, id`
+		orderArgs = append(orderArgs, key, key)
+	}
+
+	// cocktails whose strength cannot be estimated are left out, since they may be stronger
+	if filter.MaxABV != nil {
+		conditions = append(conditions, `abv <= ?`)
+		args = append(args, *filter.MaxABV)
+	}
+
+	conditions = append(conditions, `deleted_at IS NULL`)
+	query := `SELECT id, name, reading, image_url, created_at, updated_at FROM cocktails WHERE ` + strings.Join(conditions, ` AND `)
+	query += ` ORDER BY ` + order + ` LIMIT ? OFFSET ?`
+	args = append(args, orderArgs...)
+	args = append(args, limit, offset)
+	return r.queryCocktails(ctx, query, args...)
+}
+
+func (r CocktailRepository) queryCocktails(ctx context.Context, query string, args ...interface{}) ([]model.Cocktail, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cocktails := []model.Cocktail{}
+	for rows.Next() {
+		nc := model.NullableCocktail{}
+		if err := rows.Scan(&nc.ID, &nc.Name, &nc.Reading, &nc.ImageURL, &nc.CreatedAt, &nc.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		cocktails = append(cocktails, model.Cocktail{
+			ID:        nc.ID,
+			Name:      nc.Name,
+			Reading:   nc.Reading,
+			ImageURL:  nc.ImageURL.String,
+			CreatedAt: nc.CreatedAt,
+			UpdatedAt: nc.UpdatedAt,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return cocktails, nil
+}
+
+func (r CocktailRepository) GetByID(ctx context.Context, id int64) (model.CocktailDetail, error) {
+	log.Printf("get cocktails with cocktail id...")
+
+	nc := model.NullableCocktail{}
+	err := r.db.QueryRowContext(ctx, `SELECT id, name, reading, image_url, created_at, updated_at FROM cocktails WHERE id = ? AND deleted_at IS NULL`, id).
+		Scan(&nc.ID, &nc.Name, &nc.Reading, &nc.ImageURL, &nc.CreatedAt, &nc.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.CocktailDetail{}, fmt.Errorf("%w. cocktail_id: %d", repository.ErrCocktailNotFound, id)
+	}
+	if err != nil {
+		return model.CocktailDetail{}, err
+	}
+
+	query := `
+		SELECT
+			materials.id,
+			materials.name,
+			materials.abv,
+			cocktail_materials.quantity,
+			cocktail_materials.unit
+		FROM cocktail_materials
+		INNER JOIN materials
+			ON cocktail_materials.material_id = materials.id
+		WHERE cocktail_materials.cocktail_id = ?
+		ORDER BY cocktail_materials.rowid
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return model.CocktailDetail{}, err
+	}
+	defer rows.Close()
+
+	materials := []model.Material{}
+	for rows.Next() {
+		var m model.Material
+		var quantity sql.NullFloat64
+		var unit sql.NullString
+		if err := rows.Scan(&m.ID, &m.Name, &m.ABV, &quantity, &unit); err != nil {
+			return model.CocktailDetail{}, err
+		}
+
+		m.Quantity = model.MaterialQuantity{Quantity: quantity.Float64, Unit: unit.String}
+		materials = append(materials, m)
+	}
+	if err := rows.Err(); err != nil {
+		return model.CocktailDetail{}, err
+	}
+
+	d := model.CocktailDetail{
+		ID:        nc.ID,
+		Name:      nc.Name,
+		Reading:   nc.Reading,
+		ImageURL:  nc.ImageURL.String,
+		Materials: materials,
+		CreatedAt: nc.CreatedAt,
+		UpdatedAt: nc.UpdatedAt,
+	}
+	if err := loadPreparation(ctx, r.db, &d); err != nil {
+		return model.CocktailDetail{}, err
+	}
+
+	return d, nil
+}
+
+func (r CocktailRepository) Create(ctx context.Context, params model.CocktailParams) (*model.CocktailDetail, error) {
+	log.Printf("create cocktails...")
+
+	now := time.Now().Unix()
+	var cocktailID int64
+	var materials []model.Material
+
+	err := db.InTx(ctx, r.db, func(tx db.Executor) error {
+		res, err := tx.ExecContext(ctx, `INSERT INTO cocktails (name, reading, name_key, reading_key, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`, params.Name, params.Reading, normalize.Name(params.Name), normalize.Name(params.Reading), now, now)
+		if err != nil {
+			log.Printf("failed to create cocktail. err: %v", err)
+			return err
+		}
+		cocktailID, err = res.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		materials, err = insertCocktailMaterials(ctx, tx, cocktailID, params.Materials, now)
+		if err != nil {
+			return err
+		}
+
+		if err := savePreparation(ctx, tx, cocktailID, params); err != nil {
+			return err
+		}
+		return storeStrength(ctx, tx, cocktailID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CocktailDetail{
+		ID:        cocktailID,
+		Name:      params.Name,
+		Reading:   params.Reading,
+		Materials: materials,
+		Method:    params.Method,
+		Glass:     params.Glass,
+		Garnish:   params.Garnish,
+		Notes:     params.Notes,
+		Steps:     append([]string{}, params.Steps...),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func (r CocktailRepository) GetListByIDs(ctx context.Context, ids []int64) ([]model.Cocktail, error) {
+	log.Println("get cocktails with id list ...")
+
+	var args []interface{}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	query := `SELECT id, name, reading, image_url, created_at, updated_at FROM cocktails WHERE id IN (` + placeholders(len(ids)) + `) AND deleted_at IS NULL ORDER BY id`
+	return r.queryCocktails(ctx, query, args...)
+}
+
+func (r CocktailRepository) Update(ctx context.Context, id int64, params model.CocktailParams) (*model.CocktailDetail, error) {
+	log.Printf("update cocktail ... id: %d\n", id)
+
+	var imageURL sql.NullString
+	var createdAt int64
+	var materials []model.Material
+	now := time.Now().Unix()
+
+	err := db.InTx(ctx, r.db, func(tx db.Executor) error {
+		err := tx.QueryRowContext(ctx, `SELECT image_url, created_at FROM cocktails WHERE id = ? AND deleted_at IS NULL`, id).Scan(&imageURL, &createdAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrCocktailNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE cocktails SET name = ?, reading = ?, name_key = ?, reading_key = ?, updated_at = ? WHERE id = ?`, params.Name, params.Reading, normalize.Name(params.Name), normalize.Name(params.Reading), now, id)
+		if err != nil {
+			log.Printf("failed to update cocktail. err: %v", err)
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM cocktail_materials WHERE cocktail_id = ?`, id)
+		if err != nil {
+			log.Printf("failed to delete cocktail_materials. cocktail_id: %d, err: %v", id, err)
+			return err
+		}
+
+		materials, err = insertCocktailMaterials(ctx, tx, id, params.Materials, now)
+		if err != nil {
+			return err
+		}
+
+		if err := savePreparation(ctx, tx, id, params); err != nil {
+			return err
+		}
+		return storeStrength(ctx, tx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CocktailDetail{
+		ID:        id,
+		Name:      params.Name,
+		Reading:   params.Reading,
+		ImageURL:  imageURL.String,
+		Materials: materials,
+		Method:    params.Method,
+		Glass:     params.Glass,
+		Garnish:   params.Garnish,
+		Notes:     params.Notes,
+		Steps:     append([]string{}, params.Steps...),
+		CreatedAt: createdAt,
+		UpdatedAt: now,
+	}, nil
+}
+
+// insertCocktailMaterials stores the recipe of the cocktail, creating the materials which are missing.
+func insertCocktailMaterials(ctx context.Context, tx db.Executor, cocktailID int64, params []model.MaterialParams, now int64) ([]model.Material, error) {
+	materials := []model.Material{}
+
+	cocktailMaterialQuery := `INSERT INTO cocktail_materials (cocktail_id, material_id, quantity, unit) VALUES (?, ?, ?, ?)`
+	for _, m := range params {
+		materialID, err := findOrCreateMaterial(ctx, tx, m.Name, now)
+		if err != nil {
+			log.Printf("failed to find or create material. name: %s, err: %v", m.Name, err)
+			return nil, err
+		}
+
+		_, err = tx.ExecContext(ctx, cocktailMaterialQuery, cocktailID, materialID, m.Quantity.Quantity, m.Quantity.Unit)
+		if err != nil {
+			log.Printf("failed to create cocktail_material. err: %v", err)
+			return nil, err
+		}
+
+		materials = append(materials, model.Material{
+			ID:       materialID,
+			Name:     m.Name,
+			Quantity: m.Quantity,
+		})
+	}
+
+	return materials, nil
+}
+
+// savePreparation replaces how the cocktail is prepared: the method, glass, garnish, notes and ordered steps.
+func savePreparation(ctx context.Context, tx db.Executor, cocktailID int64, params model.CocktailParams) error {
+	for _, q := range []string{`DELETE FROM cocktail_steps WHERE cocktail_id = ?`, `DELETE FROM cocktail_recipes WHERE cocktail_id = ?`} {
+		if _, err := tx.ExecContext(ctx, q, cocktailID); err != nil {
+			return err
+		}
+	}
+
+	recipeQuery := `INSERT INTO cocktail_recipes (cocktail_id, method, glass, garnish, notes) VALUES (?, ?, ?, ?, ?)`
+	_, err := tx.ExecContext(ctx, recipeQuery, cocktailID, params.Method, params.Glass, params.Garnish, params.Notes)
+	if err != nil {
+		log.Printf("failed to create cocktail_recipe. err: %v", err)
+		return err
+	}
+
+	stepQuery := `INSERT INTO cocktail_steps (cocktail_id, seq, description) VALUES (?, ?, ?)`
+	for i, step := range params.Steps {
+		if _, err := tx.ExecContext(ctx, stepQuery, cocktailID, i+1, step); err != nil {
+			log.Printf("failed to create cocktail_step. err: %v", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadPreparation fills in how the cocktail is prepared. Cocktails created before recipes were recorded have none.
+func loadPreparation(ctx context.Context, e db.Executor, d *model.CocktailDetail) error {
+	err := e.QueryRowContext(ctx, `SELECT method, glass, garnish, notes FROM cocktail_recipes WHERE cocktail_id = ?`, d.ID).
+		Scan(&d.Method, &d.Glass, &d.Garnish, &d.Notes)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	rows, err := e.QueryContext(ctx, `SELECT description FROM cocktail_steps WHERE cocktail_id = ? ORDER BY seq`, d.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	d.Steps = []string{}
+	for rows.Next() {
+		var step string
+		if err := rows.Scan(&step); err != nil {
+			return err
+		}
+		d.Steps = append(d.Steps, step)
+	}
+
+	return rows.Err()
+}
+
+// storeStrength stores the estimated ABV of the cocktail, which max_abv filters on, or NULL when it cannot be estimated.
+// It runs whenever the recipe, the method or the ABV of one of the materials changes.
+func storeStrength(ctx context.Context, tx db.Executor, cocktailID int64) error {
+	q := `
+		SELECT
+			cocktail_recipes.method,
+			materials.abv,
+			cocktail_materials.quantity,
+			cocktail_materials.unit
+		FROM cocktail_materials
+		INNER JOIN materials
+			ON cocktail_materials.material_id = materials.id
+		LEFT JOIN cocktail_recipes
+			ON cocktail_materials.cocktail_id = cocktail_recipes.cocktail_id
+		WHERE cocktail_materials.cocktail_id = ?
+	`
+
+	rows, err := tx.QueryContext(ctx, q, cocktailID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var method sql.NullString
+	var materials []model.Material
+	for rows.Next() {
+		var quantity sql.NullFloat64
+		var unit sql.NullString
+		m := model.Material{}
+		if err := rows.Scan(&method, &m.ABV, &quantity, &unit); err != nil {
+			return err
+		}
+		m.Quantity = model.MaterialQuantity{Quantity: quantity.Float64, Unit: unit.String}
+		materials = append(materials, m)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	var abv *float64
+	if s, ok := strength.Of(model.Method(method.String), materials); ok {
+		abv = &s.ABV
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE cocktails SET abv = ? WHERE id = ?`, abv, cocktailID)
+	return err
+}
+
+// storeStrengthsUsing stores the estimated ABV of every cocktail using the material.
+func storeStrengthsUsing(ctx context.Context, tx db.Executor, materialID int64) error {
+	rows, err := tx.QueryContext(ctx, `SELECT DISTINCT cocktail_id FROM cocktail_materials WHERE material_id = ?`, materialID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		if err := storeStrength(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findOrCreateMaterial returns the id of the material matching the name or one of its aliases, creating it when missing.
+func findOrCreateMaterial(ctx context.Context, tx db.Executor, name string, now int64) (int64, error) {
+	materialID, err := findMaterialByName(ctx, tx, name, 0)
+	if err != nil || materialID != 0 {
+		return materialID, err
+	}
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO materials (name, name_key, created_at, updated_at) VALUES (?, ?, ?, ?)`, name, normalize.Name(name), now, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (r CocktailRepository) UpdateImageURL(ctx context.Context, id int64, url string) error {
+	log.Printf("update cocktail image ... id: %d\n", id)
+
+	res, err := r.db.ExecContext(ctx, `UPDATE cocktails SET image_url = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL`, url, time.Now().Unix(), id)
+	if err != nil {
+		log.Printf("failed to update cocktail image. err: %v", err)
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return repository.ErrCocktailNotFound
+	}
+
+	return nil
+}
+
+func (r CocktailRepository) Delete(ctx context.Context, id int64) error {
+	log.Printf("delete cocktail ... id: %d\n", id)
+
+	return db.InTx(ctx, r.db, func(tx db.Executor) error {
+		var exists bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT * FROM cocktails WHERE id = ? AND deleted_at IS NULL)`, id).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return repository.ErrCocktailNotFound
+		}
+
+		// orders which are not provided yet must be served or removed before the cocktail disappears
+		statusCondition, statusArgs := orderStatusCondition(model.UnprovidedOrderStatuses)
+		var unprovided bool
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT * FROM shop_orders WHERE shop_cocktail_id = ? AND `+statusCondition+`)`, append([]interface{}{id}, statusArgs...)...).Scan(&unprovided)
+		if err != nil {
+			return err
+		}
+		if unprovided {
+			return repository.ErrCocktailInUse
+		}
+
+		// the cocktails row is kept so that the orders and bills of the past still show what was served
+		queries := []string{
+			`DELETE FROM shop_cocktails WHERE cocktail_id = ?`,
+			`DELETE FROM cocktail_materials WHERE cocktail_id = ?`,
+			`DELETE FROM cocktail_steps WHERE cocktail_id = ?`,
+			`DELETE FROM cocktail_recipes WHERE cocktail_id = ?`,
+		}
+		for _, q := range queries {
+			if _, err := tx.ExecContext(ctx, q, id); err != nil {
+				log.Printf("failed to delete cocktail. id: %d, err: %v", id, err)
+				return err
+			}
+		}
+
+		now := time.Now().Unix()
+		if _, err := tx.ExecContext(ctx, `UPDATE cocktails SET deleted_at = ?, updated_at = ? WHERE id = ?`, now, now, id); err != nil {
+			log.Printf("failed to delete cocktail. id: %d, err: %v", id, err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (r CocktailRepository) GetMakeable(ctx context.Context, materialIDs []int64, materialNames []string, maxMissing int64) ([]model.MakeableCocktail, error) {
+	log.Println("get makeable cocktails ...")
+
+	ownedCondition, ownedArgs := ownedMaterialCondition(materialIDs, materialNames)
+
+	query := `
+		SELECT
+			cocktails.id,
+			cocktails.name,
+			cocktails.reading,
+			cocktails.image_url,
+			cocktails.created_at,
+			cocktails.updated_at,
+			materials.id,
+			materials.name,
+			cocktail_materials.quantity,
+			cocktail_materials.unit,
+			` + ownedCondition + `
+		FROM cocktails
+		INNER JOIN cocktail_materials
+			ON cocktails.id = cocktail_materials.cocktail_id
+			INNER JOIN materials
+				ON cocktail_materials.material_id = materials.id
+		WHERE cocktails.id IN (
+			SELECT cocktail_materials.cocktail_id
+			FROM cocktail_materials
+			INNER JOIN materials
+				ON cocktail_materials.material_id = materials.id
+			GROUP BY cocktail_materials.cocktail_id
+			HAVING SUM(CASE WHEN ` + ownedCondition + ` THEN 0 ELSE 1 END) <= ?
+		)
+		ORDER BY cocktails.id, cocktail_materials.rowid
+	`
+
+	var args []interface{}
+	args = append(args, ownedArgs...)
+	args = append(args, ownedArgs...)
+	args = append(args, maxMissing)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cocktails := []model.MakeableCocktail{}
+	for rows.Next() {
+		nc := model.NullableCocktail{}
+		var m model.Material
+		var quantity sql.NullFloat64
+		var unit sql.NullString
+		var owned bool
+		if err := rows.Scan(&nc.ID, &nc.Name, &nc.Reading, &nc.ImageURL, &nc.CreatedAt, &nc.UpdatedAt, &m.ID, &m.Name, &quantity, &unit, &owned); err != nil {
+			return nil, err
+		}
+
+		if len(cocktails) == 0 || cocktails[len(cocktails)-1].Cocktail.ID != nc.ID {
+			cocktails = append(cocktails, model.MakeableCocktail{
+				Cocktail: model.Cocktail{
+					ID:        nc.ID,
+					Name:      nc.Name,
+					Reading:   nc.Reading,
+					ImageURL:  nc.ImageURL.String,
+					CreatedAt: nc.CreatedAt,
+					UpdatedAt: nc.UpdatedAt,
+				},
+				MissingMaterials: []model.Material{},
+			})
+		}
+
+		if owned {
+			continue
+		}
+
+		m.Quantity = model.MaterialQuantity{Quantity: quantity.Float64, Unit: unit.String}
+		last := &cocktails[len(cocktails)-1]
+		last.MissingMaterials = append(last.MissingMaterials, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return cocktails, nil
+}
+
+// GetAllDetails returns every cocktail with its recipe, for building indexes over the whole catalog.
+func (r CocktailRepository) GetAllDetails(ctx context.Context) ([]model.CocktailDetail, error) {
+	log.Println("get all cocktail details ...")
+
+	query := `
+		SELECT
+			cocktails.id,
+			cocktails.name,
+			cocktails.reading,
+			cocktails.image_url,
+			cocktails.created_at,
+			cocktails.updated_at,
+			COALESCE(cocktail_recipes.method, ''),
+			materials.id,
+			materials.name,
+			materials.abv,
+			cocktail_materials.quantity,
+			cocktail_materials.unit
+		FROM cocktails
+		LEFT JOIN cocktail_recipes
+			ON cocktails.id = cocktail_recipes.cocktail_id
+		LEFT JOIN cocktail_materials
+			ON cocktails.id = cocktail_materials.cocktail_id
+		LEFT JOIN materials
+			ON cocktail_materials.material_id = materials.id
+		WHERE cocktails.deleted_at IS NULL
+		ORDER BY cocktails.id, cocktail_materials.rowid
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cocktails := []model.CocktailDetail{}
+	for rows.Next() {
+		nc := model.NullableCocktail{}
+		var method model.Method
+		var materialABV *float64
+		var materialID sql.NullInt64
+		var quantity sql.NullFloat64
+		var materialName, unit sql.NullString
+		if err := rows.Scan(&nc.ID, &nc.Name, &nc.Reading, &nc.ImageURL, &nc.CreatedAt, &nc.UpdatedAt, &method, &materialID, &materialName, &materialABV, &quantity, &unit); err != nil {
+			return nil, err
+		}
+
+		if len(cocktails) == 0 || cocktails[len(cocktails)-1].ID != nc.ID {
+			cocktails = append(cocktails, model.CocktailDetail{
+				ID:        nc.ID,
+				Name:      nc.Name,
+				Reading:   nc.Reading,
+				ImageURL:  nc.ImageURL.String,
+				Materials: []model.Material{},
+				Method:    method,
+				CreatedAt: nc.CreatedAt,
+				UpdatedAt: nc.UpdatedAt,
+			})
+		}
+
+		if !materialID.Valid {
+			continue
+		}
+
+		last := &cocktails[len(cocktails)-1]
+		last.Materials = append(last.Materials, model.Material{
+			ID:       materialID.Int64,
+			Name:     materialName.String,
+			ABV:      materialABV,
+			Quantity: model.MaterialQuantity{Quantity: quantity.Float64, Unit: unit.String},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return cocktails, nil
+}
+
+// ownedMaterialCondition builds a SQL condition which is true when the joined materials row is one of the given materials.
+// Materials given by name match their name or one of their aliases once normalized.
+func ownedMaterialCondition(materialIDs []int64, materialNames []string) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if len(materialIDs) > 0 {
+		conditions = append(conditions, `materials.id IN (`+placeholders(len(materialIDs))+`)`)
+		for _, id := range materialIDs {
+			args = append(args, id)
+		}
+	}
+
+	if len(materialNames) > 0 {
+		keys := `(` + placeholders(len(materialNames)) + `)`
+		conditions = append(conditions, `materials.name_key IN `+keys, `materials.id IN (SELECT material_id FROM material_aliases WHERE name_key IN `+keys+`)`)
+		for i := 0; i < 2; i++ {
+			for _, name := range materialNames {
+				args = append(args, normalize.Name(name))
+			}
+		}
+	}
+
+	if len(conditions) == 0 {
+		return `FALSE`, nil
+	}
+
+	return `(` + strings.Join(conditions, ` OR `) + `)`, args
+}
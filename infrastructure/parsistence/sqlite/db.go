@@ -0,0 +1,32 @@
+package sqlite
+
+import (
+	"database/sql"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// Open opens the SQLite database file at path. The schema is managed by db/migrate with the migrations package.
+// ":memory:" opens a database which lives as long as the returned handle.
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows one writer at a time, and every connection to ":memory:" is a database of its own
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// placeholders returns "?,?,?" for n arguments.
+func placeholders(n int) string {
+	return strings.Repeat("?,", n-1) + "?"
+}
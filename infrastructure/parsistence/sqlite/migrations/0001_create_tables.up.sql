@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS cocktails (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(128) NOT NULL,
+    image_url TEXT,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS materials (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(128) NOT NULL,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS cocktail_materials (
+    cocktail_id INTEGER NOT NULL,
+    material_id INTEGER NOT NULL,
+    quantity INTEGER,
+    unit VARCHAR(128)
+);
+
+CREATE TABLE IF NOT EXISTS cocktail_material_images (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS shops (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS shop_cocktails (
+    shop_id INTEGER NOT NULL,
+    cocktail_id INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS shop_tables (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    shop_id INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS shop_orders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    table_id INTEGER NOT NULL,
+    shop_cocktail_id INTEGER NOT NULL,
+    is_provided BOOLEAN DEFAULT 0,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
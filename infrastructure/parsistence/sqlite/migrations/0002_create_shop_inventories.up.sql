@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS shop_inventories (
+    shop_id INTEGER NOT NULL,
+    material_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    unit VARCHAR(128) NOT NULL,
+    updated_at INTEGER NOT NULL,
+    PRIMARY KEY (shop_id, material_id)
+);
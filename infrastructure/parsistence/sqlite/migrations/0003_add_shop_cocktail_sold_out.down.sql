@@ -0,0 +1,2 @@
+ALTER TABLE shop_cocktails DROP COLUMN back_at;
+ALTER TABLE shop_cocktails DROP COLUMN sold_out;
@@ -0,0 +1,2 @@
+ALTER TABLE shop_cocktails ADD COLUMN sold_out BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE shop_cocktails ADD COLUMN back_at INTEGER;
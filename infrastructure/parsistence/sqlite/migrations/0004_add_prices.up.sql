@@ -0,0 +1,3 @@
+ALTER TABLE shops ADD COLUMN tax_rate INTEGER NOT NULL DEFAULT 10;
+ALTER TABLE shop_cocktails ADD COLUMN price INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE shop_orders ADD COLUMN price INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,9 @@
+ALTER TABLE shop_orders ADD COLUMN is_provided BOOLEAN DEFAULT 0;
+-- only served orders count as provided; the other states have no equivalent
+UPDATE shop_orders SET is_provided = 1 WHERE status = 'served';
+ALTER TABLE shop_orders DROP COLUMN cancelled_at;
+ALTER TABLE shop_orders DROP COLUMN served_at;
+ALTER TABLE shop_orders DROP COLUMN ready_at;
+ALTER TABLE shop_orders DROP COLUMN preparing_at;
+ALTER TABLE shop_orders DROP COLUMN accepted_at;
+ALTER TABLE shop_orders DROP COLUMN status;
@@ -0,0 +1,10 @@
+ALTER TABLE shop_orders ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'accepted';
+ALTER TABLE shop_orders ADD COLUMN accepted_at INTEGER;
+ALTER TABLE shop_orders ADD COLUMN preparing_at INTEGER;
+ALTER TABLE shop_orders ADD COLUMN ready_at INTEGER;
+ALTER TABLE shop_orders ADD COLUMN served_at INTEGER;
+ALTER TABLE shop_orders ADD COLUMN cancelled_at INTEGER;
+-- every existing order was accepted when placed, and a provided one was served when it was last updated
+UPDATE shop_orders SET accepted_at = created_at;
+UPDATE shop_orders SET status = 'served', served_at = updated_at WHERE is_provided;
+ALTER TABLE shop_orders DROP COLUMN is_provided;
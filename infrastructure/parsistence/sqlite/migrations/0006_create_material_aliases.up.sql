@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS material_aliases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    material_id INTEGER NOT NULL,
+    name VARCHAR(128) NOT NULL,
+    created_at INTEGER NOT NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE cocktails DROP COLUMN reading;
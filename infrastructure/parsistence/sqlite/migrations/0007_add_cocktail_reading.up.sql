@@ -0,0 +1 @@
+ALTER TABLE cocktails ADD COLUMN reading VARCHAR(128) NOT NULL DEFAULT '';
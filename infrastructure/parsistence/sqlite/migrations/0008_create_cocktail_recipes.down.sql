@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS cocktail_steps;
+DROP TABLE IF EXISTS cocktail_recipes;
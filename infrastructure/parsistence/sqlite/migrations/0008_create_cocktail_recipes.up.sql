@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS cocktail_recipes (
+    cocktail_id INTEGER NOT NULL PRIMARY KEY,
+    method VARCHAR(16) NOT NULL DEFAULT '',
+    glass VARCHAR(64) NOT NULL DEFAULT '',
+    garnish VARCHAR(128) NOT NULL DEFAULT '',
+    notes TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS cocktail_steps (
+    cocktail_id INTEGER NOT NULL,
+    seq INTEGER NOT NULL,
+    description TEXT NOT NULL,
+    PRIMARY KEY (cocktail_id, seq)
+);
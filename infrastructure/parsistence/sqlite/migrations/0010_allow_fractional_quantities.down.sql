@@ -0,0 +1,23 @@
+CREATE TABLE cocktail_materials_new (
+    cocktail_id INTEGER NOT NULL,
+    material_id INTEGER NOT NULL,
+    quantity INTEGER,
+    unit VARCHAR(128)
+);
+INSERT INTO cocktail_materials_new (cocktail_id, material_id, quantity, unit)
+    SELECT cocktail_id, material_id, CAST(ROUND(quantity) AS INTEGER), unit FROM cocktail_materials ORDER BY rowid;
+DROP TABLE cocktail_materials;
+ALTER TABLE cocktail_materials_new RENAME TO cocktail_materials;
+
+CREATE TABLE shop_inventories_new (
+    shop_id INTEGER NOT NULL,
+    material_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    unit VARCHAR(128) NOT NULL,
+    updated_at INTEGER NOT NULL,
+    PRIMARY KEY (shop_id, material_id)
+);
+INSERT INTO shop_inventories_new (shop_id, material_id, quantity, unit, updated_at)
+    SELECT shop_id, material_id, CAST(ROUND(quantity) AS INTEGER), unit, updated_at FROM shop_inventories;
+DROP TABLE shop_inventories;
+ALTER TABLE shop_inventories_new RENAME TO shop_inventories;
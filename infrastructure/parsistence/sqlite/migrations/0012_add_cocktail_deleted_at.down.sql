@@ -0,0 +1 @@
+ALTER TABLE cocktails DROP COLUMN deleted_at;
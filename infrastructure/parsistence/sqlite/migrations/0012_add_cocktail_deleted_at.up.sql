@@ -0,0 +1 @@
+ALTER TABLE cocktails ADD COLUMN deleted_at INTEGER;
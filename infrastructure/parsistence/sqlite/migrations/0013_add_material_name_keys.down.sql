@@ -0,0 +1,4 @@
+DROP INDEX material_aliases_name_key;
+ALTER TABLE material_aliases DROP COLUMN name_key;
+DROP INDEX materials_name_key;
+ALTER TABLE materials DROP COLUMN name_key;
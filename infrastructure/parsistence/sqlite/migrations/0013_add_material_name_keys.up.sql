@@ -0,0 +1,4 @@
+ALTER TABLE materials ADD COLUMN name_key VARCHAR(255);
+CREATE UNIQUE INDEX materials_name_key ON materials (name_key);
+ALTER TABLE material_aliases ADD COLUMN name_key VARCHAR(255);
+CREATE UNIQUE INDEX material_aliases_name_key ON material_aliases (name_key);
@@ -0,0 +1,2 @@
+UPDATE material_aliases SET name_key = NULL;
+UPDATE materials SET name_key = NULL;
@@ -0,0 +1 @@
+-- the keys are normalized names, which SQL cannot compute, so the step of this migration fills them in
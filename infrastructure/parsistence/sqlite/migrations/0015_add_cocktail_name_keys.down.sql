@@ -0,0 +1,2 @@
+ALTER TABLE cocktails DROP COLUMN reading_key;
+ALTER TABLE cocktails DROP COLUMN name_key;
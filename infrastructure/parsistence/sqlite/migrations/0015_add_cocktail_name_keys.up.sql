@@ -0,0 +1,2 @@
+ALTER TABLE cocktails ADD COLUMN name_key VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE cocktails ADD COLUMN reading_key VARCHAR(255) NOT NULL DEFAULT '';
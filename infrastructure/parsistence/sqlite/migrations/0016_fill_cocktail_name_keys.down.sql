@@ -0,0 +1 @@
+UPDATE cocktails SET name_key = '', reading_key = '';
@@ -0,0 +1 @@
+ALTER TABLE cocktails ADD COLUMN abv DOUBLE;
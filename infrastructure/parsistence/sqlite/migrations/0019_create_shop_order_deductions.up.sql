@@ -0,0 +1,9 @@
+-- what each order took from the shop stock, in the unit the stock was kept in, so cancelling it gives back exactly that.
+-- orders placed before this have no rows and give nothing back.
+CREATE TABLE IF NOT EXISTS shop_order_deductions (
+    order_id INTEGER NOT NULL,
+    material_id INTEGER NOT NULL,
+    quantity REAL NOT NULL,
+    unit VARCHAR(128) NOT NULL
+);
+CREATE INDEX shop_order_deductions_order_id ON shop_order_deductions (order_id);
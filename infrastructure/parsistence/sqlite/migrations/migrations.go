@@ -0,0 +1,15 @@
+// Package migrations holds the SQLite schema migrations applied by db/migrate.
+// They mirror db/migrations in the SQLite dialect.
+package migrations
+
+import (
+	"embed"
+
+	"github.com/shake551/cocktails-api/db/migrations"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// Steps are the steps of db/migrations, whose statements run on SQLite as well.
+var Steps = migrations.Steps
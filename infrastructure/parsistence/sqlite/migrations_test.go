@@ -0,0 +1,239 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/shake551/cocktails-api/db"
+	"github.com/shake551/cocktails-api/db/migrate"
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/infrastructure/parsistence/sqlite/migrations"
+	"github.com/stretchr/testify/assert"
+)
+
+// until returns the migrations up to the version, so data can be written in the schema of an older release.
+// The first migration alone is the schema before any of the later changes.
+func until(t *testing.T, version int64) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	loaded, err := migrate.Load(migrations.FS)
+	assert.Nil(t, err)
+	for _, m := range loaded {
+		if m.Version > version {
+			break
+		}
+		prefix := fmt.Sprintf("%04d_%s", m.Version, m.Name)
+		fsys[prefix+".up.sql"] = &fstest.MapFile{Data: []byte(m.Up)}
+		fsys[prefix+".down.sql"] = &fstest.MapFile{Data: []byte(m.Down)}
+	}
+	return fsys
+}
+
+func TestMigrateProvidedOrders(t *testing.T) {
+	ctx := context.Background()
+	d, err := Open(":memory:")
+	assert.Nil(t, err)
+	defer d.Close()
+
+	m, err := migrate.New(d, until(t, 1))
+	assert.Nil(t, err)
+	_, err = m.Up(ctx)
+	assert.Nil(t, err)
+	_, err = d.ExecContext(ctx, `INSERT INTO shop_orders (table_id, shop_cocktail_id, is_provided, created_at, updated_at) VALUES (1, 1, 1, 100, 200), (1, 1, 0, 300, 300)`)
+	assert.Nil(t, err)
+
+	m, err = migrate.New(d, migrations.FS, migrations.Steps...)
+	assert.Nil(t, err)
+	_, err = m.Up(ctx)
+	assert.Nil(t, err)
+
+	var orders []model.Order
+	rows, err := d.QueryContext(ctx, `SELECT status, accepted_at, COALESCE(served_at, 0) FROM shop_orders ORDER BY id`)
+	assert.Nil(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var o model.Order
+		assert.Nil(t, rows.Scan(&o.Status, &o.AcceptedAt, &o.ServedAt))
+		orders = append(orders, o)
+	}
+
+	assert.Equal(t, []model.Order{
+		{Status: model.OrderStatusServed, AcceptedAt: 100, ServedAt: 200},
+		{Status: model.OrderStatusAccepted, AcceptedAt: 300},
+	}, orders)
+}
+
+func TestMigrateDownToBaseline(t *testing.T) {
+	ctx := context.Background()
+	d := openDB(t)
+	m, err := migrate.New(d, migrations.FS, migrations.Steps...)
+	assert.Nil(t, err)
+
+	for {
+		reverted, err := m.Down(ctx)
+		assert.Nil(t, err)
+		if reverted == nil || reverted.Version == 2 {
+			break
+		}
+	}
+
+	var schema []string
+	rows, err := d.QueryContext(ctx, `SELECT sql FROM sqlite_master WHERE name = 'shop_orders'`)
+	assert.Nil(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var s string
+		assert.Nil(t, rows.Scan(&s))
+		schema = append(schema, s)
+	}
+	assert.Len(t, schema, 1)
+	assert.True(t, strings.Contains(schema[0], "is_provided"))
+	assert.False(t, strings.Contains(schema[0], "status"))
+}
+
+func TestMigrateMaterialNameKeys(t *testing.T) {
+	ctx := context.Background()
+	d, err := Open(":memory:")
+	assert.Nil(t, err)
+	defer d.Close()
+
+	m, err := migrate.New(d, until(t, 12))
+	assert.Nil(t, err)
+	_, err = m.Up(ctx)
+	assert.Nil(t, err)
+	_, err = d.ExecContext(ctx, `INSERT INTO materials (id, name, created_at, updated_at) VALUES (1, 'ライム', 0, 0), (2, 'らいむ', 0, 0), (3, 'Gin', 0, 0)`)
+	assert.Nil(t, err)
+	_, err = d.ExecContext(ctx, `INSERT INTO material_aliases (id, material_id, name, created_at) VALUES (1, 3, 'ジン', 0), (2, 1, 'ﾗｲﾑ', 0), (3, 2, 'じん', 0)`)
+	assert.Nil(t, err)
+
+	m, err = migrate.New(d, migrations.FS, migrations.Steps...)
+	assert.Nil(t, err)
+	_, err = m.Up(ctx)
+	assert.Nil(t, err)
+
+	keys := func(query string) []string {
+		var keys []string
+		rows, err := d.QueryContext(ctx, query)
+		assert.Nil(t, err)
+		defer rows.Close()
+		for rows.Next() {
+			var key sql.NullString
+			assert.Nil(t, rows.Scan(&key))
+			keys = append(keys, key.String)
+		}
+		return keys
+	}
+
+	// the second ライム and the second ジン keep no key until they are merged
+	assert.Equal(t, []string{"ライム", "", "gin"}, keys(`SELECT name_key FROM materials ORDER BY id`))
+	assert.Equal(t, []string{"ジン", "ライム", ""}, keys(`SELECT name_key FROM material_aliases ORDER BY id`))
+}
+
+func TestMigrateCocktailNameKeys(t *testing.T) {
+	ctx := context.Background()
+	d, err := Open(":memory:")
+	assert.Nil(t, err)
+	defer d.Close()
+
+	m, err := migrate.New(d, until(t, 14))
+	assert.Nil(t, err)
+	_, err = m.Up(ctx)
+	assert.Nil(t, err)
+	_, err = d.ExecContext(ctx, `INSERT INTO cocktails (name, reading, created_at, updated_at) VALUES ('雪国', 'ゆきぐに', 0, 0)`)
+	assert.Nil(t, err)
+
+	m, err = migrate.New(d, migrations.FS, migrations.Steps...)
+	assert.Nil(t, err)
+	_, err = m.Up(ctx)
+	assert.Nil(t, err)
+
+	var nameKey, readingKey string
+	assert.Nil(t, d.QueryRowContext(ctx, `SELECT name_key, reading_key FROM cocktails`).Scan(&nameKey, &readingKey))
+	assert.Equal(t, "雪国", nameKey)
+	assert.Equal(t, "ユキグニ", readingKey)
+}
+
+func TestMigrateCocktailABV(t *testing.T) {
+	ctx := context.Background()
+	d, err := Open(":memory:")
+	assert.Nil(t, err)
+	defer d.Close()
+
+	m, err := migrate.New(d, until(t, 16))
+	assert.Nil(t, err)
+	_, err = m.Up(ctx)
+	assert.Nil(t, err)
+	for _, q := range []string{
+		`INSERT INTO cocktails (id, name, created_at, updated_at) VALUES (1, 'カルーアミルク', 0, 0), (2, 'シャーリー・テンプル', 0, 0)`,
+		`INSERT INTO materials (id, name, abv, created_at, updated_at) VALUES (1, 'カルーア', 20, 0, 0), (2, '牛乳', NULL, 0, 0), (3, 'ジンジャーエール', NULL, 0, 0)`,
+		`INSERT INTO cocktail_materials (cocktail_id, material_id, quantity, unit) VALUES (1, 1, 45, 'ml'), (1, 2, 90, 'ml'), (2, 3, 120, 'ml')`,
+		`INSERT INTO cocktail_recipes (cocktail_id, method, notes) VALUES (1, 'build', '')`,
+	} {
+		_, err = d.ExecContext(ctx, q)
+		assert.Nil(t, err)
+	}
+
+	m, err = migrate.New(d, migrations.FS, migrations.Steps...)
+	assert.Nil(t, err)
+	_, err = m.Up(ctx)
+	assert.Nil(t, err)
+
+	// the cocktail without any known ABV keeps none
+	var abv []*float64
+	rows, err := d.QueryContext(ctx, `SELECT abv FROM cocktails ORDER BY id`)
+	assert.Nil(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var v *float64
+		assert.Nil(t, rows.Scan(&v))
+		abv = append(abv, v)
+	}
+	assert.Len(t, abv, 2)
+	assert.Equal(t, 6.1, *abv[0])
+	assert.Nil(t, abv[1])
+}
+
+func TestMigrateRetriesFailedStep(t *testing.T) {
+	ctx := context.Background()
+	d, err := Open(":memory:")
+	assert.Nil(t, err)
+	defer d.Close()
+
+	m, err := migrate.New(d, until(t, 12))
+	assert.Nil(t, err)
+	_, err = m.Up(ctx)
+	assert.Nil(t, err)
+	_, err = d.ExecContext(ctx, `INSERT INTO materials (id, name, created_at, updated_at) VALUES (1, 'ライム', 0, 0)`)
+	assert.Nil(t, err)
+
+	// the keys are filled in, then the step fails before its migration is recorded
+	fail := errors.New("step failed")
+	failing := migrate.Step{Version: 14, Up: func(ctx context.Context, tx db.Executor) error {
+		if _, err := tx.ExecContext(ctx, `UPDATE materials SET name_key = name`); err != nil {
+			return err
+		}
+		return fail
+	}}
+	m, err = migrate.New(d, migrations.FS, failing)
+	assert.Nil(t, err)
+	_, err = m.Up(ctx)
+	assert.ErrorIs(t, err, fail)
+
+	var key sql.NullString
+	assert.Nil(t, d.QueryRowContext(ctx, `SELECT name_key FROM materials`).Scan(&key))
+	assert.False(t, key.Valid)
+
+	// running up again picks up at the step rather than adding the columns twice
+	m, err = migrate.New(d, migrations.FS, migrations.Steps...)
+	assert.Nil(t, err)
+	applied, err := m.Up(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(14), applied[0].Version)
+	assert.Nil(t, m.Check(ctx))
+	assert.Nil(t, d.QueryRowContext(ctx, `SELECT name_key FROM materials`).Scan(&key))
+	assert.Equal(t, "ライム", key.String)
+}
@@ -0,0 +1,101 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/shake551/cocktails-api/db/migrate"
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/infrastructure/parsistence/repositorytest"
+	"github.com/shake551/cocktails-api/infrastructure/parsistence/sqlite/migrations"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ repository.CocktailRepository = (*CocktailRepository)(nil)
+var _ repository.MaterialRepository = (*MaterialRepository)(nil)
+var _ repository.ShopRepository = (*ShopRepository)(nil)
+var _ repository.ImageRepository = (*ImageRepository)(nil)
+var _ repository.UnitOfWork = (*UnitOfWork)(nil)
+
+// openDB opens a migrated database which lives until the test ends.
+func openDB(t *testing.T) *sql.DB {
+	d, err := Open(":memory:")
+	assert.Nil(t, err)
+	t.Cleanup(func() { d.Close() })
+
+	m, err := migrate.New(d, migrations.FS, migrations.Steps...)
+	assert.Nil(t, err)
+	_, err = m.Up(context.Background())
+	assert.Nil(t, err)
+
+	return d
+}
+
+func createCocktail(t *testing.T, r *CocktailRepository, name string, materials ...string) *model.CocktailDetail {
+	params := model.CocktailParams{Name: name}
+	for _, m := range materials {
+		params.Materials = append(params.Materials, model.MaterialParams{Name: m, Quantity: model.MaterialQuantity{Quantity: 30, Unit: "ml"}})
+	}
+
+	c, err := r.Create(context.Background(), params)
+	assert.Nil(t, err)
+	return c
+}
+
+func TestRepositories(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Backend {
+		d := openDB(t)
+		return repositorytest.Backend{
+			Cocktail:   NewCocktailRepository(d),
+			Material:   NewMaterialRepository(d),
+			Shop:       NewShopRepository(d),
+			Image:      NewImageRepository(d),
+			UnitOfWork: NewUnitOfWork(d),
+		}
+	})
+}
+
+func TestOpenKeepsData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cocktails.db")
+	d, err := Open(path)
+	assert.Nil(t, err)
+	m, err := migrate.New(d, migrations.FS, migrations.Steps...)
+	assert.Nil(t, err)
+	_, err = m.Up(context.Background())
+	assert.Nil(t, err)
+	createCocktail(t, NewCocktailRepository(d), "カルーアミルク", "カルーア", "牛乳")
+	assert.Nil(t, d.Close())
+
+	d, err = Open(path)
+	assert.Nil(t, err)
+	defer d.Close()
+	m, err = migrate.New(d, migrations.FS, migrations.Steps...)
+	assert.Nil(t, err)
+	assert.Nil(t, m.Check(context.Background()))
+
+	c, err := NewCocktailRepository(d).GetByID(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "カルーアミルク", c.Name)
+	assert.Len(t, c.Materials, 2)
+}
+
+func TestCallerOwnedTransaction(t *testing.T) {
+	d := openDB(t)
+	ctx := context.Background()
+
+	tx, err := d.BeginTx(ctx, nil)
+	assert.Nil(t, err)
+	createCocktail(t, NewCocktailRepository(tx), "カルーアミルク", "カルーア", "牛乳")
+	assert.Nil(t, tx.Rollback())
+
+	cocktails, err := NewCocktailRepository(d).GetLimit(ctx, 10, 0, model.CocktailFilter{})
+	assert.Nil(t, err)
+	assert.Empty(t, cocktails)
+
+	materials, err := NewMaterialRepository(d).GetLimit(ctx, 10, 0, "")
+	assert.Nil(t, err)
+	assert.Empty(t, materials)
+}
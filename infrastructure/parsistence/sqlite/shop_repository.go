@@ -0,0 +1,1014 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shake551/cocktails-api/db"
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/domain/units"
+)
+
+type ShopRepository struct {
+	db db.Executor
+}
+
+// NewShopRepository returns a repository running its statements on e.
+// Given a transaction, every call joins it instead of committing on its own.
+func NewShopRepository(e db.Executor) *ShopRepository {
+	return &ShopRepository{db: e}
+}
+
+func (r ShopRepository) GetLimit(ctx context.Context, limit int64, offset int64) ([]model.Shop, error) {
+	log.Println("get shops with limit ...")
+
+	query := `SELECT id, name, tax_rate FROM shops ORDER BY id LIMIT ? OFFSET ?`
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var shops []model.Shop
+	for rows.Next() {
+		s := model.Shop{}
+		if err := rows.Scan(&s.ID, &s.Name, &s.TaxRate); err != nil {
+			return nil, err
+		}
+
+		shops = append(shops, s)
+	}
+
+	if len(shops) == 0 {
+		return []model.Shop{}, nil
+	}
+
+	return shops, nil
+}
+
+func (r ShopRepository) Create(ctx context.Context, params model.ShopParams) (*model.Shop, error) {
+	log.Println("create shop...")
+
+	taxRate := model.DefaultTaxRate
+	if params.TaxRate != nil {
+		taxRate = *params.TaxRate
+	}
+
+	query := `INSERT INTO shops (name, tax_rate) VALUES (?, ?)`
+	res, err := r.db.ExecContext(ctx, query, params.Name, taxRate)
+	if err != nil {
+		return nil, err
+	}
+
+	shopID, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Shop{ID: shopID, Name: params.Name, TaxRate: taxRate}, nil
+}
+
+func (r ShopRepository) GetByID(ctx context.Context, id int64) (model.Shop, error) {
+	log.Println("find shop with shop id ...")
+
+	query := `SELECT id, name, tax_rate FROM shops WHERE id = ?`
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return model.Shop{}, err
+	}
+
+	defer rows.Close()
+
+	s := model.Shop{}
+	for rows.Next() {
+		if err := rows.Scan(&s.ID, &s.Name, &s.TaxRate); err != nil {
+			return model.Shop{}, err
+		}
+	}
+	if s.ID == 0 {
+		return model.Shop{}, fmt.Errorf("%w. shop_id: %d", repository.ErrShopNotFound, id)
+	}
+
+	return s, nil
+}
+
+func (r ShopRepository) Update(ctx context.Context, id int64, params model.ShopParams) (*model.Shop, error) {
+	log.Printf("update shop ... id: %d\n", id)
+
+	query := `UPDATE shops SET name = ?, tax_rate = COALESCE(?, tax_rate) WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, params.Name, params.TaxRate, id); err != nil {
+		return nil, err
+	}
+
+	s := model.Shop{}
+	err := r.db.QueryRowContext(ctx, `SELECT id, name, tax_rate FROM shops WHERE id = ?`, id).Scan(&s.ID, &s.Name, &s.TaxRate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrShopNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (r ShopRepository) GetShopCocktailList(ctx context.Context, shopID int64, limit int64, offset int64) ([]model.ShopMenuCocktail, error) {
+	log.Printf("get shop cocktail list ... %d \n", shopID)
+
+	q := `SELECT
+			cocktails.id,
+			cocktails.name,
+			cocktails.reading,
+			cocktails.image_url,
+			cocktails.created_at,
+			cocktails.updated_at,
+			shop_cocktails.price,
+			shop_cocktails.sold_out,
+			shop_cocktails.back_at
+		FROM cocktails
+		INNER JOIN shop_cocktails
+			ON shop_cocktails.cocktail_id = cocktails.id
+		WHERE shop_cocktails.shop_id = ?
+		ORDER BY shop_cocktails.rowid
+		LIMIT ? OFFSET ?`
+
+	rows, err := r.db.QueryContext(ctx, q, shopID, limit, offset)
+	if err != nil {
+		log.Println(err)
+		return []model.ShopMenuCocktail{}, err
+	}
+
+	defer rows.Close()
+	var cocktails []model.ShopMenuCocktail
+	for rows.Next() {
+		nc := model.NullableCocktail{}
+		var price int64
+		var soldOut bool
+		var backAt sql.NullInt64
+		if err := rows.Scan(&nc.ID, &nc.Name, &nc.Reading, &nc.ImageURL, &nc.CreatedAt, &nc.UpdatedAt, &price, &soldOut, &backAt); err != nil {
+			log.Println(err)
+			return []model.ShopMenuCocktail{}, err
+		}
+
+		c := model.ShopMenuCocktail{
+			ID:       nc.ID,
+			Name:     nc.Name,
+			Reading:  nc.Reading,
+			ImageURL: nc.ImageURL.String,
+			Price:    price,
+			Availability: model.ShopCocktailAvailability{
+				SoldOut: soldOut,
+				BackAt:  backAt.Int64,
+			},
+			CreatedAt: nc.CreatedAt,
+			UpdatedAt: nc.UpdatedAt,
+		}
+		cocktails = append(cocktails, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []model.ShopMenuCocktail{}, err
+	}
+	rows.Close()
+
+	if len(cocktails) == 0 {
+		return []model.ShopMenuCocktail{}, nil
+	}
+
+	ids := make([]int64, len(cocktails))
+	for i, c := range cocktails {
+		ids[i] = c.ID
+	}
+	out, err := outOfStock(ctx, r.db, shopID, ids)
+	if err != nil {
+		log.Println(err)
+		return []model.ShopMenuCocktail{}, err
+	}
+	for i := range cocktails {
+		cocktails[i].InStock = !out[cocktails[i].ID]
+	}
+
+	return cocktails, nil
+}
+
+// outOfStock returns which of the cocktails the shop stock does not cover the recipe of.
+// Materials the shop does not track, or the recipe gives no quantity of, never run out,
+// while stock kept in a unit the recipe cannot be converted to cannot be ordered and so counts as out.
+func outOfStock(ctx context.Context, q db.Executor, shopID int64, cocktailIDs []int64) (map[int64]bool, error) {
+	query := `
+		SELECT
+			cocktail_materials.cocktail_id,
+			cocktail_materials.quantity,
+			cocktail_materials.unit,
+			shop_inventories.quantity,
+			shop_inventories.unit
+		FROM cocktail_materials
+		INNER JOIN shop_inventories
+			ON shop_inventories.material_id = cocktail_materials.material_id
+			AND shop_inventories.shop_id = ?
+		WHERE cocktail_materials.cocktail_id IN (` + placeholders(len(cocktailIDs)) + `)
+			AND cocktail_materials.quantity > 0
+	`
+
+	args := []interface{}{shopID}
+	for _, id := range cocktailIDs {
+		args = append(args, id)
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[int64]bool{}
+	for rows.Next() {
+		var cocktailID int64
+		var need, stock model.MaterialQuantity
+		var recipeUnit sql.NullString
+		if err := rows.Scan(&cocktailID, &need.Quantity, &recipeUnit, &stock.Quantity, &stock.Unit); err != nil {
+			return nil, err
+		}
+		need.Unit = recipeUnit.String
+
+		left, err := units.Sub(stock, need)
+		if err != nil || left.Quantity < 0 {
+			out[cocktailID] = true
+		}
+	}
+
+	return out, rows.Err()
+}
+
+func (r ShopRepository) AddShopCocktail(ctx context.Context, shopID int64, params model.ShopCocktailParams) ([]*model.ShopCocktail, error) {
+	log.Printf("add shop cocktails... shop_id: %d\n", shopID)
+
+	var cocktails []*model.ShopCocktail
+
+	err := db.InTx(ctx, r.db, func(tx db.Executor) error {
+		cocktails = nil
+
+		findCocktailQuery := `SELECT EXISTS (SELECT * FROM cocktails WHERE id = ? AND deleted_at IS NULL)`
+		findShopCocktailQuery := `SELECT price FROM shop_cocktails WHERE shop_id = ? AND cocktail_id = ?`
+		createShopCocktailQuery := `INSERT INTO shop_cocktails (shop_id, cocktail_id) VALUES (?, ?)`
+		for _, cID := range params.CocktailIDs {
+			var exists bool
+			if err := tx.QueryRowContext(ctx, findCocktailQuery, cID).Scan(&exists); err != nil {
+				return err
+			}
+			if !exists {
+				log.Printf("does not exist cocktails. cokctail_id: %d \n", cID)
+				return fmt.Errorf("%w. cocktail_id: %d", repository.ErrCocktailNotFound, cID)
+			}
+
+			// a cocktail already on the menu keeps its price
+			var price int64
+			err := tx.QueryRowContext(ctx, findShopCocktailQuery, shopID, cID).Scan(&price)
+			if errors.Is(err, sql.ErrNoRows) {
+				_, err = tx.ExecContext(ctx, createShopCocktailQuery, shopID, cID)
+			}
+			if err != nil {
+				log.Printf("fail create shop_cocktail. shop_id: %d, cocktail_id: %d", shopID, cID)
+				return err
+			}
+
+			cocktails = append(cocktails, &model.ShopCocktail{ShopID: shopID, CocktailID: cID, Price: price})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cocktails, nil
+}
+
+func (r ShopRepository) UpdateShopCocktailPrice(ctx context.Context, shopID int64, cocktailID int64, params model.ShopCocktailPriceParams) (*model.ShopCocktail, error) {
+	log.Printf("update shop cocktail price ... shopID: %d, cocktailID: %d \n", shopID, cocktailID)
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT * FROM shop_cocktails WHERE shop_id = ? AND cocktail_id = ?)`, shopID, cocktailID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, repository.ErrShopCocktailNotFound
+	}
+
+	q := `UPDATE shop_cocktails SET price = ? WHERE shop_id = ? AND cocktail_id = ?`
+	if _, err := r.db.ExecContext(ctx, q, params.Price, shopID, cocktailID); err != nil {
+		return nil, err
+	}
+
+	return &model.ShopCocktail{ShopID: shopID, CocktailID: cocktailID, Price: params.Price}, nil
+}
+
+func (r ShopRepository) UpdateShopCocktailAvailability(ctx context.Context, shopID int64, cocktailID int64, params model.ShopCocktailAvailability) (*model.ShopCocktailAvailability, error) {
+	log.Printf("update shop cocktail availability ... shopID: %d, cocktailID: %d \n", shopID, cocktailID)
+
+	var backAt sql.NullInt64
+	if params.SoldOut && params.BackAt != 0 {
+		backAt = sql.NullInt64{Int64: params.BackAt, Valid: true}
+	}
+
+	q := `UPDATE shop_cocktails SET sold_out = ?, back_at = ? WHERE shop_id = ? AND cocktail_id = ?`
+	res, err := r.db.ExecContext(ctx, q, params.SoldOut, backAt, shopID, cocktailID)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		var exists bool
+		err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT * FROM shop_cocktails WHERE shop_id = ? AND cocktail_id = ?)`, shopID, cocktailID).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, repository.ErrShopCocktailNotFound
+		}
+	}
+
+	return &model.ShopCocktailAvailability{SoldOut: params.SoldOut, BackAt: backAt.Int64}, nil
+}
+
+func (r ShopRepository) GetShopCocktailDetail(ctx context.Context, shopID int64, cocktailID int64) (model.CocktailDetail, error) {
+	log.Printf("get shop cocktail detail ... shopID: %d, cocktailID: %d \n", shopID, cocktailID)
+
+	// the materials are left joined, so a cocktail without any is still found
+	q := `
+		SELECT
+		    cocktails.id,
+			cocktails.name,
+			cocktails.reading,
+			cocktails.image_url,
+			materials.id,
+			materials.name,
+			materials.abv,
+			cocktail_materials.quantity,
+			cocktail_materials.unit
+		FROM cocktails
+		INNER JOIN shop_cocktails
+			ON shop_cocktails.cocktail_id = cocktails.id
+		LEFT JOIN cocktail_materials
+			ON cocktails.id = cocktail_materials.cocktail_id
+			LEFT JOIN materials
+				ON cocktail_materials.material_id = materials.id
+		WHERE shop_cocktails.shop_id= ?
+			AND cocktails.id = ?
+		ORDER BY cocktail_materials.rowid
+	`
+
+	rows, err := r.db.QueryContext(ctx, q, shopID, cocktailID)
+	if err != nil {
+		return model.CocktailDetail{}, err
+	}
+
+	defer rows.Close()
+
+	nc := model.NullableCocktail{}
+	materials := []model.Material{}
+	for rows.Next() {
+		var materialABV *float64
+		var materialID sql.NullInt64
+		var quantity sql.NullFloat64
+		var materialName, unit sql.NullString
+		if err := rows.Scan(&nc.ID, &nc.Name, &nc.Reading, &nc.ImageURL, &materialID, &materialName, &materialABV, &quantity, &unit); err != nil {
+			return model.CocktailDetail{}, err
+		}
+
+		if !materialID.Valid {
+			continue
+		}
+		materials = append(materials, model.Material{
+			ID:   materialID.Int64,
+			Name: materialName.String,
+			ABV:  materialABV,
+			Quantity: model.MaterialQuantity{
+				Quantity: quantity.Float64,
+				Unit:     unit.String,
+			},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return model.CocktailDetail{}, err
+	}
+	if nc.ID == 0 {
+		return model.CocktailDetail{}, fmt.Errorf("%w. shop_id: %d, cocktail_id: %d", repository.ErrShopCocktailNotFound, shopID, cocktailID)
+	}
+
+	d := model.CocktailDetail{
+		ID:        nc.ID,
+		Name:      nc.Name,
+		Reading:   nc.Reading,
+		ImageURL:  nc.ImageURL.String,
+		Materials: materials,
+	}
+	if err := loadPreparation(ctx, r.db, &d); err != nil {
+		return model.CocktailDetail{}, err
+	}
+
+	return d, nil
+}
+
+func (r ShopRepository) GetUnprovidedOrderList(ctx context.Context, shopID int64, limit int64, offset int64) ([]*model.TableOrder, error) {
+	log.Printf("get shop unprovided prder list ... shopID: %d \n", shopID)
+
+	statusCondition, statusArgs := orderStatusCondition(model.UnprovidedOrderStatuses)
+
+	q := `SELECT 
+			shop_orders.id,
+			cocktails.name,
+			cocktails.image_url,
+			shop_orders.status
+		FROM shop_tables
+			INNER JOIN shop_orders
+				ON shop_tables.id = shop_orders.table_id
+			INNER JOIN cocktails
+				ON cocktails.id = shop_orders.shop_cocktail_id
+		WHERE shop_tables.shop_id=?
+			AND ` + statusCondition + `
+		ORDER BY shop_orders.id
+		LIMIT ? OFFSET ?`
+
+	args := []interface{}{shopID}
+	args = append(args, statusArgs...)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return []*model.TableOrder{}, err
+	}
+
+	defer rows.Close()
+	var orders []*model.TableOrder
+	for rows.Next() {
+		no := model.NullableTableOrder{}
+		if err := rows.Scan(&no.ID, &no.Name, &no.ImageURL, &no.Status); err != nil {
+			return nil, err
+		}
+
+		to := &model.TableOrder{
+			ID:       no.ID,
+			Name:     no.Name,
+			ImageURL: no.ImageURL.String,
+			Status:   no.Status,
+		}
+		orders = append(orders, to)
+	}
+	if len(orders) == 0 {
+		return []*model.TableOrder{}, nil
+	}
+
+	return orders, nil
+}
+
+func (r ShopRepository) AddTable(ctx context.Context, shopID int64) (*model.Table, error) {
+	log.Println("create shop table ...")
+
+	query := `INSERT INTO shop_tables (shop_id) VALUES (?)`
+	res, err := r.db.ExecContext(ctx, query, shopID)
+	if err != nil {
+		return nil, err
+	}
+
+	tableID, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Table{ID: tableID, ShopID: shopID}, nil
+}
+
+func (r ShopRepository) GetTable(ctx context.Context, shopID int64, tableID int64) (*model.Table, error) {
+	log.Printf("get table ... shopID: %d, tabelID: %d \n", shopID, tableID)
+
+	q := `SELECT id, shop_id FROM shop_tables WHERE id=? AND shop_id=?`
+	rows, err := r.db.QueryContext(ctx, q, tableID, shopID)
+	if err != nil {
+		return &model.Table{}, err
+	}
+
+	defer rows.Close()
+
+	var t model.Table
+	for rows.Next() {
+		if err := rows.Scan(&t.ID, &t.ShopID); err != nil {
+			return &model.Table{}, err
+		}
+	}
+	if t.ID == 0 {
+		return &model.Table{}, fmt.Errorf("%w. shop_id: %d, table_id: %d", repository.ErrTableNotFound, shopID, tableID)
+	}
+	return &t, nil
+}
+
+func (r ShopRepository) GetTableOrderList(ctx context.Context, shopID int64, tableID int64, unprovided bool) ([]*model.TableOrder, error) {
+	log.Printf("get table order list ... shopID: %d, tableID: %d \n", shopID, tableID)
+
+	q := `SELECT 
+			shop_orders.id,
+			cocktails.name,
+			cocktails.image_url,
+			shop_orders.status
+		FROM shop_tables
+			INNER JOIN shop_orders
+				ON shop_tables.id = shop_orders.table_id
+			INNER JOIN cocktails
+				ON cocktails.id = shop_orders.shop_cocktail_id
+		WHERE shop_tables.shop_id=?
+			AND shop_tables.id=?`
+	args := []interface{}{shopID, tableID}
+
+	if unprovided {
+		statusCondition, statusArgs := orderStatusCondition(model.UnprovidedOrderStatuses)
+		q += ` AND ` + statusCondition
+		args = append(args, statusArgs...)
+	}
+	q += ` ORDER BY shop_orders.id`
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return []*model.TableOrder{}, err
+	}
+
+	defer rows.Close()
+	var orders []*model.TableOrder
+	for rows.Next() {
+		no := model.NullableTableOrder{}
+		if err := rows.Scan(&no.ID, &no.Name, &no.ImageURL, &no.Status); err != nil {
+			return nil, err
+		}
+
+		to := &model.TableOrder{
+			ID:       no.ID,
+			Name:     no.Name,
+			ImageURL: no.ImageURL.String,
+			Status:   no.Status,
+		}
+		orders = append(orders, to)
+	}
+	if len(orders) == 0 {
+		return []*model.TableOrder{}, nil
+	}
+
+	return orders, nil
+}
+
+func (r ShopRepository) GetTableBillOrders(ctx context.Context, shopID int64, tableID int64) ([]model.BillOrder, error) {
+	log.Printf("get table bill orders ... shopID: %d, tableID: %d \n", shopID, tableID)
+
+	q := `SELECT
+			shop_orders.id,
+			cocktails.id,
+			cocktails.name,
+			shop_orders.price,
+			shop_orders.created_at
+		FROM shop_tables
+			INNER JOIN shop_orders
+				ON shop_tables.id = shop_orders.table_id
+			INNER JOIN cocktails
+				ON cocktails.id = shop_orders.shop_cocktail_id
+		WHERE shop_tables.shop_id = ?
+			AND shop_tables.id = ?
+			AND shop_orders.status <> ?
+		ORDER BY shop_orders.id`
+
+	rows, err := r.db.QueryContext(ctx, q, shopID, tableID, model.OrderStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []model.BillOrder
+	for rows.Next() {
+		o := model.BillOrder{}
+		if err := rows.Scan(&o.OrderID, &o.CocktailID, &o.Name, &o.Price, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(orders) == 0 {
+		return []model.BillOrder{}, nil
+	}
+
+	return orders, nil
+}
+
+func (r ShopRepository) Order(ctx context.Context, shopID int64, tableID int64, params model.OrderParams) ([]*model.Order, error) {
+	log.Printf("receive order... shop_id: %d, table_id: %d \n", shopID, tableID)
+
+	var orders []*model.Order
+
+	now := time.Now().Unix()
+
+	err := db.InTx(ctx, r.db, func(tx db.Executor) error {
+		orders = nil
+
+		var found int
+		err := tx.QueryRowContext(ctx, `SELECT 1 FROM shop_tables WHERE id=? AND shop_id=?`, tableID, shopID).Scan(&found)
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("does not exist shop_tables. shop_id: %d, table_id: %d \n", shopID, tableID)
+			return fmt.Errorf("%w. shop_id: %d, table_id: %d", repository.ErrTableNotFound, shopID, tableID)
+		}
+		if err != nil {
+			return err
+		}
+
+		findCocktailQuery := `SELECT price, sold_out, back_at FROM shop_cocktails WHERE shop_id=? AND cocktail_id=? LIMIT 1`
+		orderQuery := `INSERT INTO shop_orders (table_id, shop_cocktail_id, price, status, accepted_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+		for _, cID := range params.CocktailIDs {
+			var price int64
+			var soldOut bool
+			var backAt sql.NullInt64
+			err := tx.QueryRowContext(ctx, findCocktailQuery, shopID, cID).Scan(&price, &soldOut, &backAt)
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("does not exist shop_cocktails. shop_id: %d, cocktail_id: %d \n", shopID, cID)
+				return repository.ErrShopCocktailNotFound
+			}
+			if err != nil {
+				log.Printf("cannot find shop_cocktails. shop_id: %d, cocktail_id: %d\n", shopID, cID)
+				return err
+			}
+
+			availability := model.ShopCocktailAvailability{SoldOut: soldOut, BackAt: backAt.Int64}
+			if availability.IsSoldOut(now) {
+				return fmt.Errorf("%w. cocktail_id: %d", repository.ErrSoldOut, cID)
+			}
+
+			deductions, err := deductInventory(ctx, tx, shopID, cID, now)
+			if err != nil {
+				log.Printf("fail deduct inventory. shop_id: %d, cocktail_id: %d, err: %v", shopID, cID, err)
+				return err
+			}
+
+			res, err := tx.ExecContext(ctx, orderQuery, tableID, cID, price, model.OrderStatusAccepted, now, now, now)
+			if err != nil {
+				log.Printf("fail create order. shop_id: %d, table_id: %d, cocktail_id: %d", shopID, tableID, cID)
+				return err
+			}
+
+			orderID, err := res.LastInsertId()
+			if err != nil {
+				return err
+			}
+
+			if err := recordDeductions(ctx, tx, orderID, deductions); err != nil {
+				log.Printf("fail record deductions. order_id: %d, err: %v", orderID, err)
+				return err
+			}
+
+			o := &model.Order{ID: orderID, TableID: tableID, ShopCocktailID: cID, Price: price, CreatedAt: now}
+			o.SetStatus(model.OrderStatusAccepted, now)
+			orders = append(orders, o)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// deduction is what an order took of a material, in the unit the shop stock was kept in.
+type deduction struct {
+	materialID int64
+	quantity   float64
+	unit       string
+}
+
+// deductInventory subtracts the recipe of the cocktail from the shop stock and returns what it took.
+// Materials the shop does not track, or the recipe gives no quantity of, are left untouched.
+// The recipe is converted to the unit the stock is kept in, and stock kept in a unit it cannot be converted to
+// fails the order with ErrInventoryUnitMismatch rather than going unnoticed.
+func deductInventory(ctx context.Context, tx db.Executor, shopID int64, cocktailID int64, now int64) ([]deduction, error) {
+	q := `
+		SELECT
+			cocktail_materials.material_id,
+			cocktail_materials.quantity,
+			cocktail_materials.unit,
+			shop_inventories.quantity,
+			shop_inventories.unit
+		FROM cocktail_materials
+		INNER JOIN shop_inventories
+			ON shop_inventories.material_id = cocktail_materials.material_id
+			AND shop_inventories.shop_id = ?
+		WHERE cocktail_materials.cocktail_id = ?
+	`
+
+	rows, err := tx.QueryContext(ctx, q, shopID, cocktailID)
+	if err != nil {
+		return nil, err
+	}
+
+	var deductions []deduction
+	for rows.Next() {
+		var materialID int64
+		var stock model.MaterialQuantity
+		var need sql.NullFloat64
+		var recipeUnit sql.NullString
+		if err := rows.Scan(&materialID, &need, &recipeUnit, &stock.Quantity, &stock.Unit); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		if need.Float64 == 0 {
+			continue
+		}
+		left, err := units.Sub(stock, model.MaterialQuantity{Quantity: need.Float64, Unit: recipeUnit.String})
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%w. material_id: %d, recipe: %s, stock: %s, %v", repository.ErrInventoryUnitMismatch, materialID, recipeUnit.String, stock.Unit, err)
+		}
+		if left.Quantity < 0 {
+			rows.Close()
+			return nil, fmt.Errorf("%w. material_id: %d", repository.ErrOutOfStock, materialID)
+		}
+
+		deductions = append(deductions, deduction{materialID: materialID, quantity: stock.Quantity - left.Quantity, unit: stock.Unit})
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	updateQuery := `UPDATE shop_inventories SET quantity = quantity - ?, updated_at = ? WHERE shop_id = ? AND material_id = ?`
+	for _, d := range deductions {
+		if _, err := tx.ExecContext(ctx, updateQuery, d.quantity, now, shopID, d.materialID); err != nil {
+			return nil, err
+		}
+	}
+
+	return deductions, nil
+}
+
+// recordDeductions keeps what the order took, so cancelling it gives back exactly that whatever the recipe becomes.
+func recordDeductions(ctx context.Context, tx db.Executor, orderID int64, deductions []deduction) error {
+	q := `INSERT INTO shop_order_deductions (order_id, material_id, quantity, unit) VALUES (?, ?, ?, ?)`
+	for _, d := range deductions {
+		if _, err := tx.ExecContext(ctx, q, orderID, d.materialID, d.quantity, d.unit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r ShopRepository) GetOrder(ctx context.Context, shopID int64, tableID int64, orderID int64) (*model.Order, error) {
+	log.Printf("get order ... shopID: %d, tableID: %d, orderID: %d \n", shopID, tableID, orderID)
+
+	q := `SELECT 
+			shop_orders.id,
+			shop_orders.table_id,
+			shop_orders.shop_cocktail_id,
+			shop_orders.price,
+			shop_orders.status,
+			shop_orders.accepted_at,
+			shop_orders.preparing_at,
+			shop_orders.ready_at,
+			shop_orders.served_at,
+			shop_orders.cancelled_at,
+			shop_orders.created_at,
+			shop_orders.updated_at
+		FROM shop_tables
+			INNER JOIN shop_orders
+				ON shop_tables.id = shop_orders.table_id
+		WHERE shop_tables.shop_id=?
+			AND shop_tables.id=? 
+			AND shop_orders.id = ?`
+
+	o := model.Order{}
+	var acceptedAt, preparingAt, readyAt, servedAt, cancelledAt sql.NullInt64
+	err := r.db.QueryRowContext(ctx, q, shopID, tableID, orderID).Scan(
+		&o.ID, &o.TableID, &o.ShopCocktailID, &o.Price, &o.Status,
+		&acceptedAt, &preparingAt, &readyAt, &servedAt, &cancelledAt,
+		&o.CreatedAt, &o.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	o.AcceptedAt = acceptedAt.Int64
+	o.PreparingAt = preparingAt.Int64
+	o.ReadyAt = readyAt.Int64
+	o.ServedAt = servedAt.Int64
+	o.CancelledAt = cancelledAt.Int64
+
+	return &o, nil
+}
+
+// orderStatusColumns are the columns recording when an order reached each status.
+var orderStatusColumns = map[model.OrderStatus]string{
+	model.OrderStatusAccepted:  "accepted_at",
+	model.OrderStatusPreparing: "preparing_at",
+	model.OrderStatusReady:     "ready_at",
+	model.OrderStatusServed:    "served_at",
+	model.OrderStatusCancelled: "cancelled_at",
+}
+
+func (r ShopRepository) UpdateOrderStatus(ctx context.Context, orderID int64, from model.OrderStatus, to model.OrderStatus, at int64) error {
+	log.Printf("update order status ... orderID: %d, %s -> %s \n", orderID, from, to)
+
+	column, ok := orderStatusColumns[to]
+	if !ok {
+		return fmt.Errorf("%w. unknown status: %s", repository.ErrInvalidOrderTransition, to)
+	}
+
+	// the status condition makes concurrent transitions from the same status fail instead of overwriting each other
+	q := `UPDATE shop_orders SET status = ?, ` + column + ` = ?, updated_at = ? WHERE id = ? AND status = ?`
+	res, err := r.db.ExecContext(ctx, q, to, at, at, orderID, from)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w. order is no longer %s", repository.ErrInvalidOrderTransition, from)
+	}
+
+	return nil
+}
+
+// orderStatusCondition builds a SQL condition matching orders in one of the statuses.
+func orderStatusCondition(statuses []model.OrderStatus) (string, []interface{}) {
+	var args []interface{}
+	for _, s := range statuses {
+		args = append(args, s)
+	}
+	return `shop_orders.status IN (` + placeholders(len(statuses)) + `)`, args
+}
+
+func (r ShopRepository) GetInventory(ctx context.Context, shopID int64) ([]model.InventoryItem, error) {
+	log.Printf("get shop inventory ... shopID: %d \n", shopID)
+
+	q := `
+		SELECT
+			materials.id,
+			materials.name,
+			shop_inventories.quantity,
+			shop_inventories.unit,
+			shop_inventories.updated_at
+		FROM shop_inventories
+		INNER JOIN materials
+			ON shop_inventories.material_id = materials.id
+		WHERE shop_inventories.shop_id = ?
+		ORDER BY materials.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, q, shopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.InventoryItem
+	for rows.Next() {
+		i := model.InventoryItem{}
+		if err := rows.Scan(&i.MaterialID, &i.MaterialName, &i.Quantity, &i.Unit, &i.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return []model.InventoryItem{}, nil
+	}
+
+	return items, nil
+}
+
+func (r ShopRepository) UpdateInventory(ctx context.Context, shopID int64, params model.InventoryParams) ([]model.InventoryItem, error) {
+	log.Printf("update shop inventory ... shopID: %d \n", shopID)
+
+	now := time.Now().Unix()
+
+	var items []model.InventoryItem
+
+	err := db.InTx(ctx, r.db, func(tx db.Executor) error {
+		items = []model.InventoryItem{}
+
+		findMaterialQuery := `SELECT name FROM materials WHERE id = ?`
+		upsertQuery := `
+			INSERT INTO shop_inventories (shop_id, material_id, quantity, unit, updated_at) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (shop_id, material_id) DO UPDATE SET quantity = excluded.quantity, unit = excluded.unit, updated_at = excluded.updated_at
+		`
+		for _, m := range params.Materials {
+			var name string
+			err := tx.QueryRowContext(ctx, findMaterialQuery, m.MaterialID).Scan(&name)
+			if errors.Is(err, sql.ErrNoRows) {
+				return repository.ErrMaterialNotFound
+			}
+			if err != nil {
+				return err
+			}
+
+			if _, err := tx.ExecContext(ctx, upsertQuery, shopID, m.MaterialID, m.Quantity, m.Unit, now); err != nil {
+				log.Printf("fail update inventory. shop_id: %d, material_id: %d", shopID, m.MaterialID)
+				return err
+			}
+
+			items = append(items, model.InventoryItem{
+				MaterialID:   m.MaterialID,
+				MaterialName: name,
+				Quantity:     m.Quantity,
+				Unit:         m.Unit,
+				UpdatedAt:    now,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// RestoreInventory puts back into the shop stock what the order took when it was placed.
+// Materials the shop no longer tracks are left untouched, and so are ones it now tracks in a unit
+// the deduction cannot be converted to, which is logged since that stock is left short.
+func (r ShopRepository) RestoreInventory(ctx context.Context, shopID int64, orderID int64, at int64) error {
+	log.Printf("restore inventory ... shopID: %d, orderID: %d \n", shopID, orderID)
+
+	q := `
+		SELECT
+			shop_order_deductions.material_id,
+			shop_order_deductions.quantity,
+			shop_order_deductions.unit,
+			shop_inventories.quantity,
+			shop_inventories.unit
+		FROM shop_order_deductions
+		INNER JOIN shop_inventories
+			ON shop_inventories.material_id = shop_order_deductions.material_id
+			AND shop_inventories.shop_id = ?
+		WHERE shop_order_deductions.order_id = ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, q, shopID, orderID)
+	if err != nil {
+		return err
+	}
+
+	type restoration struct {
+		materialID int64
+		quantity   float64
+	}
+	var restorations []restoration
+	for rows.Next() {
+		var materialID int64
+		var taken, stock model.MaterialQuantity
+		if err := rows.Scan(&materialID, &taken.Quantity, &taken.Unit, &stock.Quantity, &stock.Unit); err != nil {
+			rows.Close()
+			return err
+		}
+
+		// giving the deduction back is taking out a negative amount of it
+		restored, err := units.Sub(stock, model.MaterialQuantity{Quantity: -taken.Quantity, Unit: taken.Unit})
+		if err != nil {
+			log.Printf("cannot restore inventory. order_id: %d, material_id: %d, taken: %s, stock: %s, err: %v", orderID, materialID, taken.Unit, stock.Unit, err)
+			continue
+		}
+		restorations = append(restorations, restoration{materialID: materialID, quantity: restored.Quantity - stock.Quantity})
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	updateQuery := `UPDATE shop_inventories SET quantity = quantity + ?, updated_at = ? WHERE shop_id = ? AND material_id = ?`
+	for _, re := range restorations {
+		if _, err := r.db.ExecContext(ctx, updateQuery, re.quantity, at, shopID, re.materialID); err != nil {
+			return err
+		}
+	}
+
+	// the deductions are given back once, however often this is called
+	_, err = r.db.ExecContext(ctx, `DELETE FROM shop_order_deductions WHERE order_id = ?`, orderID)
+	return err
+}
@@ -0,0 +1,26 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/shake551/cocktails-api/db"
+	"github.com/shake551/cocktails-api/domain/repository"
+)
+
+type UnitOfWork struct {
+	db db.Executor
+}
+
+func NewUnitOfWork(e db.Executor) *UnitOfWork {
+	return &UnitOfWork{db: e}
+}
+
+func (u UnitOfWork) Do(ctx context.Context, fn func(repos repository.Repositories) error) error {
+	return db.InTx(ctx, u.db, func(tx db.Executor) error {
+		return fn(repository.Repositories{
+			Cocktail: NewCocktailRepository(tx),
+			Material: NewMaterialRepository(tx),
+			Shop:     NewShopRepository(tx),
+		})
+	})
+}
@@ -2,20 +2,29 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
 	"github.com/go-chi/chi"
 	"github.com/shake551/cocktails-api/application/usecase"
+	"github.com/shake551/cocktails-api/domain/errs"
 	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/domain/units"
 	"log"
 	"net/http"
+	"reflect"
 	"strconv"
+	"strings"
 )
 
 type CocktailHandler interface {
 	GetLimit(w http.ResponseWriter, r *http.Request)
 	GetById(w http.ResponseWriter, r *http.Request)
+	Batch(w http.ResponseWriter, r *http.Request)
 	Create(w http.ResponseWriter, r *http.Request)
 	GetListByIDs(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Patch(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	GetMakeable(w http.ResponseWriter, r *http.Request)
 }
 
 type cocktailHandler struct {
@@ -36,8 +45,7 @@ func (h *cocktailHandler) GetLimit(w http.ResponseWriter, r *http.Request) {
 	if v.Get("limit") != "" {
 		l, err := strconv.ParseInt(v.Get("limit"), 10, 64)
 		if err != nil {
-			log.Printf("failed to get limit. err: %v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			writeError(w, errs.BadRequest("limit must be an integer"))
 			return
 		}
 
@@ -48,27 +56,39 @@ func (h *cocktailHandler) GetLimit(w http.ResponseWriter, r *http.Request) {
 	if v.Get("offset") != "" {
 		o, err := strconv.ParseInt(v.Get("offset"), 10, 64)
 		if err != nil {
-			log.Printf("failed to get offset. err: %v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			writeError(w, errs.BadRequest("offset must be an integer"))
 			return
 		}
 
 		offset = o
 	}
 
-	var keyword = v.Get("keyword")
+	filter := model.CocktailFilter{
+		Keyword:          v.Get("keyword"),
+		Materials:        splitQueryValues(v["material"]),
+		ExcludeMaterials: splitQueryValues(v["exclude_material"]),
+	}
+	if v.Get("max_abv") != "" {
+		maxABV, err := strconv.ParseFloat(v.Get("max_abv"), 64)
+		if err != nil {
+			writeError(w, errs.BadRequest("max_abv must be a number"))
+			return
+		}
+
+		filter.MaxABV = &maxABV
+	}
 
-	cocktails, err := h.u.GetLimit(r.Context(), limit, offset, keyword)
+	cocktails, err := h.u.GetLimit(r.Context(), limit, offset, filter)
 	if err != nil {
 		log.Printf("failed to get cocktails. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	b, err := json.Marshal(cocktails)
 	if err != nil {
 		log.Printf("failed to parse json. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -78,24 +98,79 @@ func (h *cocktailHandler) GetLimit(w http.ResponseWriter, r *http.Request) {
 	w.Write(b)
 }
 
+// splitQueryValues accepts both repeated query parameters and comma separated values.
+func splitQueryValues(values []string) []string {
+	var res []string
+	for _, v := range values {
+		res = append(res, strings.Split(v, ",")...)
+	}
+	return res
+}
+
 func (h *cocktailHandler) GetById(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "cocktailsID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrCocktailNotFound)
 		return
 	}
 
-	cocktailsDetail, err := h.u.GetById(r.Context(), id)
+	system, err := units.ParseSystem(r.URL.Query().Get("units"))
+	if err != nil {
+		writeError(w, errs.BadRequest("units must be metric or imperial"))
+		return
+	}
+
+	cocktailsDetail, err := h.u.GetById(r.Context(), id, system)
 	if err != nil {
 		log.Printf("failed to get cocktails detail. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	b, err := json.Marshal(cocktailsDetail)
 	if err != nil {
 		log.Printf("failed to parse json. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func (h *cocktailHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "cocktailsID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrCocktailNotFound)
+		return
+	}
+
+	v := r.URL.Query()
+	servings, err := strconv.ParseInt(v.Get("servings"), 10, 64)
+	if err != nil {
+		writeError(w, errs.BadRequest("servings must be an integer"))
+		return
+	}
+
+	system, err := units.ParseSystem(v.Get("units"))
+	if err != nil {
+		writeError(w, errs.BadRequest("units must be metric or imperial"))
+		return
+	}
+
+	batch, err := h.u.Batch(r.Context(), id, model.BatchParams{Servings: servings}, system)
+	if err != nil {
+		log.Printf("failed to scale cocktail. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(batch)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
 		return
 	}
 
@@ -107,7 +182,13 @@ func (h *cocktailHandler) GetById(w http.ResponseWriter, r *http.Request) {
 
 type PostCocktailsBody struct {
 	Name      string                  `json:"name"`
+	Reading   string                  `json:"reading"`
 	Materials []PostCocktailsMaterial `json:"materials"`
+	Method    string                  `json:"method"`
+	Glass     string                  `json:"glass"`
+	Garnish   string                  `json:"garnish"`
+	Notes     string                  `json:"notes"`
+	Steps     []string                `json:"steps"`
 }
 
 type PostCocktailsMaterial struct {
@@ -116,46 +197,72 @@ type PostCocktailsMaterial struct {
 }
 
 type PostCocktailsQuantity struct {
-	Quantity json.Number `json:"quantity"`
-	Unit     string      `json:"unit"`
+	Quantity amount `json:"quantity"`
+	Unit     string `json:"unit"`
 }
 
-func (h *cocktailHandler) Create(w http.ResponseWriter, r *http.Request) {
-	body := &PostCocktailsBody{}
-	if err := json.NewDecoder(r.Body).Decode(body); err != nil {
-		log.Printf("bad request error. err: %v, body: %v", err, body)
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
-		return
+// amount is a quantity written either as a JSON number or as a string like "1 1/2" or "½".
+type amount float64
+
+func (a *amount) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		var f float64
+		if err := json.Unmarshal(b, &f); err != nil {
+			return err
+		}
+		*a = amount(f)
+		return nil
 	}
 
+	f, err := units.ParseAmount(s)
+	if err != nil {
+		return &json.UnmarshalTypeError{Value: "string " + strconv.Quote(s), Type: reflect.TypeOf(f)}
+	}
+	*a = amount(f)
+	return nil
+}
+
+func toMaterialParams(body []PostCocktailsMaterial) []model.MaterialParams {
 	var materials []model.MaterialParams
-	for _, material := range body.Materials {
-		quantity, err := material.Quantity.Quantity.Int64()
-		if err != nil {
-			fmt.Println(err)
-		}
+	for _, material := range body {
 		materials = append(materials, model.MaterialParams{Name: material.Name, Quantity: model.MaterialQuantity{
-			Quantity: quantity,
+			Quantity: float64(material.Quantity.Quantity),
 			Unit:     material.Quantity.Unit,
 		}})
 	}
+	return materials
+}
+
+func (h *cocktailHandler) Create(w http.ResponseWriter, r *http.Request) {
+	body := &PostCocktailsBody{}
+	if err := decodeJSON(r, body); err != nil {
+		writeError(w, err)
+		return
+	}
 
 	params := model.CocktailParams{
 		Name:      body.Name,
-		Materials: materials,
+		Reading:   body.Reading,
+		Materials: toMaterialParams(body.Materials),
+		Method:    model.Method(body.Method),
+		Glass:     body.Glass,
+		Garnish:   body.Garnish,
+		Notes:     body.Notes,
+		Steps:     body.Steps,
 	}
 
 	coc, err := h.u.Create(r.Context(), params)
 	if err != nil {
 		log.Printf("failed to create cocktail. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	b, err := json.Marshal(coc)
 	if err != nil {
 		log.Printf("failed to parse json")
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -176,8 +283,7 @@ func (h *cocktailHandler) GetListByIDs(w http.ResponseWriter, r *http.Request) {
 		if v.Get("ids["+strconv.Itoa(i)+"]") != "" {
 			id, err := strconv.ParseInt(v.Get("ids["+strconv.Itoa(i)+"]"), 10, 64)
 			if err != nil {
-				log.Println(err)
-				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				writeError(w, errs.BadRequest("ids must be integers"))
 				return
 			}
 			ids = append(ids, id)
@@ -189,14 +295,161 @@ func (h *cocktailHandler) GetListByIDs(w http.ResponseWriter, r *http.Request) {
 	cocktails, err := h.u.GetListByIDs(r.Context(), ids)
 	if err != nil {
 		log.Printf("failed to get cocktails. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(cocktails)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func (h *cocktailHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "cocktailsID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrCocktailNotFound)
+		return
+	}
+
+	body := &PostCocktailsBody{}
+	if err := decodeJSON(r, body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	params := model.CocktailParams{
+		Name:      body.Name,
+		Reading:   body.Reading,
+		Materials: toMaterialParams(body.Materials),
+		Method:    model.Method(body.Method),
+		Glass:     body.Glass,
+		Garnish:   body.Garnish,
+		Notes:     body.Notes,
+		Steps:     body.Steps,
+	}
+
+	coc, err := h.u.Update(r.Context(), id, params)
+	if err != nil {
+		log.Printf("failed to update cocktail. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(coc)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+type PatchCocktailsBody struct {
+	Name      *string                 `json:"name"`
+	Reading   *string                 `json:"reading"`
+	Materials []PostCocktailsMaterial `json:"materials"`
+	Method    *model.Method           `json:"method"`
+	Glass     *string                 `json:"glass"`
+	Garnish   *string                 `json:"garnish"`
+	Notes     *string                 `json:"notes"`
+	Steps     []string                `json:"steps"`
+}
+
+func (h *cocktailHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "cocktailsID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrCocktailNotFound)
+		return
+	}
+
+	body := &PatchCocktailsBody{}
+	if err := decodeJSON(r, body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	params := model.CocktailPatchParams{
+		Name:    body.Name,
+		Reading: body.Reading,
+		Method:  body.Method,
+		Glass:   body.Glass,
+		Garnish: body.Garnish,
+		Notes:   body.Notes,
+	}
+	if body.Materials != nil {
+		params.Materials = append([]model.MaterialParams{}, toMaterialParams(body.Materials)...)
+	}
+	if body.Steps != nil {
+		params.Steps = append([]string{}, body.Steps...)
+	}
+
+	coc, err := h.u.Patch(r.Context(), id, params)
+	if err != nil {
+		log.Printf("failed to patch cocktail. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(coc)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func (h *cocktailHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "cocktailsID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrCocktailNotFound)
+		return
+	}
+
+	err = h.u.Delete(r.Context(), id)
+	if err != nil {
+		log.Printf("failed to delete cocktail. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *cocktailHandler) GetMakeable(w http.ResponseWriter, r *http.Request) {
+	body := model.MakeableParams{}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	cocktails, err := h.u.GetMakeable(r.Context(), body)
+	if err != nil {
+		log.Printf("failed to get makeable cocktails. err: %v", err)
+		writeError(w, err)
 		return
 	}
 
 	b, err := json.Marshal(cocktails)
 	if err != nil {
 		log.Printf("failed to parse json. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/shake551/cocktails-api/domain/errs"
+)
+
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code    errs.Kind         `json:"code"`
+	Message string            `json:"message"`
+	Fields  []errs.FieldError `json:"fields,omitempty"`
+}
+
+var errorStatus = map[errs.Kind]int{
+	errs.KindBadRequest:  http.StatusBadRequest,
+	errs.KindNotFound:    http.StatusNotFound,
+	errs.KindConflict:    http.StatusConflict,
+	errs.KindValidation:  http.StatusUnprocessableEntity,
+	errs.KindTooLarge:    http.StatusRequestEntityTooLarge,
+	errs.KindUnsupported: http.StatusUnsupportedMediaType,
+}
+
+// writeError writes err as a JSON error response.
+// Errors which are not typed domain errors are hidden behind a 500 response.
+func writeError(w http.ResponseWriter, err error) {
+	kind := errs.KindOf(err)
+
+	status, ok := errorStatus[kind]
+	message := err.Error()
+	fields := errs.FieldsOf(err)
+	if !ok {
+		status = http.StatusInternalServerError
+		message = http.StatusText(http.StatusInternalServerError)
+	}
+
+	b, _ := json.Marshal(ErrorResponse{Error: ErrorBody{Code: kind, Message: message, Fields: fields}})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(status)
+	w.Write(b)
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/shake551/cocktails-api/application/usecase"
+	"github.com/shake551/cocktails-api/domain/errs"
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/photo"
+	"github.com/shake551/cocktails-api/domain/repository"
+)
+
+type ImageHandler interface {
+	UploadCocktailImage(w http.ResponseWriter, r *http.Request)
+	Get(w http.ResponseWriter, r *http.Request)
+	GetVariant(w http.ResponseWriter, r *http.Request)
+}
+
+type imageHandler struct {
+	u usecase.ImageUseCase
+}
+
+func NewImageHandler(u usecase.ImageUseCase) ImageHandler {
+	return &imageHandler{u}
+}
+
+// UploadCocktailImage takes the image from the "image" field of a multipart/form-data body.
+func (h *imageHandler) UploadCocktailImage(w http.ResponseWriter, r *http.Request) {
+	cocktailID, err := strconv.ParseInt(chi.URLParam(r, "cocktailsID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrCocktailNotFound)
+		return
+	}
+
+	data, err := readImagePart(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	img, err := h.u.UploadCocktailImage(r.Context(), cocktailID, data)
+	if err != nil {
+		log.Printf("failed to upload cocktail image. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(img)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusCreated)
+	w.Write(b)
+}
+
+// readImagePart streams the parts of the body up to the "image" one, so the upload is never held in memory twice
+// and a large one is not spooled to disk. A byte more than allowed is read to tell an oversized image apart.
+func readImagePart(r *http.Request) ([]byte, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, errs.BadRequest("request body must be multipart/form-data")
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, errs.BadRequest("image is required")
+		}
+		if err != nil {
+			return nil, errs.BadRequest("invalid request body")
+		}
+		if part.FormName() != "image" {
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(part, photo.MaxSize+1))
+		if err != nil {
+			return nil, errs.BadRequest("invalid request body")
+		}
+		return data, nil
+	}
+}
+
+func (h *imageHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "imageID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrImageNotFound)
+		return
+	}
+
+	img, err := h.u.Get(r.Context(), id)
+	if err != nil {
+		log.Printf("failed to get image. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	writeImage(w, img)
+}
+
+// GetVariant serves a resized variant of the image, like /images/4/thumbnail.jpg.
+func (h *imageHandler) GetVariant(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "imageID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrImageNotFound)
+		return
+	}
+
+	img, err := h.u.GetVariant(r.Context(), id, chi.URLParam(r, "variant"))
+	if err != nil {
+		log.Printf("failed to get image variant. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	writeImage(w, img)
+}
+
+func writeImage(w http.ResponseWriter, img model.Image) {
+	w.Header().Set("Content-Type", img.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(img.Data)))
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	w.Write(img.Data)
+}
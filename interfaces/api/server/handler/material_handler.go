@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi"
+	"github.com/shake551/cocktails-api/application/usecase"
+	"github.com/shake551/cocktails-api/domain/errs"
+	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+type MaterialHandler interface {
+	GetLimit(w http.ResponseWriter, r *http.Request)
+	GetByID(w http.ResponseWriter, r *http.Request)
+	Create(w http.ResponseWriter, r *http.Request)
+	Rename(w http.ResponseWriter, r *http.Request)
+	UpdateABV(w http.ResponseWriter, r *http.Request)
+	AddAlias(w http.ResponseWriter, r *http.Request)
+	DeleteAlias(w http.ResponseWriter, r *http.Request)
+	Merge(w http.ResponseWriter, r *http.Request)
+}
+
+type materialHandler struct {
+	u usecase.MaterialUseCase
+}
+
+func NewMaterialHandler(u usecase.MaterialUseCase) MaterialHandler {
+	return &materialHandler{u}
+}
+
+func (h *materialHandler) GetLimit(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	if v == nil {
+		return
+	}
+
+	var limit = int64(30)
+	if v.Get("limit") != "" {
+		l, err := strconv.ParseInt(v.Get("limit"), 10, 64)
+		if err != nil {
+			writeError(w, errs.BadRequest("limit must be an integer"))
+			return
+		}
+
+		limit = l
+	}
+
+	var offset = int64(0)
+	if v.Get("offset") != "" {
+		o, err := strconv.ParseInt(v.Get("offset"), 10, 64)
+		if err != nil {
+			writeError(w, errs.BadRequest("offset must be an integer"))
+			return
+		}
+
+		offset = o
+	}
+
+	var keyword = v.Get("keyword")
+
+	materials, err := h.u.GetLimit(r.Context(), limit, offset, keyword)
+	if err != nil {
+		log.Printf("failed to get materials. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(materials)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func (h *materialHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "materialID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrMaterialNotFound)
+		return
+	}
+
+	d, err := h.u.GetByID(r.Context(), id)
+	if err != nil {
+		log.Printf("failed to get material detail. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func (h *materialHandler) Create(w http.ResponseWriter, r *http.Request) {
+	body := model.MaterialNameParams{}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	m, err := h.u.Create(r.Context(), body)
+	if err != nil {
+		log.Printf("failed to create material. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusCreated)
+	w.Write(b)
+}
+
+func (h *materialHandler) Rename(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "materialID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrMaterialNotFound)
+		return
+	}
+
+	body := model.MaterialNameParams{}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	m, err := h.u.Rename(r.Context(), id, body)
+	if err != nil {
+		log.Printf("failed to rename material. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func (h *materialHandler) UpdateABV(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "materialID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrMaterialNotFound)
+		return
+	}
+
+	body := model.MaterialABVParams{}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	m, err := h.u.UpdateABV(r.Context(), id, body)
+	if err != nil {
+		log.Printf("failed to update material abv. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func (h *materialHandler) AddAlias(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "materialID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrMaterialNotFound)
+		return
+	}
+
+	body := model.MaterialNameParams{}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	a, err := h.u.AddAlias(r.Context(), id, body)
+	if err != nil {
+		log.Printf("failed to add material alias. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(a)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusCreated)
+	w.Write(b)
+}
+
+func (h *materialHandler) DeleteAlias(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "materialID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrMaterialNotFound)
+		return
+	}
+
+	aliasID, err := strconv.ParseInt(chi.URLParam(r, "aliasID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrMaterialAliasNotFound)
+		return
+	}
+
+	err = h.u.DeleteAlias(r.Context(), id, aliasID)
+	if err != nil {
+		log.Printf("failed to delete material alias. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *materialHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	body := model.MaterialMergeParams{}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	d, err := h.u.Merge(r.Context(), body)
+	if err != nil {
+		log.Printf("failed to merge material. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/shake551/cocktails-api/domain/errs"
+)
+
+// decodeJSON decodes the request body into v.
+// Unknown fields and values of the wrong type are reported as field errors.
+func decodeJSON(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &te) {
+		return errs.InvalidFields(errs.FieldError{
+			Field:   te.Field,
+			Message: fmt.Sprintf("must be %s", jsonType(te.Type.Kind())),
+		})
+	}
+
+	// encoding/json has no typed error for unknown fields
+	if name := strings.TrimPrefix(err.Error(), "json: unknown field "); name != err.Error() {
+		return errs.InvalidFields(errs.FieldError{
+			Field:   strings.Trim(name, `"`),
+			Message: "unknown field",
+		})
+	}
+
+	return errs.BadRequest("invalid request body")
+}
+
+func jsonType(k reflect.Kind) string {
+	switch k {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	}
+	return "an integer"
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/shake551/cocktails-api/application/usecase"
+	"github.com/shake551/cocktails-api/domain/errs"
+)
+
+type SearchHandler interface {
+	SearchCocktails(w http.ResponseWriter, r *http.Request)
+}
+
+type searchHandler struct {
+	u usecase.SearchUseCase
+}
+
+func NewSearchHandler(u usecase.SearchUseCase) SearchHandler {
+	return &searchHandler{u}
+}
+
+func (h *searchHandler) SearchCocktails(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+
+	var limit = int64(20)
+	if v.Get("limit") != "" {
+		l, err := strconv.ParseInt(v.Get("limit"), 10, 64)
+		if err != nil {
+			writeError(w, errs.BadRequest("limit must be an integer"))
+			return
+		}
+
+		limit = l
+	}
+
+	results, err := h.u.SearchCocktails(r.Context(), v.Get("q"), limit)
+	if err != nil {
+		log.Printf("failed to search cocktails. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(results)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
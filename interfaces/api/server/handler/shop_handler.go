@@ -2,27 +2,43 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/go-chi/chi"
 	"github.com/shake551/cocktails-api/application/usecase"
+	"github.com/shake551/cocktails-api/domain/errs"
 	"github.com/shake551/cocktails-api/domain/model"
+	"github.com/shake551/cocktails-api/domain/repository"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 type ShopHandler interface {
 	GetLimit(w http.ResponseWriter, r *http.Request)
 	Create(w http.ResponseWriter, r *http.Request)
 	GetByID(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
 	GetShopCocktailList(w http.ResponseWriter, r *http.Request)
 	AddShopCocktail(w http.ResponseWriter, r *http.Request)
+	UpdateShopCocktailPrice(w http.ResponseWriter, r *http.Request)
+	UpdateShopCocktailAvailability(w http.ResponseWriter, r *http.Request)
 	GetShopCocktailDetail(w http.ResponseWriter, r *http.Request)
 	GetUnprovidedOrderList(w http.ResponseWriter, r *http.Request)
+	StreamOrders(w http.ResponseWriter, r *http.Request)
 	AddTable(w http.ResponseWriter, r *http.Request)
 	GetTable(w http.ResponseWriter, r *http.Request)
 	GetTableOrderList(w http.ResponseWriter, r *http.Request)
+	GetBill(w http.ResponseWriter, r *http.Request)
 	Order(w http.ResponseWriter, r *http.Request)
 	OrderProvide(w http.ResponseWriter, r *http.Request)
+	PrepareOrder(w http.ResponseWriter, r *http.Request)
+	ReadyOrder(w http.ResponseWriter, r *http.Request)
+	ServeOrder(w http.ResponseWriter, r *http.Request)
+	CancelOrder(w http.ResponseWriter, r *http.Request)
+	GetInventory(w http.ResponseWriter, r *http.Request)
+	UpdateInventory(w http.ResponseWriter, r *http.Request)
 }
 
 type shopHandler struct {
@@ -43,8 +59,7 @@ func (h *shopHandler) GetLimit(w http.ResponseWriter, r *http.Request) {
 	if v.Get("limit") != "" {
 		l, err := strconv.ParseInt(v.Get("limit"), 10, 64)
 		if err != nil {
-			log.Printf("failed to get limit. err: %v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			writeError(w, errs.BadRequest("limit must be an integer"))
 			return
 		}
 
@@ -55,8 +70,7 @@ func (h *shopHandler) GetLimit(w http.ResponseWriter, r *http.Request) {
 	if v.Get("offset") != "" {
 		o, err := strconv.ParseInt(v.Get("offset"), 10, 64)
 		if err != nil {
-			log.Printf("failed to get offset. err: %v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			writeError(w, errs.BadRequest("offset must be an integer"))
 			return
 		}
 
@@ -66,14 +80,14 @@ func (h *shopHandler) GetLimit(w http.ResponseWriter, r *http.Request) {
 	shops, err := h.u.GetLimit(r.Context(), limit, offset)
 	if err != nil {
 		log.Printf("failed to get cocktails. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	b, err := json.Marshal(shops)
 	if err != nil {
 		log.Printf("failed to parse json. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -85,23 +99,22 @@ func (h *shopHandler) GetLimit(w http.ResponseWriter, r *http.Request) {
 
 func (h *shopHandler) Create(w http.ResponseWriter, r *http.Request) {
 	body := model.ShopParams{}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		log.Printf("bad request error. err: %v, body:%v", err, body)
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
 		return
 	}
 
 	s, err := h.u.Create(r.Context(), body)
 	if err != nil {
 		log.Printf("failed to create shop. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	b, err := json.Marshal(s)
 	if err != nil {
 		log.Printf("failed to parse json")
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -114,21 +127,54 @@ func (h *shopHandler) Create(w http.ResponseWriter, r *http.Request) {
 func (h *shopHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrShopNotFound)
 		return
 	}
 
 	cocktailsDetail, err := h.u.GetByID(r.Context(), id)
 	if err != nil {
 		log.Printf("failed to get shop with id. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	b, err := json.Marshal(cocktailsDetail)
 	if err != nil {
 		log.Printf("failed to parse json. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func (h *shopHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrShopNotFound)
+		return
+	}
+
+	body := model.ShopParams{}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	s, err := h.u.Update(r.Context(), id, body)
+	if err != nil {
+		log.Printf("failed to update shop. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
 		return
 	}
 
@@ -141,7 +187,7 @@ func (h *shopHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 func (h *shopHandler) GetShopCocktailList(w http.ResponseWriter, r *http.Request) {
 	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrShopNotFound)
 		return
 	}
 
@@ -154,8 +200,7 @@ func (h *shopHandler) GetShopCocktailList(w http.ResponseWriter, r *http.Request
 	if v.Get("limit") != "" {
 		l, err := strconv.ParseInt(v.Get("limit"), 10, 64)
 		if err != nil {
-			log.Printf("failed to get limit. err: %v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			writeError(w, errs.BadRequest("limit must be an integer"))
 			return
 		}
 
@@ -166,8 +211,7 @@ func (h *shopHandler) GetShopCocktailList(w http.ResponseWriter, r *http.Request
 	if v.Get("offset") != "" {
 		o, err := strconv.ParseInt(v.Get("offset"), 10, 64)
 		if err != nil {
-			log.Printf("failed to get offset. err: %v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			writeError(w, errs.BadRequest("offset must be an integer"))
 			return
 		}
 
@@ -176,14 +220,14 @@ func (h *shopHandler) GetShopCocktailList(w http.ResponseWriter, r *http.Request
 
 	c, err := h.u.GetShopCocktailList(r.Context(), shopID, limit, offset)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	b, err := json.Marshal(c)
 	if err != nil {
 		log.Printf("failed to parse json. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -196,27 +240,26 @@ func (h *shopHandler) GetShopCocktailList(w http.ResponseWriter, r *http.Request
 func (h *shopHandler) AddShopCocktail(w http.ResponseWriter, r *http.Request) {
 	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrShopNotFound)
 		return
 	}
 
 	body := model.ShopCocktailParams{}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		log.Printf("bad request error. err: %v, body:%v", err, body)
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
 		return
 	}
 
 	c, err := h.u.AddShopCocktail(r.Context(), shopID, body)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	b, err := json.Marshal(c)
 	if err != nil {
 		log.Printf("failed to parse json. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -226,29 +269,117 @@ func (h *shopHandler) AddShopCocktail(w http.ResponseWriter, r *http.Request) {
 	w.Write(b)
 }
 
+func (h *shopHandler) UpdateShopCocktailPrice(w http.ResponseWriter, r *http.Request) {
+	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrShopNotFound)
+		return
+	}
+
+	cocktailID, err := strconv.ParseInt(chi.URLParam(r, "cocktailID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrCocktailNotFound)
+		return
+	}
+
+	body := model.ShopCocktailPriceParams{}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	c, err := h.u.UpdateShopCocktailPrice(r.Context(), shopID, cocktailID, body)
+	if err != nil {
+		log.Printf("failed to update shop cocktail price. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+type PutShopCocktailAvailabilityBody struct {
+	Available bool  `json:"available"`
+	BackAt    int64 `json:"back_at"`
+}
+
+func (h *shopHandler) UpdateShopCocktailAvailability(w http.ResponseWriter, r *http.Request) {
+	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrShopNotFound)
+		return
+	}
+
+	cocktailID, err := strconv.ParseInt(chi.URLParam(r, "cocktailID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrCocktailNotFound)
+		return
+	}
+
+	body := PutShopCocktailAvailabilityBody{}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	params := model.ShopCocktailAvailability{
+		SoldOut: !body.Available,
+		BackAt:  body.BackAt,
+	}
+
+	a, err := h.u.UpdateShopCocktailAvailability(r.Context(), shopID, cocktailID, params)
+	if err != nil {
+		log.Printf("failed to update shop cocktail availability. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(a)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
 func (h *shopHandler) GetShopCocktailDetail(w http.ResponseWriter, r *http.Request) {
 	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrShopNotFound)
 		return
 	}
 
 	cocktailID, err := strconv.ParseInt(chi.URLParam(r, "cocktailID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrCocktailNotFound)
 		return
 	}
 
 	d, err := h.u.GetShopCocktailDetail(r.Context(), shopID, cocktailID)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	b, err := json.Marshal(d)
 	if err != nil {
 		log.Printf("failed to parse json. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -261,7 +392,7 @@ func (h *shopHandler) GetShopCocktailDetail(w http.ResponseWriter, r *http.Reque
 func (h *shopHandler) GetUnprovidedOrderList(w http.ResponseWriter, r *http.Request) {
 	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrShopNotFound)
 		return
 	}
 
@@ -274,8 +405,7 @@ func (h *shopHandler) GetUnprovidedOrderList(w http.ResponseWriter, r *http.Requ
 	if v.Get("limit") != "" {
 		l, err := strconv.ParseInt(v.Get("limit"), 10, 64)
 		if err != nil {
-			log.Printf("failed to get limit. err: %v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			writeError(w, errs.BadRequest("limit must be an integer"))
 			return
 		}
 
@@ -286,8 +416,7 @@ func (h *shopHandler) GetUnprovidedOrderList(w http.ResponseWriter, r *http.Requ
 	if v.Get("offset") != "" {
 		o, err := strconv.ParseInt(v.Get("offset"), 10, 64)
 		if err != nil {
-			log.Printf("failed to get offset. err: %v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			writeError(w, errs.BadRequest("offset must be an integer"))
 			return
 		}
 
@@ -298,8 +427,7 @@ func (h *shopHandler) GetUnprovidedOrderList(w http.ResponseWriter, r *http.Requ
 	if v.Get("unprovided") != "" {
 		unprovided, err = strconv.ParseBool(v.Get("unprovided"))
 		if err != nil {
-			log.Printf("bad request error. err: %v, param:%v", err, v.Get("unprovided"))
-			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			writeError(w, errs.BadRequest("unprovided must be a boolean"))
 			return
 		}
 	}
@@ -310,14 +438,14 @@ func (h *shopHandler) GetUnprovidedOrderList(w http.ResponseWriter, r *http.Requ
 
 	os, err := h.u.GetUnprovidedOrderList(r.Context(), shopID, limit, offset)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	b, err := json.Marshal(os)
 	if err != nil {
 		log.Printf("failed to parse json. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -327,23 +455,80 @@ func (h *shopHandler) GetUnprovidedOrderList(w http.ResponseWriter, r *http.Requ
 	w.Write(b)
 }
 
+// streamHeartbeatInterval keeps idle order streams from being closed by proxies.
+const streamHeartbeatInterval = 30 * time.Second
+
+func (h *shopHandler) StreamOrders(w http.ResponseWriter, r *http.Request) {
+	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrShopNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		log.Printf("streaming is not supported by the response writer")
+		writeError(w, errors.New("streaming is not supported"))
+		return
+	}
+
+	events, unsubscribe := h.u.SubscribeOrders(shopID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+
+			b, err := json.Marshal(e)
+			if err != nil {
+				log.Printf("failed to parse json. err: %v", err)
+				continue
+			}
+
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Order.ID, e.Type, b); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
 func (h *shopHandler) AddTable(w http.ResponseWriter, r *http.Request) {
 	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrShopNotFound)
 		return
 	}
 
 	t, err := h.u.AddTable(r.Context(), shopID)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	b, err := json.Marshal(t)
 	if err != nil {
 		log.Printf("failed to parse json. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -356,26 +541,26 @@ func (h *shopHandler) AddTable(w http.ResponseWriter, r *http.Request) {
 func (h *shopHandler) GetTable(w http.ResponseWriter, r *http.Request) {
 	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrShopNotFound)
 		return
 	}
 
 	tableID, err := strconv.ParseInt(chi.URLParam(r, "tableID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrTableNotFound)
 		return
 	}
 
 	t, err := h.u.GetTable(r.Context(), shopID, tableID)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	b, err := json.Marshal(t)
 	if err != nil {
 		log.Printf("failed to parse json. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -388,13 +573,13 @@ func (h *shopHandler) GetTable(w http.ResponseWriter, r *http.Request) {
 func (h *shopHandler) GetTableOrderList(w http.ResponseWriter, r *http.Request) {
 	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrShopNotFound)
 		return
 	}
 
 	tableID, err := strconv.ParseInt(chi.URLParam(r, "tableID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrTableNotFound)
 		return
 	}
 
@@ -404,22 +589,21 @@ func (h *shopHandler) GetTableOrderList(w http.ResponseWriter, r *http.Request)
 	if v.Get("unprovided") != "" {
 		unprovided, err = strconv.ParseBool(v.Get("unprovided"))
 		if err != nil {
-			log.Printf("bad request error. err: %v, param:%v", err, v.Get("unprovided"))
-			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			writeError(w, errs.BadRequest("unprovided must be a boolean"))
 			return
 		}
 	}
 
 	os, err := h.u.GetTableOrderList(r.Context(), shopID, tableID, unprovided)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	b, err := json.Marshal(os)
 	if err != nil {
 		log.Printf("failed to parse json. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -429,36 +613,68 @@ func (h *shopHandler) GetTableOrderList(w http.ResponseWriter, r *http.Request)
 	w.Write(b)
 }
 
+func (h *shopHandler) GetBill(w http.ResponseWriter, r *http.Request) {
+	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrShopNotFound)
+		return
+	}
+
+	tableID, err := strconv.ParseInt(chi.URLParam(r, "tableID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrTableNotFound)
+		return
+	}
+
+	bill, err := h.u.GetBill(r.Context(), shopID, tableID)
+	if err != nil {
+		log.Printf("failed to get bill. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(bill)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
 func (h *shopHandler) Order(w http.ResponseWriter, r *http.Request) {
 	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrShopNotFound)
 		return
 	}
 
 	tableID, err := strconv.ParseInt(chi.URLParam(r, "tableID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrTableNotFound)
 		return
 	}
 
 	body := model.OrderParams{}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		log.Printf("bad request error. err: %v, body:%v", err, body)
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
 		return
 	}
 
 	o, err := h.u.Order(r.Context(), shopID, tableID, body)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	b, err := json.Marshal(o)
 	if err != nil {
 		log.Printf("failed to parse json. err: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -471,28 +687,143 @@ func (h *shopHandler) Order(w http.ResponseWriter, r *http.Request) {
 func (h *shopHandler) OrderProvide(w http.ResponseWriter, r *http.Request) {
 	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrShopNotFound)
 		return
 	}
 
 	tableID, err := strconv.ParseInt(chi.URLParam(r, "tableID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrTableNotFound)
 		return
 	}
 
 	orderID, err := strconv.ParseInt(chi.URLParam(r, "orderID"), 10, 64)
 	if err != nil {
-		http.NotFound(w, r)
+		writeError(w, repository.ErrOrderNotFound)
 		return
 	}
 
 	err = h.u.OrderProvide(r.Context(), shopID, tableID, orderID)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 }
+
+func (h *shopHandler) PrepareOrder(w http.ResponseWriter, r *http.Request) {
+	h.transitionOrder(w, r, model.OrderStatusPreparing)
+}
+
+func (h *shopHandler) ReadyOrder(w http.ResponseWriter, r *http.Request) {
+	h.transitionOrder(w, r, model.OrderStatusReady)
+}
+
+func (h *shopHandler) ServeOrder(w http.ResponseWriter, r *http.Request) {
+	h.transitionOrder(w, r, model.OrderStatusServed)
+}
+
+func (h *shopHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	h.transitionOrder(w, r, model.OrderStatusCancelled)
+}
+
+func (h *shopHandler) transitionOrder(w http.ResponseWriter, r *http.Request, status model.OrderStatus) {
+	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrShopNotFound)
+		return
+	}
+
+	tableID, err := strconv.ParseInt(chi.URLParam(r, "tableID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrTableNotFound)
+		return
+	}
+
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "orderID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrOrderNotFound)
+		return
+	}
+
+	o, err := h.u.TransitionOrder(r.Context(), shopID, tableID, orderID, status)
+	if err != nil {
+		log.Printf("failed to change order status. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(o)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func (h *shopHandler) GetInventory(w http.ResponseWriter, r *http.Request) {
+	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrShopNotFound)
+		return
+	}
+
+	items, err := h.u.GetInventory(r.Context(), shopID)
+	if err != nil {
+		log.Printf("failed to get inventory. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(items)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func (h *shopHandler) UpdateInventory(w http.ResponseWriter, r *http.Request) {
+	shopID, err := strconv.ParseInt(chi.URLParam(r, "shopID"), 10, 64)
+	if err != nil {
+		writeError(w, repository.ErrShopNotFound)
+		return
+	}
+
+	body := model.InventoryParams{}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	items, err := h.u.UpdateInventory(r.Context(), shopID, body)
+	if err != nil {
+		log.Printf("failed to update inventory. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(items)
+	if err != nil {
+		log.Printf("failed to parse json. err: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
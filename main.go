@@ -2,9 +2,10 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"github.com/shake551/cocktails-api/application/usecase"
-	"github.com/shake551/cocktails-api/infrastructure/parsistence/datastore"
+	"github.com/shake551/cocktails-api/domain/event"
 	"github.com/shake551/cocktails-api/interfaces/api/server/handler"
 	"log"
 	"mime"
@@ -12,6 +13,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi"
@@ -19,8 +21,6 @@ import (
 	"github.com/go-chi/cors"
 	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
 	"github.com/lestrrat-go/server-starter/listener"
-
-	"github.com/shake551/cocktails-api/db"
 )
 
 const port = 8080
@@ -53,26 +53,33 @@ func getAccessLogFormatter() middleware.LogFormatter {
 	return &middleware.DefaultLogFormatter{Logger: log.New(logf, "", log.LstdFlags), NoColor: false}
 }
 
-func createRouter() chi.Router {
+func createRouter(repos repositories, searchRefresh time.Duration) chi.Router {
 	mux := chi.NewRouter()
 	mux.Use(cors.New(cors.Options{
 		AllowedOrigins: []string{"*"},
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "HEAD", "OPTIONS"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
 		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
 	}).Handler)
 	mux.Use(middleware.RequestLogger(getAccessLogFormatter()))
-	mux.Use(contentTypeRestrictionMiddleware("application/json"))
 
-	cr := datastore.NewCocktailRepository()
-	cu := usecase.NewCocktailUseCase(cr)
+	cu := usecase.NewCocktailUseCase(repos.cocktail, repos.unitOfWork)
 	ch := handler.NewCocktailHandler(cu)
 
-	sr := datastore.NewShopRepository()
-	su := usecase.NewShopUseCase(sr)
+	seh := handler.NewSearchHandler(usecase.NewSearchUseCase(repos.cocktail, searchRefresh))
+
+	mu := usecase.NewMaterialUseCase(repos.material)
+	mh := handler.NewMaterialHandler(mu)
+
+	su := usecase.NewShopUseCase(repos.shop, event.NewOrderHub(), repos.unitOfWork)
 	sh := handler.NewShopHandler(su)
 
+	iu := usecase.NewImageUseCase(repos.image, repos.unitOfWork)
+	ih := handler.NewImageHandler(iu)
+
 	// no auth
 	mux.Group(func(mux chi.Router) {
+		mux.Use(contentTypeRestrictionMiddleware("application/json"))
+
 		mux.MethodFunc("GET", "/health", func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 		})
@@ -80,33 +87,103 @@ func createRouter() chi.Router {
 		mux.MethodFunc("GET", "/cocktails", ch.GetLimit)
 		mux.MethodFunc("POST", "/cocktails", ch.Create)
 		mux.MethodFunc("GET", "/cocktails/{cocktailsID}", ch.GetById)
+		mux.MethodFunc("GET", "/cocktails/{cocktailsID}/batch", ch.Batch)
+		mux.MethodFunc("PUT", "/cocktails/{cocktailsID}", ch.Update)
+		mux.MethodFunc("PATCH", "/cocktails/{cocktailsID}", ch.Patch)
+		mux.MethodFunc("DELETE", "/cocktails/{cocktailsID}", ch.Delete)
 		mux.MethodFunc("GET", "/cocktails/list", ch.GetListByIDs)
+		mux.MethodFunc("GET", "/cocktails/search", seh.SearchCocktails)
+		mux.MethodFunc("POST", "/cocktails/makeable", ch.GetMakeable)
+
+		mux.MethodFunc("GET", "/materials", mh.GetLimit)
+		mux.MethodFunc("POST", "/materials", mh.Create)
+		mux.MethodFunc("GET", "/materials/{materialID}", mh.GetByID)
+		mux.MethodFunc("PUT", "/materials/{materialID}", mh.Rename)
+		mux.MethodFunc("PUT", "/materials/{materialID}/abv", mh.UpdateABV)
+		mux.MethodFunc("POST", "/materials/{materialID}/aliases", mh.AddAlias)
+		mux.MethodFunc("DELETE", "/materials/{materialID}/aliases/{aliasID}", mh.DeleteAlias)
 
 		mux.MethodFunc("GET", "/shop", sh.GetLimit)
 		mux.MethodFunc("POST", "/shop", sh.Create)
 		mux.MethodFunc("GET", "/shop/{shopID}", sh.GetByID)
+		mux.MethodFunc("PUT", "/shop/{shopID}", sh.Update)
 		mux.MethodFunc("GET", "/shop/{shopID}/cocktail", sh.GetShopCocktailList)
 		mux.MethodFunc("POST", "/shop/{shopID}/cocktail", sh.AddShopCocktail)
 		mux.MethodFunc("GET", "/shop/{shopID}/cocktail/{cocktailID}", sh.GetShopCocktailDetail)
+		mux.MethodFunc("PUT", "/shop/{shopID}/cocktail/{cocktailID}", sh.UpdateShopCocktailPrice)
+		mux.MethodFunc("PUT", "/shop/{shopID}/cocktail/{cocktailID}/availability", sh.UpdateShopCocktailAvailability)
 		mux.MethodFunc("GET", "/shop/{shopID}/order", sh.GetUnprovidedOrderList)
+		mux.MethodFunc("GET", "/shop/{shopID}/order/stream", sh.StreamOrders)
+		mux.MethodFunc("GET", "/shop/{shopID}/inventory", sh.GetInventory)
+		mux.MethodFunc("PUT", "/shop/{shopID}/inventory", sh.UpdateInventory)
 		mux.MethodFunc("POST", "/shop/{shopID}/table", sh.AddTable)
 		mux.MethodFunc("GET", "/shop/{shopID}/table/{tableID}", sh.GetTable)
 		mux.MethodFunc("GET", "/shop/{shopID}/table/{tableID}/order", sh.GetTableOrderList)
 		mux.MethodFunc("POST", "/shop/{shopID}/table/{tableID}/order", sh.Order)
+		mux.MethodFunc("GET", "/shop/{shopID}/table/{tableID}/bill", sh.GetBill)
 		mux.MethodFunc("PUT", "/shop/{shopID}/table/{tableID}/order/{orderID}", sh.OrderProvide)
+		mux.MethodFunc("PUT", "/shop/{shopID}/table/{tableID}/order/{orderID}/prepare", sh.PrepareOrder)
+		mux.MethodFunc("PUT", "/shop/{shopID}/table/{tableID}/order/{orderID}/ready", sh.ReadyOrder)
+		mux.MethodFunc("PUT", "/shop/{shopID}/table/{tableID}/order/{orderID}/serve", sh.ServeOrder)
+		mux.MethodFunc("PUT", "/shop/{shopID}/table/{tableID}/order/{orderID}/cancel", sh.CancelOrder)
+
+		mux.MethodFunc("GET", "/images/{imageID}", ih.Get)
+		mux.MethodFunc("GET", "/images/{imageID}/{variant}", ih.GetVariant)
+	})
+
+	// uploads, no auth
+	mux.Group(func(mux chi.Router) {
+		mux.Use(contentTypeRestrictionMiddleware("multipart/form-data"))
+
+		mux.MethodFunc("POST", "/cocktails/{cocktailsID}/image", ih.UploadCocktailImage)
+	})
+
+	// admin
+	mux.Group(func(mux chi.Router) {
+		mux.Use(contentTypeRestrictionMiddleware("application/json"))
+		mux.Use(adminAuthMiddleware(os.Getenv("ADMIN_TOKEN")))
+
+		mux.MethodFunc("POST", "/admin/materials/merge", mh.Merge)
 	})
 
 	return mux
 }
 
 func main() {
-	done, err := db.Initialize(os.Getenv("DSN"))
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), os.Getenv("STORAGE"), os.Args[2:]); err != nil {
+			log.Fatalf("failed to migrate: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "backfill-variants" {
+		if err := runBackfillVariants(context.Background(), os.Getenv("STORAGE")); err != nil {
+			log.Fatalf("failed to backfill image variants: %v", err)
+		}
+		return
+	}
+
+	repos, done, err := newRepositories(context.Background(), os.Getenv("STORAGE"))
 	if err != nil {
-		log.Fatalf("failed to initialize db: %v", err)
+		log.Fatalf("failed to initialize storage: %v", err)
 	}
 	defer done()
 
-	mux := createRouter()
+	repos.image, err = newImageRepository(os.Getenv("IMAGE_STORAGE"), repos.image)
+	if err != nil {
+		log.Fatalf("failed to initialize image storage: %v", err)
+	}
+
+	// the search index is rebuilt from storage when older than this, so writes of other instances show up too
+	searchRefresh, err := durationEnv("SEARCH_REFRESH_INTERVAL")
+	if err != nil {
+		log.Fatalf("failed to read the search settings: %v", err)
+	}
+	if searchRefresh == 0 {
+		searchRefresh = 30 * time.Second
+	}
+
+	mux := createRouter(repos, searchRefresh)
 	server := http.Server{
 		Handler: mux,
 	}
@@ -168,3 +245,26 @@ func contentTypeRestrictionMiddleware(mediaType string) func(next http.Handler)
 		})
 	}
 }
+
+// adminAuthMiddleware lets through the requests carrying the token as "Authorization: Bearer <token>".
+// Without a token configured, every request is refused.
+func adminAuthMiddleware(token string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				log.Print("ADMIN_TOKEN is not set")
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				log.Print("Invalid admin token")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
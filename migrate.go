@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shake551/cocktails-api/db/migrate"
+	"github.com/shake551/cocktails-api/db/migrations"
+	sqlitemigrations "github.com/shake551/cocktails-api/infrastructure/parsistence/sqlite/migrations"
+)
+
+const migrateUsage = "usage: cocktails-api-server migrate up|down|status"
+
+// newMigrator connects to the database of the storage backend with the migrations of its dialect.
+func newMigrator(storage string) (*migrate.Migrator, func() error, error) {
+	switch storage {
+	case "", "mysql":
+		d, err := openMySQL()
+		if err != nil {
+			return nil, func() error { return nil }, err
+		}
+		m, err := migrate.New(d.DB, migrations.FS, migrations.Steps...)
+		return m, d.Close, err
+	case "sqlite":
+		d, err := openSQLite()
+		if err != nil {
+			return nil, func() error { return nil }, err
+		}
+		m, err := migrate.New(d, sqlitemigrations.FS, sqlitemigrations.Steps...)
+		return m, d.Close, err
+	}
+
+	return nil, func() error { return nil }, fmt.Errorf("storage %q has no schema to migrate", storage)
+}
+
+// runMigrate runs the migrate subcommand.
+func runMigrate(ctx context.Context, storage string, args []string) error {
+	if len(args) != 1 {
+		return errors.New(migrateUsage)
+	}
+
+	m, done, err := newMigrator(storage)
+	defer done()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			fmt.Printf("applied %04d_%s\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("already up to date")
+		}
+		return err
+	case "down":
+		reverted, err := m.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if reverted == nil {
+			fmt.Println("no migration to revert")
+			return nil
+		}
+		fmt.Printf("reverted %04d_%s\n", reverted.Version, reverted.Name)
+		return nil
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != 0 {
+				appliedAt = time.Unix(s.AppliedAt, 0).Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return nil
+	}
+
+	return errors.New(migrateUsage)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/shake551/cocktails-api/db"
+	"github.com/shake551/cocktails-api/db/migrate"
+	"github.com/shake551/cocktails-api/db/migrations"
+	"github.com/shake551/cocktails-api/domain/repository"
+	"github.com/shake551/cocktails-api/infrastructure/parsistence/datastore"
+	"github.com/shake551/cocktails-api/infrastructure/parsistence/filestore"
+	"github.com/shake551/cocktails-api/infrastructure/parsistence/inmemory"
+	"github.com/shake551/cocktails-api/infrastructure/parsistence/sqlite"
+	sqlitemigrations "github.com/shake551/cocktails-api/infrastructure/parsistence/sqlite/migrations"
+)
+
+type repositories struct {
+	cocktail   repository.CocktailRepository
+	material   repository.MaterialRepository
+	shop       repository.ShopRepository
+	image      repository.ImageRepository
+	unitOfWork repository.UnitOfWork
+}
+
+// newRepositories builds the repositories of the storage backend.
+// "mysql" (the default) connects to the DSN and refuses a schema which is not at the version of this binary,
+// "sqlite" opens the file at SQLITE_PATH and applies its pending migrations,
+// and "memory" keeps everything in the process and needs no database.
+func newRepositories(ctx context.Context, storage string) (repositories, func() error, error) {
+	switch storage {
+	case "", "mysql":
+		d, err := openMySQL()
+		if err != nil {
+			return repositories{}, func() error { return nil }, err
+		}
+		m, err := migrate.New(d.DB, migrations.FS, migrations.Steps...)
+		if err == nil {
+			err = m.Check(ctx)
+		}
+		if err != nil {
+			return repositories{}, d.Close, fmt.Errorf("%w. run `migrate up` with the matching release", err)
+		}
+		return repositories{
+			cocktail:   datastore.NewCocktailRepository(d),
+			material:   datastore.NewMaterialRepository(d),
+			shop:       datastore.NewShopRepository(d),
+			image:      datastore.NewImageRepository(d),
+			unitOfWork: datastore.NewUnitOfWork(d),
+		}, d.Close, nil
+	case "sqlite":
+		d, err := openSQLite()
+		if err != nil {
+			return repositories{}, func() error { return nil }, err
+		}
+		// the file belongs to this binary, so it is brought up to date; a newer schema still refuses to start
+		m, err := migrate.New(d, sqlitemigrations.FS, sqlitemigrations.Steps...)
+		if err == nil {
+			_, err = m.Up(ctx)
+		}
+		if err != nil {
+			return repositories{}, d.Close, err
+		}
+		return repositories{
+			cocktail:   sqlite.NewCocktailRepository(d),
+			material:   sqlite.NewMaterialRepository(d),
+			shop:       sqlite.NewShopRepository(d),
+			image:      sqlite.NewImageRepository(d),
+			unitOfWork: sqlite.NewUnitOfWork(d),
+		}, d.Close, nil
+	case "memory":
+		s := inmemory.NewStore()
+		return repositories{
+			cocktail:   inmemory.NewCocktailRepository(s),
+			material:   inmemory.NewMaterialRepository(s),
+			shop:       inmemory.NewShopRepository(s),
+			image:      inmemory.NewImageRepository(s),
+			unitOfWork: inmemory.NewUnitOfWork(s),
+		}, func() error { return nil }, nil
+	}
+
+	return repositories{}, func() error { return nil }, fmt.Errorf("unknown storage: %s", storage)
+}
+
+// newImageRepository returns where uploaded images are kept.
+// "db" (the default) keeps them in the database of the storage backend, as inDB does,
+// and "fs" writes them as files to the directory IMAGE_DIR.
+func newImageRepository(storage string, inDB repository.ImageRepository) (repository.ImageRepository, error) {
+	switch storage {
+	case "", "db":
+		return inDB, nil
+	case "fs":
+		dir := os.Getenv("IMAGE_DIR")
+		if dir == "" {
+			dir = "images"
+		}
+		return filestore.NewImageRepository(dir)
+	}
+
+	return nil, fmt.Errorf("unknown image storage: %s", storage)
+}
+
+// openMySQL connects to DSN with the pool settings DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS,
+// DB_CONN_MAX_LIFETIME and DB_CONN_MAX_IDLE_TIME. The durations are written like "5m".
+func openMySQL() (*sqlx.DB, error) {
+	cfg := db.Config{DSN: os.Getenv("DSN")}
+
+	var err error
+	if cfg.MaxOpenConns, err = intEnv("DB_MAX_OPEN_CONNS"); err != nil {
+		return nil, err
+	}
+	if cfg.MaxIdleConns, err = intEnv("DB_MAX_IDLE_CONNS"); err != nil {
+		return nil, err
+	}
+	if cfg.ConnMaxLifetime, err = durationEnv("DB_CONN_MAX_LIFETIME"); err != nil {
+		return nil, err
+	}
+	if cfg.ConnMaxIdleTime, err = durationEnv("DB_CONN_MAX_IDLE_TIME"); err != nil {
+		return nil, err
+	}
+
+	return db.Initialize(cfg)
+}
+
+// openSQLite opens the database file at SQLITE_PATH.
+func openSQLite() (*sql.DB, error) {
+	path := os.Getenv("SQLITE_PATH")
+	if path == "" {
+		path = "cocktails.db"
+	}
+	return sqlite.Open(path)
+}
+
+func intEnv(key string) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer: %q", key, v)
+	}
+	return n, nil
+}
+
+func durationEnv(key string) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration like 5m: %q", key, v)
+	}
+	return d, nil
+}